@@ -7,6 +7,10 @@ var (
 	errEmptyEtcdHosts = errors.New("empty etcd hosts")
 	// errEmptyEtcdKey indicates that etcd key is empty.
 	errEmptyEtcdKey = errors.New("empty etcd key")
+	// errEmptyRegistryTarget indicates that registry target is empty.
+	errEmptyRegistryTarget = errors.New("empty registry target")
+	// errEmptyRegistryService indicates that registry service is empty.
+	errEmptyRegistryService = errors.New("empty registry service")
 )
 
 // EtcdConf is the config item with the given key on etcd.
@@ -47,3 +51,25 @@ func (c EtcdConf) Validate() error {
 		return nil
 	}
 }
+
+// RegistryConf is the config of the service registry, the backend is selected by the scheme
+// of Target, etcd, dns, file, k8s and the ones registered by RegisterRegistry.
+type RegistryConf struct {
+	// Target is the registry, like etcd://127.0.0.1:2379, dns:///, dns://8.8.8.8:53?ttl=10s,
+	// file:///path/to/registry.json or k8s:///.
+	Target string
+	// Service is the service to register and watch, like the etcd key, the DNS name,
+	// the kubernetes service namespace/name:port or the service name in the file.
+	Service string
+}
+
+// Validate validates c.
+func (c RegistryConf) Validate() error {
+	if len(c.Target) == 0 {
+		return errEmptyRegistryTarget
+	} else if len(c.Service) == 0 {
+		return errEmptyRegistryService
+	} else {
+		return nil
+	}
+}
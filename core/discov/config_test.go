@@ -167,3 +167,9 @@ func TestEtcdConf_HasTLS(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistryConf(t *testing.T) {
+	assert.ErrorIs(t, RegistryConf{}.Validate(), errEmptyRegistryTarget)
+	assert.ErrorIs(t, RegistryConf{Target: "dns:///"}.Validate(), errEmptyRegistryService)
+	assert.NoError(t, RegistryConf{Target: "dns:///", Service: "svc:8080"}.Validate())
+}
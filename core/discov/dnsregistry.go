@@ -0,0 +1,153 @@
+package discov
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/threading"
+	"github.com/r27153733/fastgozero/core/timex"
+)
+
+const (
+	dnsScheme     = "dns"
+	defaultDnsTtl = 30 * time.Second
+	dnsTimeout    = 5 * time.Second
+	srvPrefix     = "_"
+)
+
+type dnsRegistry struct {
+	ttl        time.Duration
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	lookupSRV  func(ctx context.Context, name string) ([]*net.SRV, error)
+}
+
+// newDnsRegistry builds the registry from dns:/// with the system resolver,
+// or dns://8.8.8.8:53 with the given name server, the records are resolved again
+// every ttl, which is 30s by default, customized like dns:///?ttl=10s.
+func newDnsRegistry(target *url.URL) (Registry, error) {
+	ttl := defaultDnsTtl
+	if val := target.Query().Get("ttl"); len(val) > 0 {
+		var err error
+		if ttl, err = time.ParseDuration(val); err != nil {
+			return nil, err
+		}
+	}
+
+	resolver := net.DefaultResolver
+	if len(target.Host) > 0 {
+		nameServer := target.Host
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, nameServer)
+			},
+		}
+	}
+
+	return &dnsRegistry{
+		ttl:        ttl,
+		lookupHost: resolver.LookupHost,
+		lookupSRV: func(ctx context.Context, name string) ([]*net.SRV, error) {
+			_, srvs, err := resolver.LookupSRV(ctx, "", "", name)
+			return srvs, err
+		},
+	}, nil
+}
+
+func (r *dnsRegistry) Deregister(_ string, _ Endpoint) error {
+	return ErrReadOnlyRegistry
+}
+
+func (r *dnsRegistry) Register(_ string, _ Endpoint) error {
+	return ErrReadOnlyRegistry
+}
+
+// Watch watches the SRV records if service starts with _, like _grpc._tcp.svc.example.com,
+// or the A/AAAA records with the port, like svc.example.com:8080.
+func (r *dnsRegistry) Watch(service string, listener func([]Endpoint)) (func(), error) {
+	eps, err := r.resolve(service)
+	if err != nil {
+		return nil, err
+	}
+	listener(eps)
+
+	done := make(chan struct{})
+	threading.GoSafe(func() {
+		ticker := timex.NewTicker(r.ttl)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.Chan():
+				latest, err := r.resolve(service)
+				if err != nil {
+					logx.Errorf("discov: resolve %q, error: %v", service, err)
+					continue
+				}
+				if !slices.EqualFunc(eps, latest, equalEndpoint) {
+					eps = latest
+					listener(eps)
+				}
+			case <-done:
+				return
+			}
+		}
+	})
+
+	return func() {
+		close(done)
+	}, nil
+}
+
+func (r *dnsRegistry) resolve(service string) ([]Endpoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	var eps []Endpoint
+	if strings.HasPrefix(service, srvPrefix) {
+		srvs, err := r.lookupSRV(ctx, service)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, srv := range srvs {
+			eps = append(eps, Endpoint{
+				Addr:   net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port))),
+				Weight: int(srv.Weight),
+			})
+		}
+	} else {
+		host, port, err := net.SplitHostPort(service)
+		if err != nil {
+			return nil, err
+		}
+
+		hosts, err := r.lookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, each := range hosts {
+			eps = append(eps, Endpoint{
+				Addr: net.JoinHostPort(each, port),
+			})
+		}
+	}
+
+	slices.SortFunc(eps, func(a, b Endpoint) int {
+		return strings.Compare(a.Addr, b.Addr)
+	})
+
+	return eps, nil
+}
+
+func equalEndpoint(a, b Endpoint) bool {
+	return a.Addr == b.Addr && a.Weight == b.Weight
+}
@@ -0,0 +1,94 @@
+package discov
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDnsRegistry(t *testing.T) {
+	r, err := newDnsRegistry(mustParseURL(t, "dns://8.8.8.8:53?ttl=10s"))
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, r.(*dnsRegistry).ttl)
+
+	r, err = newDnsRegistry(mustParseURL(t, "dns:///"))
+	assert.NoError(t, err)
+	assert.Equal(t, defaultDnsTtl, r.(*dnsRegistry).ttl)
+
+	_, err = newDnsRegistry(mustParseURL(t, "dns:///?ttl=bad"))
+	assert.Error(t, err)
+
+	assert.ErrorIs(t, r.Register("foo", Endpoint{}), ErrReadOnlyRegistry)
+	assert.ErrorIs(t, r.Deregister("foo", Endpoint{}), ErrReadOnlyRegistry)
+}
+
+func TestDnsRegistry_Watch(t *testing.T) {
+	var lock sync.Mutex
+	hosts := []string{"10.0.0.2", "10.0.0.1"}
+	r := &dnsRegistry{
+		ttl: 10 * time.Millisecond,
+		lookupHost: func(_ context.Context, host string) ([]string, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			if host != "svc.example.com" {
+				return nil, errors.New("not found")
+			}
+			return hosts, nil
+		},
+		lookupSRV: func(_ context.Context, name string) ([]*net.SRV, error) {
+			return []*net.SRV{
+				{Target: "b.example.com.", Port: 8081, Weight: 20},
+				{Target: "a.example.com.", Port: 8080, Weight: 10},
+			}, nil
+		},
+	}
+
+	_, err := r.Watch("svc.example.com", func([]Endpoint) {})
+	assert.Error(t, err)
+	_, err = r.Watch("unknown.example.com:8080", func([]Endpoint) {})
+	assert.Error(t, err)
+
+	var eps []Endpoint
+	stop, err := r.Watch("_grpc._tcp.svc.example.com", func(val []Endpoint) {
+		eps = val
+	})
+	assert.NoError(t, err)
+	stop()
+	assert.Equal(t, []Endpoint{
+		{Addr: "a.example.com:8080", Weight: 10},
+		{Addr: "b.example.com:8081", Weight: 20},
+	}, eps)
+
+	ch := make(chan []Endpoint, 10)
+	stop, err = r.Watch("svc.example.com:8080", func(val []Endpoint) {
+		ch <- val
+	})
+	assert.NoError(t, err)
+	defer stop()
+	assert.Equal(t, []Endpoint{
+		{Addr: "10.0.0.1:8080"},
+		{Addr: "10.0.0.2:8080"},
+	}, <-ch)
+
+	lock.Lock()
+	hosts = []string{"10.0.0.3"}
+	lock.Unlock()
+	select {
+	case val := <-ch:
+		assert.Equal(t, []Endpoint{{Addr: "10.0.0.3:8080"}}, val)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the changes")
+	}
+}
+
+func mustParseURL(t *testing.T, val string) *url.URL {
+	u, err := url.Parse(val)
+	assert.NoError(t, err)
+	return u
+}
@@ -0,0 +1,54 @@
+package discov
+
+import (
+	"strings"
+
+	"github.com/r27153733/fastgozero/core/jsonx"
+	"github.com/r27153733/fastgozero/core/logx"
+)
+
+// An Endpoint is a service instance published in etcd, with its metadata.
+type Endpoint struct {
+	Addr    string            `json:"addr"`
+	Weight  int               `json:"weight,omitempty"`
+	Zone    string            `json:"zone,omitempty"`
+	Version string            `json:"version,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+}
+
+// ParseEndpoint parses the published value into an Endpoint,
+// both plain addresses and the values with metadata are supported.
+func ParseEndpoint(val string) Endpoint {
+	if !strings.HasPrefix(val, "{") {
+		return Endpoint{Addr: val}
+	}
+
+	var ep Endpoint
+	if err := jsonx.UnmarshalFromString(val, &ep); err != nil {
+		logx.Errorf("bad endpoint value: %s, error: %v", val, err)
+		return Endpoint{Addr: val}
+	}
+
+	return ep
+}
+
+// HasMetadata checks if there is any metadata besides the address.
+func (ep Endpoint) HasMetadata() bool {
+	return ep.Weight != 0 || len(ep.Zone) > 0 || len(ep.Version) > 0 || len(ep.Tags) > 0
+}
+
+// Value returns the value to publish. The plain address is returned if no metadata,
+// to keep compatible with the subscribers that only recognize addresses.
+func (ep Endpoint) Value() string {
+	if !ep.HasMetadata() {
+		return ep.Addr
+	}
+
+	val, err := jsonx.MarshalToString(ep)
+	if err != nil {
+		logx.Errorf("marshal endpoint: %v, error: %v", ep, err)
+		return ep.Addr
+	}
+
+	return val
+}
@@ -0,0 +1,34 @@
+package discov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpoint(t *testing.T) {
+	ep := Endpoint{
+		Addr: "localhost:8080",
+	}
+	assert.False(t, ep.HasMetadata())
+	assert.Equal(t, "localhost:8080", ep.Value())
+	assert.Equal(t, ep, ParseEndpoint(ep.Value()))
+
+	ep = Endpoint{
+		Addr:    "localhost:8080",
+		Weight:  10,
+		Zone:    "zone-a",
+		Version: "v2",
+		Tags: map[string]string{
+			"lane": "canary",
+		},
+	}
+	assert.True(t, ep.HasMetadata())
+	assert.Equal(t, `{"addr":"localhost:8080","weight":10,"zone":"zone-a","version":"v2","tags":{"lane":"canary"}}`,
+		ep.Value())
+	assert.Equal(t, ep, ParseEndpoint(ep.Value()))
+}
+
+func TestParseEndpoint_bad(t *testing.T) {
+	assert.Equal(t, Endpoint{Addr: "{bad"}, ParseEndpoint("{bad"))
+}
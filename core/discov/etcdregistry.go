@@ -0,0 +1,110 @@
+package discov
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/r27153733/fastgozero/core/syncx"
+)
+
+const etcdScheme = "etcd"
+
+type etcdRegistry struct {
+	hosts      []string
+	pubOpts    []PubOption
+	publishers map[string]*Publisher
+	lock       sync.Mutex
+}
+
+// NewEtcdRegistry returns a Registry on the etcd cluster of c, the Key of c is ignored,
+// the services are registered and watched on their names as the keys.
+func NewEtcdRegistry(c EtcdConf) (Registry, error) {
+	if c.HasAccount() {
+		RegisterAccount(c.Hosts, c.User, c.Pass)
+	}
+	if c.HasTLS() {
+		if err := RegisterTLS(c.Hosts, c.CertFile, c.CertKeyFile, c.CACertFile,
+			c.InsecureSkipVerify); err != nil {
+			return nil, err
+		}
+	}
+
+	var pubOpts []PubOption
+	if c.HasID() {
+		pubOpts = append(pubOpts, WithId(c.ID))
+	}
+
+	return &etcdRegistry{
+		hosts:      c.Hosts,
+		pubOpts:    pubOpts,
+		publishers: make(map[string]*Publisher),
+	}, nil
+}
+
+// newEtcdRegistryFromURL builds the registry from etcd://host1:2379,host2:2379.
+func newEtcdRegistryFromURL(target *url.URL) (Registry, error) {
+	return NewEtcdRegistry(EtcdConf{
+		Hosts: strings.FieldsFunc(target.Host, func(r rune) bool {
+			return r == ','
+		}),
+	})
+}
+
+func (r *etcdRegistry) Deregister(service string, ep Endpoint) error {
+	key := registryKey(service, ep)
+	r.lock.Lock()
+	pub, ok := r.publishers[key]
+	delete(r.publishers, key)
+	r.lock.Unlock()
+
+	if ok {
+		pub.Stop()
+	}
+
+	return nil
+}
+
+func (r *etcdRegistry) Register(service string, ep Endpoint) error {
+	pub := NewPublisher(r.hosts, service, ep.Value(), r.pubOpts...)
+	if err := pub.KeepAlive(); err != nil {
+		return err
+	}
+
+	key := registryKey(service, ep)
+	r.lock.Lock()
+	prev, ok := r.publishers[key]
+	r.publishers[key] = pub
+	r.lock.Unlock()
+
+	if ok {
+		prev.Stop()
+	}
+
+	return nil
+}
+
+func (r *etcdRegistry) Watch(service string, listener func([]Endpoint)) (func(), error) {
+	sub, err := NewSubscriber(r.hosts, service)
+	if err != nil {
+		return nil, err
+	}
+
+	// the notifying listener might be still called after closing, mute it.
+	stopped := syncx.NewAtomicBool()
+	sub.AddListener(func() {
+		if !stopped.True() {
+			listener(sub.Endpoints())
+		}
+	})
+	listener(sub.Endpoints())
+
+	return func() {
+		stopped.Set(true)
+		sub.Close()
+	}, nil
+}
+
+func registryKey(service string, ep Endpoint) string {
+	return service + "/" + ep.Addr
+}
@@ -0,0 +1,66 @@
+package discov
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/r27153733/fastgozero/core/discov/internal"
+	"github.com/stretchr/testify/assert"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+func TestNewEtcdRegistry(t *testing.T) {
+	_, err := NewEtcdRegistry(EtcdConf{
+		Hosts:       []string{"localhost:2379"},
+		CertFile:    "cert",
+		CertKeyFile: "key",
+		CACertFile:  "ca",
+	})
+	assert.Error(t, err)
+
+	r, err := NewEtcdRegistry(EtcdConf{
+		Hosts: []string{"localhost:2379"},
+		User:  "user",
+		Pass:  "pass",
+		ID:    10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, r.(*etcdRegistry).pubOpts, 1)
+	account, ok := internal.GetAccount([]string{"localhost:2379"})
+	assert.True(t, ok)
+	assert.Equal(t, "user", account.User)
+}
+
+func TestEtcdRegistry_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	const id clientv3.LeaseID = 1
+	conn := createMockConn(t)
+	defer conn.Close()
+	cli := internal.NewMockEtcdClient(ctrl)
+	cli.EXPECT().ActiveConnection().Return(conn).AnyTimes()
+	restore := setMockClient(cli)
+	defer restore()
+	cli.EXPECT().Ctx().AnyTimes()
+	cli.EXPECT().KeepAlive(gomock.Any(), id)
+	cli.EXPECT().Grant(gomock.Any(), timeToLive).Return(&clientv3.LeaseGrantResponse{
+		ID: id,
+	}, nil)
+	cli.EXPECT().Put(gomock.Any(), makeEtcdKey("thekey", int64(id)), "localhost:8080", gomock.Any())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	cli.EXPECT().Revoke(gomock.Any(), id).Do(func(_, _ any) {
+		wg.Done()
+	})
+
+	r, err := NewEtcdRegistry(EtcdConf{
+		Hosts: []string{"the-registry-endpoint"},
+	})
+	assert.NoError(t, err)
+	ep := Endpoint{Addr: "localhost:8080"}
+	assert.NoError(t, r.Register("thekey", ep))
+	assert.NoError(t, r.Deregister("thekey", ep))
+	wg.Wait()
+	assert.NoError(t, r.Deregister("thekey", ep))
+}
@@ -0,0 +1,193 @@
+package discov
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/jsonx"
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/threading"
+	"github.com/r27153733/fastgozero/core/timex"
+)
+
+const (
+	fileScheme       = "file"
+	lockSuffix       = ".lock"
+	fileLockRetry    = 10 * time.Millisecond
+	fileLockTimeout  = 5 * time.Second
+	filePollInterval = time.Second
+)
+
+var errEmptyRegistryFile = errors.New("discov: empty registry file path")
+
+// fileRegistry keeps the instances in a json file, like {"user.rpc":[{"addr":"127.0.0.1:8080"}]},
+// which is for local and development use, the instances of the crashed processes
+// are not cleaned up, because there is no keepalive.
+type fileRegistry struct {
+	path         string
+	pollInterval time.Duration
+}
+
+// newFileRegistry builds the registry from file:///path/to/registry.json,
+// or file://./registry.json with the relative path.
+func newFileRegistry(target *url.URL) (Registry, error) {
+	path := target.Host + target.Path
+	if len(path) == 0 {
+		return nil, errEmptyRegistryFile
+	}
+
+	return &fileRegistry{
+		path:         path,
+		pollInterval: filePollInterval,
+	}, nil
+}
+
+func (r *fileRegistry) Deregister(service string, ep Endpoint) error {
+	return r.update(func(services map[string][]Endpoint) {
+		eps := slices.DeleteFunc(services[service], func(each Endpoint) bool {
+			return each.Addr == ep.Addr
+		})
+		if len(eps) > 0 {
+			services[service] = eps
+		} else {
+			delete(services, service)
+		}
+	})
+}
+
+func (r *fileRegistry) Register(service string, ep Endpoint) error {
+	return r.update(func(services map[string][]Endpoint) {
+		eps := slices.DeleteFunc(services[service], func(each Endpoint) bool {
+			return each.Addr == ep.Addr
+		})
+		services[service] = append(eps, ep)
+	})
+}
+
+// Watch polls the file, because the file might be changed by the other processes.
+func (r *fileRegistry) Watch(service string, listener func([]Endpoint)) (func(), error) {
+	services, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	eps := services[service]
+	listener(eps)
+
+	done := make(chan struct{})
+	threading.GoSafe(func() {
+		ticker := timex.NewTicker(r.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.Chan():
+				services, err := r.load()
+				if err != nil {
+					logx.Errorf("discov: load registry file %q, error: %v", r.path, err)
+					continue
+				}
+				if latest := services[service]; !reflect.DeepEqual(eps, latest) {
+					eps = latest
+					listener(eps)
+				}
+			case <-done:
+				return
+			}
+		}
+	})
+
+	return func() {
+		close(done)
+	}, nil
+}
+
+func (r *fileRegistry) load() (map[string][]Endpoint, error) {
+	content, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return map[string][]Endpoint{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	services := make(map[string][]Endpoint)
+	if len(content) == 0 {
+		return services, nil
+	}
+
+	if err := jsonx.Unmarshal(content, &services); err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// lock locks the file across the processes by creating the lock file exclusively,
+// the lock file older than fileLockTimeout is considered stale and removed.
+func (r *fileRegistry) lock() (func(), error) {
+	lockFile := r.path + lockSuffix
+	deadline := time.Now().Add(fileLockTimeout)
+	for {
+		f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_ = f.Close()
+			return func() {
+				_ = os.Remove(lockFile)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lockFile); err == nil && time.Since(info.ModTime()) > fileLockTimeout {
+			_ = os.Remove(lockFile)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, err
+		}
+
+		time.Sleep(fileLockRetry)
+	}
+}
+
+func (r *fileRegistry) update(fn func(services map[string][]Endpoint)) error {
+	unlock, err := r.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	services, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	fn(services)
+	content, err := jsonx.Marshal(services)
+	if err != nil {
+		return err
+	}
+
+	// write to a temp file and rename, to avoid the watchers reading the partial content.
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), r.path)
+}
@@ -0,0 +1,86 @@
+package discov
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFileRegistry(t *testing.T) {
+	_, err := newFileRegistry(mustParseURL(t, "file://"))
+	assert.ErrorIs(t, err, errEmptyRegistryFile)
+
+	r, err := newFileRegistry(mustParseURL(t, "file://./registry.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "./registry.json", r.(*fileRegistry).path)
+
+	r, err = newFileRegistry(mustParseURL(t, "file:///tmp/registry.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/registry.json", r.(*fileRegistry).path)
+}
+
+func TestFileRegistry(t *testing.T) {
+	r := &fileRegistry{
+		path:         filepath.Join(t.TempDir(), "registry.json"),
+		pollInterval: 10 * time.Millisecond,
+	}
+
+	ch := make(chan []Endpoint, 10)
+	stop, err := r.Watch("user.rpc", func(eps []Endpoint) {
+		ch <- eps
+	})
+	assert.NoError(t, err)
+	defer stop()
+	assert.Empty(t, <-ch)
+
+	assert.NoError(t, r.Register("user.rpc", Endpoint{Addr: "localhost:8080"}))
+	assert.NoError(t, r.Register("user.rpc", Endpoint{Addr: "localhost:8081", Zone: "zone-a"}))
+	assert.NoError(t, r.Register("user.rpc", Endpoint{Addr: "localhost:8080", Version: "v2"}))
+	assert.NoError(t, r.Register("order.rpc", Endpoint{Addr: "localhost:9090"}))
+	assert.Eventually(t, func() bool {
+		select {
+		case eps := <-ch:
+			return assert.ObjectsAreEqual([]Endpoint{
+				{Addr: "localhost:8081", Zone: "zone-a"},
+				{Addr: "localhost:8080", Version: "v2"},
+			}, eps)
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, r.Deregister("user.rpc", Endpoint{Addr: "localhost:8080"}))
+	assert.NoError(t, r.Deregister("order.rpc", Endpoint{Addr: "localhost:9090"}))
+	services, err := r.load()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]Endpoint{
+		"user.rpc": {
+			{Addr: "localhost:8081", Zone: "zone-a"},
+		},
+	}, services)
+	_, err = os.Stat(r.path + lockSuffix)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileRegistry_lock(t *testing.T) {
+	r := &fileRegistry{
+		path: filepath.Join(t.TempDir(), "registry.json"),
+	}
+
+	// stale lock file is removed
+	lockFile := r.path + lockSuffix
+	assert.NoError(t, os.WriteFile(lockFile, nil, 0o644))
+	stale := time.Now().Add(-2 * fileLockTimeout)
+	assert.NoError(t, os.Chtimes(lockFile, stale, stale))
+	unlock, err := r.lock()
+	assert.NoError(t, err)
+	unlock()
+
+	assert.NoError(t, os.WriteFile(r.path, []byte("{"), 0o644))
+	assert.Error(t, r.Register("user.rpc", Endpoint{Addr: "localhost:8080"}))
+	_, err = r.Watch("user.rpc", func([]Endpoint) {})
+	assert.Error(t, err)
+}
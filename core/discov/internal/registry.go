@@ -59,6 +59,18 @@ func (r *Registry) Monitor(endpoints []string, key string, l UpdateListener, exa
 	return c.monitor(key, l, exactMatch)
 }
 
+// Unmonitor stops notifying l of the changes of the key on given etcd endpoints,
+// the key is not watched anymore after its last listener is removed.
+func (r *Registry) Unmonitor(endpoints []string, key string, l UpdateListener) {
+	r.lock.RLock()
+	c, ok := r.clusters[getClusterKey(endpoints)]
+	r.lock.RUnlock()
+
+	if ok {
+		c.unmonitor(key, l)
+	}
+}
+
 func (r *Registry) getCluster(endpoints []string) (c *cluster, exists bool) {
 	clusterKey := getClusterKey(endpoints)
 	r.lock.RLock()
@@ -84,6 +96,7 @@ type cluster struct {
 	key        string
 	values     map[string]map[string]string
 	listeners  map[string][]UpdateListener
+	watchStops map[string]chan lang.PlaceholderType
 	watchGroup *threading.RoutineGroup
 	done       chan lang.PlaceholderType
 	lock       sync.RWMutex
@@ -96,6 +109,7 @@ func newCluster(endpoints []string) *cluster {
 		key:        getClusterKey(endpoints),
 		values:     make(map[string]map[string]string),
 		listeners:  make(map[string][]UpdateListener),
+		watchStops: make(map[string]chan lang.PlaceholderType),
 		watchGroup: threading.NewRoutineGroup(),
 		done:       make(chan lang.PlaceholderType),
 	}
@@ -257,6 +271,9 @@ func (c *cluster) load(cli EtcdClient, key string) int64 {
 func (c *cluster) monitor(key string, l UpdateListener, exactMatch bool) error {
 	c.lock.Lock()
 	c.listeners[key] = append(c.listeners[key], l)
+	if _, ok := c.watchStops[key]; !ok {
+		c.watchStops[key] = make(chan lang.PlaceholderType)
+	}
 	c.exactMatch = exactMatch
 	c.lock.Unlock()
 
@@ -305,6 +322,30 @@ func (c *cluster) reload(cli EtcdClient) {
 	}
 }
 
+func (c *cluster) unmonitor(key string, l UpdateListener) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	listeners := c.listeners[key]
+	for i, each := range listeners {
+		if each == l {
+			listeners = append(listeners[:i:i], listeners[i+1:]...)
+			break
+		}
+	}
+	if len(listeners) > 0 {
+		c.listeners[key] = listeners
+		return
+	}
+
+	delete(c.listeners, key)
+	delete(c.values, key)
+	if stop, ok := c.watchStops[key]; ok {
+		close(stop)
+		delete(c.watchStops, key)
+	}
+}
+
 func (c *cluster) watch(cli EtcdClient, key string, rev int64) {
 	for {
 		err := c.watchStream(cli, key, rev)
@@ -336,6 +377,14 @@ func (c *cluster) watchStream(cli EtcdClient, key string, rev int64) error {
 		ops = append(ops, clientv3.WithRev(rev+1))
 	}
 
+	c.lock.RLock()
+	stop, ok := c.watchStops[key]
+	c.lock.RUnlock()
+	// the key is unmonitored.
+	if !ok {
+		return nil
+	}
+
 	rch = cli.Watch(clientv3.WithRequireLeader(c.context(cli)), watchKey, ops...)
 
 	for {
@@ -354,6 +403,8 @@ func (c *cluster) watchStream(cli EtcdClient, key string, rev int64) error {
 			c.handleWatchEvents(key, wresp.Events)
 		case <-c.done:
 			return nil
+		case <-stop:
+			return nil
 		}
 	}
 }
@@ -162,6 +162,9 @@ func TestCluster_Watch(t *testing.T) {
 			c := &cluster{
 				listeners: make(map[string][]UpdateListener),
 				values:    make(map[string]map[string]string),
+				watchStops: map[string]chan lang.PlaceholderType{
+					"any": make(chan lang.PlaceholderType),
+				},
 			}
 			listener := NewMockUpdateListener(ctrl)
 			c.listeners["any"] = []UpdateListener{listener}
@@ -213,6 +216,9 @@ func TestClusterWatch_RespFailures(t *testing.T) {
 			cli.EXPECT().Ctx().Return(context.Background()).AnyTimes()
 			c := new(cluster)
 			c.done = make(chan lang.PlaceholderType)
+			c.watchStops = map[string]chan lang.PlaceholderType{
+				"any": make(chan lang.PlaceholderType),
+			}
 			go func() {
 				ch <- resp
 				close(c.done)
@@ -233,6 +239,9 @@ func TestClusterWatch_CloseChan(t *testing.T) {
 	cli.EXPECT().Ctx().Return(context.Background()).AnyTimes()
 	c := new(cluster)
 	c.done = make(chan lang.PlaceholderType)
+	c.watchStops = map[string]chan lang.PlaceholderType{
+		"any": make(chan lang.PlaceholderType),
+	}
 	go func() {
 		close(ch)
 		close(c.done)
@@ -240,6 +249,51 @@ func TestClusterWatch_CloseChan(t *testing.T) {
 	c.watch(cli, "any", 0)
 }
 
+func TestCluster_Unmonitor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cli := NewMockEtcdClient(ctrl)
+	ch := make(chan clientv3.WatchResponse)
+	cli.EXPECT().Watch(gomock.Any(), "any/", gomock.Any()).Return(ch).AnyTimes()
+	cli.EXPECT().Ctx().Return(context.Background()).AnyTimes()
+
+	l1, l2 := new(mockListener), new(mockListener)
+	c := newCluster([]string{"any"})
+	c.listeners["any"] = []UpdateListener{l1, l2}
+	c.values["any"] = map[string]string{"foo": "bar"}
+	c.watchStops["any"] = make(chan lang.PlaceholderType)
+	done := make(chan lang.PlaceholderType)
+	go func() {
+		c.watch(cli, "any", 0)
+		close(done)
+	}()
+
+	c.unmonitor("any", l1)
+	assert.Equal(t, []UpdateListener{l2}, c.listeners["any"])
+	c.unmonitor("other", l1)
+	c.unmonitor("any", l2)
+	assert.Empty(t, c.listeners)
+	assert.Empty(t, c.values)
+	assert.Empty(t, c.watchStops)
+	// the watch of the key stops without listeners.
+	<-done
+}
+
+func TestRegistry_Unmonitor(t *testing.T) {
+	endpoints := []string{stringx.Rand()}
+	l := new(mockListener)
+	c, _ := GetRegistry().getCluster(endpoints)
+	c.lock.Lock()
+	c.listeners["foo"] = []UpdateListener{l}
+	c.lock.Unlock()
+
+	GetRegistry().Unmonitor([]string{"unknown"}, "foo", l)
+	GetRegistry().Unmonitor(endpoints, "foo", l)
+	c.lock.RLock()
+	assert.Empty(t, c.listeners)
+	c.lock.RUnlock()
+}
+
 func TestValueOnlyContext(t *testing.T) {
 	ctx := contextx.ValueOnlyFrom(context.Background())
 	ctx.Done()
@@ -280,7 +334,8 @@ func TestRegistry_Monitor(t *testing.T) {
 	GetRegistry().lock.Lock()
 	GetRegistry().clusters = map[string]*cluster{
 		getClusterKey(endpoints): {
-			listeners: map[string][]UpdateListener{},
+			listeners:  map[string][]UpdateListener{},
+			watchStops: map[string]chan lang.PlaceholderType{},
 			values: map[string]map[string]string{
 				"foo": {
 					"bar": "baz",
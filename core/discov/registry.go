@@ -0,0 +1,63 @@
+package discov
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+var (
+	// ErrReadOnlyRegistry is an error that indicates the registry can't register the instances,
+	// like DNS and kubernetes, which register the instances by themselves.
+	ErrReadOnlyRegistry = errors.New("discov: read only registry")
+
+	registryBuilders = map[string]RegistryBuilder{
+		etcdScheme: newEtcdRegistryFromURL,
+		dnsScheme:  newDnsRegistry,
+		fileScheme: newFileRegistry,
+	}
+	registryLock sync.RWMutex
+)
+
+type (
+	// A Registry registers the instances of the services and watches the changes of them.
+	Registry interface {
+		// Register registers ep as an instance of service, and keeps it alive until deregistered.
+		Register(service string, ep Endpoint) error
+		// Deregister deregisters ep from the instances of service.
+		Deregister(service string, ep Endpoint) error
+		// Watch calls listener with all the instances of service once watched and on changes,
+		// the returned function stops watching.
+		Watch(service string, listener func([]Endpoint)) (func(), error)
+	}
+
+	// RegistryBuilder builds a Registry from the target, like etcd://127.0.0.1:2379.
+	RegistryBuilder func(target *url.URL) (Registry, error)
+)
+
+// NewRegistry returns a Registry built by the builder registered with the scheme of target.
+func NewRegistry(target string) (Registry, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	registryLock.RLock()
+	builder, ok := registryBuilders[u.Scheme]
+	registryLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("discov: unknown registry scheme %q", u.Scheme)
+	}
+
+	return builder(u)
+}
+
+// RegisterRegistry registers the builder of the registries with the given scheme,
+// the previous one with the same scheme is replaced.
+func RegisterRegistry(scheme string, builder RegistryBuilder) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	registryBuilders[scheme] = builder
+}
@@ -0,0 +1,40 @@
+package discov
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRegistry(t *testing.T) {
+	_, err := NewRegistry(":bad")
+	assert.Error(t, err)
+
+	_, err = NewRegistry("unknown:///")
+	assert.Error(t, err)
+
+	r, err := NewRegistry("file:///tmp/registry.json")
+	assert.NoError(t, err)
+	assert.IsType(t, &fileRegistry{}, r)
+
+	r, err = NewRegistry("dns:///")
+	assert.NoError(t, err)
+	assert.IsType(t, &dnsRegistry{}, r)
+
+	r, err = NewRegistry("etcd://localhost:2379,localhost:2380")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"localhost:2379", "localhost:2380"}, r.(*etcdRegistry).hosts)
+}
+
+func TestRegisterRegistry(t *testing.T) {
+	var target string
+	RegisterRegistry("mock", func(u *url.URL) (Registry, error) {
+		target = u.String()
+		return nil, nil
+	})
+
+	_, err := NewRegistry("mock://foo/bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "mock://foo/bar", target)
+}
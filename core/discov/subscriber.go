@@ -16,6 +16,7 @@ type (
 	// A Subscriber is used to subscribe the given key on an etcd cluster.
 	Subscriber struct {
 		endpoints  []string
+		key        string
 		exclusive  bool
 		exactMatch bool
 		items      *container
@@ -29,6 +30,7 @@ type (
 func NewSubscriber(endpoints []string, key string, opts ...SubOption) (*Subscriber, error) {
 	sub := &Subscriber{
 		endpoints: endpoints,
+		key:       key,
 	}
 	for _, opt := range opts {
 		opt(sub)
@@ -47,6 +49,23 @@ func (s *Subscriber) AddListener(listener func()) {
 	s.items.addListener(listener)
 }
 
+// Close closes s, the listeners of s are not notified anymore,
+// and the key is not watched anymore if no other subscribers on it.
+func (s *Subscriber) Close() {
+	internal.GetRegistry().Unmonitor(s.endpoints, s.key, s.items)
+}
+
+// Endpoints returns all the subscription values parsed as endpoints.
+func (s *Subscriber) Endpoints() []Endpoint {
+	vals := s.items.getValues()
+	eps := make([]Endpoint, 0, len(vals))
+	for _, val := range vals {
+		eps = append(eps, ParseEndpoint(val))
+	}
+
+	return eps
+}
+
 // Values returns all the subscription values.
 func (s *Subscriber) Values() []string {
 	return s.items.getValues()
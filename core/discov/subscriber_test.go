@@ -214,6 +214,37 @@ func TestSubscriber(t *testing.T) {
 	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
 }
 
+func TestSubscriber_Close(t *testing.T) {
+	sub := &Subscriber{
+		endpoints: []string{stringx.Rand()},
+		key:       "foo",
+		items:     newContainer(false),
+	}
+	assert.NotPanics(t, sub.Close)
+}
+
+func TestSubscriber_Endpoints(t *testing.T) {
+	sub := new(Subscriber)
+	sub.items = newContainer(false)
+	sub.items.OnAdd(internal.KV{
+		Key: "foo",
+		Val: "localhost:8080",
+	})
+	sub.items.OnAdd(internal.KV{
+		Key: "bar",
+		Val: `{"addr":"localhost:8081","zone":"zone-a"}`,
+	})
+	assert.ElementsMatch(t, []Endpoint{
+		{
+			Addr: "localhost:8080",
+		},
+		{
+			Addr: "localhost:8081",
+			Zone: "zone-a",
+		},
+	}, sub.Endpoints())
+}
+
 func TestWithSubEtcdAccount(t *testing.T) {
 	endpoints := []string{"localhost:2379"}
 	user := stringx.Rand()
@@ -0,0 +1,80 @@
+package routetag
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+const (
+	// HeaderKey is the http header that carries the route tags, like version=v2,lane=canary.
+	HeaderKey = "X-Route-Tags"
+	// MetadataKey is the gRPC metadata key that carries the route tags across rpc hops.
+	MetadataKey = "x-route-tags"
+
+	pairSeparator = ","
+	kvSeparator   = "="
+)
+
+// ContextKey is the key of the route tags in context, it's exported to let the fasthttp
+// handlers set the route tags with RequestCtx.SetUserValue.
+var ContextKey any = contextKey{}
+
+type contextKey struct{}
+
+// FromContext returns the route tags in ctx, or empty if not set.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	tags, _ := ctx.Value(ContextKey).(string)
+	return tags
+}
+
+// NewContext returns a new context with the given route tags.
+func NewContext(ctx context.Context, tags string) context.Context {
+	return context.WithValue(ctx, ContextKey, tags)
+}
+
+// Format formats the tags into the canonical form, sorted by keys.
+func Format(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			builder.WriteString(pairSeparator)
+		}
+		builder.WriteString(k)
+		builder.WriteString(kvSeparator)
+		builder.WriteString(tags[k])
+	}
+
+	return builder.String()
+}
+
+// Parse parses the route tags like version=v2,lane=canary, the malformed pairs are ignored.
+func Parse(tags string) map[string]string {
+	kvs := make(map[string]string)
+	for _, pair := range strings.Split(tags, pairSeparator) {
+		k, v, ok := strings.Cut(pair, kvSeparator)
+		if !ok {
+			continue
+		}
+
+		k = strings.TrimSpace(k)
+		v = strings.TrimSpace(v)
+		if len(k) == 0 || len(v) == 0 {
+			continue
+		}
+
+		kvs[k] = v
+	}
+
+	return kvs
+}
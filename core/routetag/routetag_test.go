@@ -0,0 +1,34 @@
+package routetag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+	//nolint:staticcheck
+	assert.Empty(t, FromContext(nil))
+
+	ctx := NewContext(context.Background(), "version=v2")
+	assert.Equal(t, "version=v2", FromContext(ctx))
+}
+
+func TestParse(t *testing.T) {
+	assert.Empty(t, Parse(""))
+	assert.Equal(t, map[string]string{
+		"version": "v2",
+		"lane":    "canary",
+	}, Parse(" version = v2, lane=canary,bad,=v,k="))
+}
+
+func TestFormat(t *testing.T) {
+	assert.Empty(t, Format(nil))
+	assert.Equal(t, "lane=canary,version=v2", Format(map[string]string{
+		"version": "v2",
+		"lane":    "canary",
+	}))
+	assert.Equal(t, "lane=canary,version=v2", Format(Parse("version=v2,lane=canary")))
+}
@@ -0,0 +1,313 @@
+package builder
+
+import "reflect"
+
+type (
+	// A Cond is a condition used in where and having clauses.
+	Cond interface {
+		build(w *writer)
+	}
+
+	andCond []Cond
+
+	orCond []Cond
+
+	notCond struct {
+		cond Cond
+	}
+
+	compareCond struct {
+		column string
+		op     string
+		val    any
+	}
+
+	inCond struct {
+		column string
+		vals   []any
+		not    bool
+	}
+
+	betweenCond struct {
+		column string
+		low    any
+		high   any
+	}
+
+	nullCond struct {
+		column string
+		not    bool
+	}
+
+	exprCond struct {
+		sql  string
+		args []any
+	}
+)
+
+// And returns a Cond that all the conds are true, the nil conds are ignored.
+func And(conds ...Cond) Cond {
+	return andCond(conds)
+}
+
+// Between returns a Cond of column between low and high.
+func Between(column string, low, high any) Cond {
+	return betweenCond{
+		column: column,
+		low:    low,
+		high:   high,
+	}
+}
+
+// Eq returns a Cond of column = val, or column is null if val is nil.
+func Eq(column string, val any) Cond {
+	if val == nil {
+		return IsNull(column)
+	}
+
+	return compareCond{column: column, op: "=", val: val}
+}
+
+// Expr returns a Cond of the raw sql, the ? in sql are the placeholders of args.
+func Expr(sql string, args ...any) Cond {
+	return exprCond{
+		sql:  sql,
+		args: args,
+	}
+}
+
+// Gt returns a Cond of column > val.
+func Gt(column string, val any) Cond {
+	return compareCond{column: column, op: ">", val: val}
+}
+
+// Gte returns a Cond of column >= val.
+func Gte(column string, val any) Cond {
+	return compareCond{column: column, op: ">=", val: val}
+}
+
+// If returns cond if ok, otherwise nil, which is used to build the dynamic filters.
+func If(ok bool, cond Cond) Cond {
+	if ok {
+		return cond
+	}
+
+	return nil
+}
+
+// In returns a Cond of column in vals, vals can be a slice or the values.
+// It's always false if vals is empty.
+func In(column string, vals ...any) Cond {
+	return inCond{
+		column: column,
+		vals:   flatten(vals),
+	}
+}
+
+// IsNotNull returns a Cond of column is not null.
+func IsNotNull(column string) Cond {
+	return nullCond{
+		column: column,
+		not:    true,
+	}
+}
+
+// IsNull returns a Cond of column is null.
+func IsNull(column string) Cond {
+	return nullCond{
+		column: column,
+	}
+}
+
+// Like returns a Cond of column like pattern.
+func Like(column string, pattern string) Cond {
+	return compareCond{column: column, op: "like", val: pattern}
+}
+
+// Lt returns a Cond of column < val.
+func Lt(column string, val any) Cond {
+	return compareCond{column: column, op: "<", val: val}
+}
+
+// Lte returns a Cond of column <= val.
+func Lte(column string, val any) Cond {
+	return compareCond{column: column, op: "<=", val: val}
+}
+
+// Ne returns a Cond of column <> val, or column is not null if val is nil.
+func Ne(column string, val any) Cond {
+	if val == nil {
+		return IsNotNull(column)
+	}
+
+	return compareCond{column: column, op: "<>", val: val}
+}
+
+// Not returns a Cond that cond is false.
+func Not(cond Cond) Cond {
+	return notCond{
+		cond: cond,
+	}
+}
+
+// NotIn returns a Cond of column not in vals, vals can be a slice or the values.
+// It's always true if vals is empty.
+func NotIn(column string, vals ...any) Cond {
+	return inCond{
+		column: column,
+		vals:   flatten(vals),
+		not:    true,
+	}
+}
+
+// Or returns a Cond that any of the conds is true, the nil conds are ignored.
+func Or(conds ...Cond) Cond {
+	return orCond(conds)
+}
+
+func (c andCond) build(w *writer) {
+	joinConds(w, c, " and ")
+}
+
+func (c betweenCond) build(w *writer) {
+	w.write(c.column, " between ")
+	w.arg(c.low)
+	w.write(" and ")
+	w.arg(c.high)
+}
+
+func (c compareCond) build(w *writer) {
+	w.write(c.column, " ", c.op, " ")
+	w.arg(c.val)
+}
+
+func (c exprCond) build(w *writer) {
+	w.expr(c.sql, c.args)
+}
+
+func (c inCond) build(w *writer) {
+	if len(c.vals) == 0 {
+		if c.not {
+			w.write("1 = 1")
+		} else {
+			w.write("1 = 0")
+		}
+		return
+	}
+
+	w.write(c.column)
+	if c.not {
+		w.write(" not")
+	}
+	w.write(" in (")
+	for i, val := range c.vals {
+		if i > 0 {
+			w.write(", ")
+		}
+		w.arg(val)
+	}
+	w.write(")")
+}
+
+func (c notCond) build(w *writer) {
+	w.write("not (")
+	c.cond.build(w)
+	w.write(")")
+}
+
+func (c nullCond) build(w *writer) {
+	if c.not {
+		w.write(c.column, " is not null")
+	} else {
+		w.write(c.column, " is null")
+	}
+}
+
+func (c orCond) build(w *writer) {
+	joinConds(w, c, " or ")
+}
+
+// flatten expands the slice in vals, except []byte.
+func flatten(vals []any) []any {
+	if len(vals) != 1 {
+		return vals
+	}
+
+	if _, ok := vals[0].([]byte); ok {
+		return vals
+	}
+
+	v := reflect.ValueOf(vals[0])
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return vals
+	}
+
+	out := make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		out = append(out, v.Index(i).Interface())
+	}
+
+	return out
+}
+
+// isEmpty returns true if cond builds nothing.
+func isEmpty(cond Cond) bool {
+	switch c := cond.(type) {
+	case nil:
+		return true
+	case andCond:
+		return len(nonEmpty(c)) == 0
+	case orCond:
+		return len(nonEmpty(c)) == 0
+	case notCond:
+		return isEmpty(c.cond)
+	default:
+		return false
+	}
+}
+
+func joinConds(w *writer, conds []Cond, sep string) {
+	conds = nonEmpty(conds)
+	if len(conds) == 1 {
+		conds[0].build(w)
+		return
+	}
+
+	for i, cond := range conds {
+		if i > 0 {
+			w.write(sep)
+		}
+		if needParens(cond) {
+			w.write("(")
+			cond.build(w)
+			w.write(")")
+		} else {
+			cond.build(w)
+		}
+	}
+}
+
+// needParens returns true if cond might be mixed up with the adjacent conds.
+func needParens(cond Cond) bool {
+	switch c := cond.(type) {
+	case andCond:
+		return len(nonEmpty(c)) > 1
+	case orCond:
+		return len(nonEmpty(c)) > 1
+	case exprCond:
+		return true
+	default:
+		return false
+	}
+}
+
+func nonEmpty(conds []Cond) []Cond {
+	var out []Cond
+	for _, cond := range conds {
+		if !isEmpty(cond) {
+			out = append(out, cond)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,63 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConds(t *testing.T) {
+	tests := []struct {
+		name  string
+		cond  Cond
+		query string
+		args  []any
+	}{
+		{name: "eq", cond: Eq("id", 1), query: "id = ?", args: []any{1}},
+		{name: "eq nil", cond: Eq("name", nil), query: "name is null"},
+		{name: "ne", cond: Ne("id", 1), query: "id <> ?", args: []any{1}},
+		{name: "ne nil", cond: Ne("name", nil), query: "name is not null"},
+		{name: "gt gte", cond: And(Gt("a", 1), Gte("b", 2)), query: "a > ? and b >= ?", args: []any{1, 2}},
+		{name: "lt lte", cond: Or(Lt("a", 1), Lte("b", 2)), query: "a < ? or b <= ?", args: []any{1, 2}},
+		{name: "like", cond: Like("name", "go%"), query: "name like ?", args: []any{"go%"}},
+		{name: "between", cond: Between("age", 18, 30), query: "age between ? and ?", args: []any{18, 30}},
+		{name: "in slice", cond: In("id", []int64{1, 2}), query: "id in (?, ?)", args: []any{int64(1), int64(2)}},
+		{name: "in values", cond: In("id", 1, 2), query: "id in (?, ?)", args: []any{1, 2}},
+		{name: "in bytes", cond: In("hash", []byte("a")), query: "hash in (?)", args: []any{[]byte("a")}},
+		{name: "in empty", cond: In("id", []int64{}), query: "1 = 0"},
+		{name: "not in", cond: NotIn("id", 1), query: "id not in (?)", args: []any{1}},
+		{name: "not in empty", cond: NotIn("id"), query: "1 = 1"},
+		{name: "not", cond: Not(Eq("a", 1)), query: "not (a = ?)", args: []any{1}},
+		{
+			name:  "nested",
+			cond:  And(Eq("a", 1), Or(Eq("b", 2), Eq("c", 3)), If(false, Eq("d", 4)), Or(Eq("e", 5))),
+			query: "a = ? and (b = ? or c = ?) and e = ?",
+			args:  []any{1, 2, 3, 5},
+		},
+		{
+			name:  "expr",
+			cond:  And(Eq("a", 1), Expr("b = ? or c = '?'", 2)),
+			query: "a = ? and (b = ? or c = '?')",
+			args:  []any{1, 2},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			w := newWriter(MySQL)
+			test.cond.build(w)
+			query, args, err := w.result()
+			assert.NoError(t, err)
+			assert.Equal(t, test.query, query)
+			assert.Equal(t, test.args, args)
+		})
+	}
+}
+
+func TestExprArgsMismatch(t *testing.T) {
+	_, _, err := Select().From("users").Where(Expr("a = ? and b = ?", 1)).Build()
+	assert.ErrorIs(t, err, ErrArgsMismatch)
+	_, _, err = Select().From("users").Where(Expr("a = ?", 1, 2)).Build()
+	assert.ErrorIs(t, err, ErrArgsMismatch)
+}
@@ -0,0 +1,119 @@
+package builder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidCursor is an error that indicates the cursor is malformed.
+var ErrInvalidCursor = errors.New("builder: invalid cursor")
+
+type (
+	// A Keyset is the columns to paginate by, which must be unique together, like the primary key.
+	// The rows are paginated by the values of the columns on the last row of the previous page,
+	// instead of offset, so that the cost doesn't grow with the pages.
+	Keyset struct {
+		Columns []string
+		Desc    bool
+	}
+
+	keysetCond struct {
+		columns []string
+		op      string
+		vals    []any
+	}
+)
+
+// DecodeCursor decodes the cursor into dest, in the same order of the encoded values.
+func DecodeCursor(cursor string, dest ...any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	var vals []json.RawMessage
+	if err := json.Unmarshal(data, &vals); err != nil || len(vals) != len(dest) {
+		return ErrInvalidCursor
+	}
+
+	for i, val := range vals {
+		if err := json.Unmarshal(val, dest[i]); err != nil {
+			return ErrInvalidCursor
+		}
+	}
+
+	return nil
+}
+
+// EncodeCursor encodes vals into an opaque cursor.
+func EncodeCursor(vals ...any) (string, error) {
+	data, err := json.Marshal(vals)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// After returns a Cond of the rows after the row with the values of the columns.
+func (k Keyset) After(vals ...any) Cond {
+	op := ">"
+	if k.Desc {
+		op = "<"
+	}
+
+	return keysetCond{
+		columns: k.Columns,
+		op:      op,
+		vals:    vals,
+	}
+}
+
+// Apply returns a copy of b with the conds and orders to select limit rows after the row
+// with last values, b is not changed to be reused for the other pages.
+// It selects the first page if last is empty.
+// The orders of the keyset go before the existing orders of b, otherwise the rows wouldn't be
+// sorted by the keyset and the cursor could skip or repeat rows. Because the keyset is unique,
+// the existing orders never take effect.
+func (k Keyset) Apply(b *SelectBuilder, last []any, limit int64) *SelectBuilder {
+	b = b.Clone()
+	if len(last) > 0 {
+		b.Where(k.After(last...))
+	}
+
+	orders := make([]string, 0, len(k.Columns)+len(b.orderBy))
+	for _, column := range k.Columns {
+		if k.Desc {
+			orders = append(orders, column+" desc")
+		} else {
+			orders = append(orders, column)
+		}
+	}
+	b.orderBy = append(orders, b.orderBy...)
+
+	return b.Limit(limit)
+}
+
+func (c keysetCond) build(w *writer) {
+	if len(c.columns) == 0 || len(c.columns) != len(c.vals) {
+		w.fail(ErrArgsMismatch)
+		return
+	}
+
+	if len(c.columns) == 1 {
+		w.write(c.columns[0], " ", c.op, " ")
+		w.arg(c.vals[0])
+		return
+	}
+
+	w.write("(", strings.Join(c.columns, ", "), ") ", c.op, " (")
+	for i, val := range c.vals {
+		if i > 0 {
+			w.write(", ")
+		}
+		w.arg(val)
+	}
+	w.write(")")
+}
@@ -0,0 +1,75 @@
+package builder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyset_Apply(t *testing.T) {
+	keyset := Keyset{Columns: []string{"id"}}
+	query, args, err := keyset.Apply(Select("id", "name").From("users"), nil, 10).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select id, name from users order by id limit 10", query)
+	assert.Empty(t, args)
+
+	query, args, err = keyset.Apply(Select().From("users").Where(Eq("state", 1)), []any{5}, 10).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users where state = ? and id > ? order by id limit 10", query)
+	assert.Equal(t, []any{1, 5}, args)
+
+	keyset = Keyset{Columns: []string{"created_at", "id"}, Desc: true}
+	query, args, err = keyset.Apply(Postgres.Select().From("users"), []any{"2024-01-01", 5}, 20).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users where (created_at, id) < ($1, $2)"+
+		" order by created_at desc, id desc limit 20", query)
+	assert.Equal(t, []any{"2024-01-01", 5}, args)
+
+	_, _, err = keyset.Apply(Select().From("users"), []any{1}, 20).Build()
+	assert.ErrorIs(t, err, ErrArgsMismatch)
+
+	// the orders of the keyset go first.
+	keyset = Keyset{Columns: []string{"id"}}
+	query, args, err = keyset.Apply(Select().From("users").OrderBy("name"), []any{5}, 10).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users where id > ? order by id, name limit 10", query)
+	assert.Equal(t, []any{5}, args)
+
+	// the given builder is reused for the other pages.
+	sb := Select().From("users").Where(Eq("state", 1))
+	keyset = Keyset{Columns: []string{"id"}}
+	keyset.Apply(sb, []any{5}, 10)
+	query, args, err = keyset.Apply(sb, []any{15}, 10).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users where state = ? and id > ? order by id limit 10", query)
+	assert.Equal(t, []any{1, 15}, args)
+	query, args, err = sb.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users where state = ?", query)
+	assert.Equal(t, []any{1}, args)
+}
+
+func TestCursor(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	cursor, err := EncodeCursor(int64(1<<62+1), "kevin", at)
+	assert.NoError(t, err)
+
+	var (
+		id   int64
+		name string
+		when time.Time
+	)
+	assert.NoError(t, DecodeCursor(cursor, &id, &name, &when))
+	assert.Equal(t, int64(1<<62+1), id)
+	assert.Equal(t, "kevin", name)
+	assert.True(t, at.Equal(when))
+
+	assert.Equal(t, ErrInvalidCursor, DecodeCursor(cursor, &id))
+	assert.Equal(t, ErrInvalidCursor, DecodeCursor("!", &id))
+	bad, err := EncodeCursor("a")
+	assert.NoError(t, err)
+	assert.Equal(t, ErrInvalidCursor, DecodeCursor(bad, &id))
+	_, err = EncodeCursor(make(chan int))
+	assert.Error(t, err)
+}
@@ -0,0 +1,466 @@
+package builder
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// mysqlMaxLimit is the max limit of mysql, to select all the rows after the offset.
+const mysqlMaxLimit = "18446744073709551615"
+
+const (
+	// MySQL is the dialect that uses ? as the placeholders.
+	MySQL Dialect = iota
+	// Postgres is the dialect that uses $n as the placeholders.
+	Postgres
+)
+
+type (
+	// A Dialect is the sql dialect that the statements are built for.
+	Dialect int
+
+	// A SelectBuilder builds a select statement.
+	SelectBuilder struct {
+		dialect   Dialect
+		columns   []string
+		table     string
+		joins     []join
+		where     []Cond
+		groupBy   []string
+		having    []Cond
+		orderBy   []string
+		limit     int64
+		offset    int64
+		forUpdate bool
+	}
+
+	// An InsertBuilder builds an insert statement.
+	InsertBuilder struct {
+		dialect   Dialect
+		table     string
+		columns   []string
+		rows      [][]any
+		upsert    bool
+		keys      []string
+		updates   []string
+		returning []string
+	}
+
+	// An UpdateBuilder builds an update statement.
+	UpdateBuilder struct {
+		dialect Dialect
+		table   string
+		sets    []assignment
+		where   []Cond
+		all     bool
+	}
+
+	// A DeleteBuilder builds a delete statement.
+	DeleteBuilder struct {
+		dialect Dialect
+		table   string
+		where   []Cond
+		all     bool
+	}
+
+	join struct {
+		kind  string
+		table string
+		on    Cond
+	}
+
+	assignment struct {
+		column string
+		val    any
+		expr   Cond
+	}
+)
+
+// Delete returns a DeleteBuilder of table with MySQL dialect.
+func Delete(table string) *DeleteBuilder {
+	return MySQL.Delete(table)
+}
+
+// Insert returns an InsertBuilder of table with MySQL dialect.
+func Insert(table string) *InsertBuilder {
+	return MySQL.Insert(table)
+}
+
+// Select returns a SelectBuilder of columns with MySQL dialect, * if no columns.
+func Select(columns ...string) *SelectBuilder {
+	return MySQL.Select(columns...)
+}
+
+// Update returns an UpdateBuilder of table with MySQL dialect.
+func Update(table string) *UpdateBuilder {
+	return MySQL.Update(table)
+}
+
+// Delete returns a DeleteBuilder of table.
+func (d Dialect) Delete(table string) *DeleteBuilder {
+	return &DeleteBuilder{
+		dialect: d,
+		table:   table,
+	}
+}
+
+// Insert returns an InsertBuilder of table.
+func (d Dialect) Insert(table string) *InsertBuilder {
+	return &InsertBuilder{
+		dialect: d,
+		table:   table,
+	}
+}
+
+// Select returns a SelectBuilder of columns, * if no columns.
+func (d Dialect) Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{
+		dialect: d,
+		columns: columns,
+		limit:   -1,
+		offset:  -1,
+	}
+}
+
+// Update returns an UpdateBuilder of table.
+func (d Dialect) Update(table string) *UpdateBuilder {
+	return &UpdateBuilder{
+		dialect: d,
+		table:   table,
+	}
+}
+
+// Build builds the select statement and its args.
+func (b *SelectBuilder) Build() (string, []any, error) {
+	if len(b.table) == 0 {
+		return "", nil, ErrEmptyTable
+	}
+
+	w := newWriter(b.dialect)
+	w.write("select ")
+	if len(b.columns) == 0 {
+		w.write("*")
+	} else {
+		w.write(strings.Join(b.columns, ", "))
+	}
+	w.write(" from ", b.table)
+	for _, j := range b.joins {
+		w.write(" ", j.kind, " ", j.table)
+		if !isEmpty(j.on) {
+			w.write(" on ")
+			j.on.build(w)
+		}
+	}
+	w.where("where", b.where)
+	if len(b.groupBy) > 0 {
+		w.write(" group by ", strings.Join(b.groupBy, ", "))
+	}
+	w.where("having", b.having)
+	if len(b.orderBy) > 0 {
+		w.write(" order by ", strings.Join(b.orderBy, ", "))
+	}
+	if b.limit >= 0 {
+		w.write(" limit ", strconv.FormatInt(b.limit, 10))
+	} else if b.offset >= 0 && b.dialect == MySQL {
+		// mysql doesn't support offset without limit.
+		w.write(" limit ", mysqlMaxLimit)
+	}
+	if b.offset >= 0 {
+		w.write(" offset ", strconv.FormatInt(b.offset, 10))
+	}
+	if b.forUpdate {
+		w.write(" for update")
+	}
+
+	return w.result()
+}
+
+// Clone returns a copy of b, the changes on the copy don't affect b.
+func (b *SelectBuilder) Clone() *SelectBuilder {
+	clone := *b
+	clone.columns = slices.Clone(b.columns)
+	clone.joins = slices.Clone(b.joins)
+	clone.where = slices.Clone(b.where)
+	clone.groupBy = slices.Clone(b.groupBy)
+	clone.having = slices.Clone(b.having)
+	clone.orderBy = slices.Clone(b.orderBy)
+	return &clone
+}
+
+// ForUpdate locks the selected rows.
+func (b *SelectBuilder) ForUpdate() *SelectBuilder {
+	b.forUpdate = true
+	return b
+}
+
+// From sets the table to select from.
+func (b *SelectBuilder) From(table string) *SelectBuilder {
+	b.table = table
+	return b
+}
+
+// GroupBy appends the group by columns.
+func (b *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
+	b.groupBy = append(b.groupBy, columns...)
+	return b
+}
+
+// Having appends the having conds, which are joined with and.
+func (b *SelectBuilder) Having(conds ...Cond) *SelectBuilder {
+	b.having = append(b.having, conds...)
+	return b
+}
+
+// Join appends an inner join of table on the cond, like Expr("o.user_id = u.id").
+func (b *SelectBuilder) Join(table string, on Cond) *SelectBuilder {
+	return b.join("join", table, on)
+}
+
+// LeftJoin appends a left join of table on the cond.
+func (b *SelectBuilder) LeftJoin(table string, on Cond) *SelectBuilder {
+	return b.join("left join", table, on)
+}
+
+// Limit sets the limit, negative means no limit.
+func (b *SelectBuilder) Limit(limit int64) *SelectBuilder {
+	b.limit = limit
+	return b
+}
+
+// Offset sets the offset, negative means no offset.
+// In MySQL, the max limit is used to select all the rows after the offset if no limit set.
+func (b *SelectBuilder) Offset(offset int64) *SelectBuilder {
+	b.offset = offset
+	return b
+}
+
+// OrderBy appends the order by clauses, like "id desc".
+func (b *SelectBuilder) OrderBy(orders ...string) *SelectBuilder {
+	b.orderBy = append(b.orderBy, orders...)
+	return b
+}
+
+// RightJoin appends a right join of table on the cond.
+func (b *SelectBuilder) RightJoin(table string, on Cond) *SelectBuilder {
+	return b.join("right join", table, on)
+}
+
+// Where appends the conds, which are joined with and, the nil conds are ignored.
+func (b *SelectBuilder) Where(conds ...Cond) *SelectBuilder {
+	b.where = append(b.where, conds...)
+	return b
+}
+
+func (b *SelectBuilder) join(kind, table string, on Cond) *SelectBuilder {
+	b.joins = append(b.joins, join{
+		kind:  kind,
+		table: table,
+		on:    on,
+	})
+	return b
+}
+
+// Build builds the insert statement and its args.
+func (b *InsertBuilder) Build() (string, []any, error) {
+	if len(b.table) == 0 {
+		return "", nil, ErrEmptyTable
+	}
+	if len(b.columns) == 0 || len(b.rows) == 0 {
+		return "", nil, ErrEmptyColumns
+	}
+	if len(b.returning) > 0 && b.dialect != Postgres {
+		return "", nil, fmt.Errorf("builder: returning is not supported by dialect %d", b.dialect)
+	}
+
+	w := newWriter(b.dialect)
+	if b.upsert && len(b.updates) == 0 && b.dialect == MySQL {
+		w.write("insert ignore into ")
+	} else {
+		w.write("insert into ")
+	}
+	w.write(b.table, " (", strings.Join(b.columns, ", "), ") values ")
+	for i, row := range b.rows {
+		if len(row) != len(b.columns) {
+			return "", nil, fmt.Errorf("%w: %d columns but %d values in row %d",
+				ErrArgsMismatch, len(b.columns), len(row), i)
+		}
+
+		if i > 0 {
+			w.write(", ")
+		}
+		w.write("(")
+		for j, val := range row {
+			if j > 0 {
+				w.write(", ")
+			}
+			w.arg(val)
+		}
+		w.write(")")
+	}
+
+	if b.upsert {
+		b.writeUpsert(w)
+	}
+	if len(b.returning) > 0 {
+		w.write(" returning ", strings.Join(b.returning, ", "))
+	}
+
+	return w.result()
+}
+
+// Columns sets the columns to insert.
+func (b *InsertBuilder) Columns(columns ...string) *InsertBuilder {
+	b.columns = columns
+	return b
+}
+
+// Returning sets the columns to return, only supported by Postgres.
+func (b *InsertBuilder) Returning(columns ...string) *InsertBuilder {
+	b.returning = columns
+	return b
+}
+
+// Upsert updates the given columns with the inserting values if the row conflicts.
+// The keys are the conflict columns, which are only used by Postgres.
+// The conflicting rows are ignored if no columns to update.
+func (b *InsertBuilder) Upsert(keys []string, updates ...string) *InsertBuilder {
+	b.upsert = true
+	b.keys = keys
+	b.updates = updates
+	return b
+}
+
+// Values appends a row of values, in the same order of the columns.
+func (b *InsertBuilder) Values(vals ...any) *InsertBuilder {
+	b.rows = append(b.rows, vals)
+	return b
+}
+
+func (b *InsertBuilder) writeUpsert(w *writer) {
+	if b.dialect != Postgres {
+		if len(b.updates) == 0 {
+			return
+		}
+
+		w.write(" on duplicate key update ")
+		for i, column := range b.updates {
+			if i > 0 {
+				w.write(", ")
+			}
+			w.write(column, " = values(", column, ")")
+		}
+		return
+	}
+
+	w.write(" on conflict ")
+	if len(b.keys) > 0 {
+		w.write("(", strings.Join(b.keys, ", "), ") ")
+	}
+	if len(b.updates) == 0 {
+		w.write("do nothing")
+		return
+	}
+
+	if len(b.keys) == 0 {
+		w.fail(fmt.Errorf("builder: conflict keys are required to upsert on %s", b.table))
+		return
+	}
+
+	w.write("do update set ")
+	for i, column := range b.updates {
+		if i > 0 {
+			w.write(", ")
+		}
+		w.write(column, " = excluded.", column)
+	}
+}
+
+// AllRows allows to update all the rows without conds.
+func (b *UpdateBuilder) AllRows() *UpdateBuilder {
+	b.all = true
+	return b
+}
+
+// Build builds the update statement and its args.
+func (b *UpdateBuilder) Build() (string, []any, error) {
+	if len(b.table) == 0 {
+		return "", nil, ErrEmptyTable
+	}
+	if len(b.sets) == 0 {
+		return "", nil, ErrEmptyColumns
+	}
+	if !b.all && len(nonEmpty(b.where)) == 0 {
+		return "", nil, ErrEmptyWhere
+	}
+
+	w := newWriter(b.dialect)
+	w.write("update ", b.table, " set ")
+	for i, set := range b.sets {
+		if i > 0 {
+			w.write(", ")
+		}
+		w.write(set.column, " = ")
+		if set.expr != nil {
+			set.expr.build(w)
+		} else {
+			w.arg(set.val)
+		}
+	}
+	w.where("where", b.where)
+
+	return w.result()
+}
+
+// Set sets column to val.
+func (b *UpdateBuilder) Set(column string, val any) *UpdateBuilder {
+	b.sets = append(b.sets, assignment{
+		column: column,
+		val:    val,
+	})
+	return b
+}
+
+// SetExpr sets column to the raw sql, like SetExpr("version", "version + ?", 1).
+func (b *UpdateBuilder) SetExpr(column, sql string, args ...any) *UpdateBuilder {
+	b.sets = append(b.sets, assignment{
+		column: column,
+		expr:   Expr(sql, args...),
+	})
+	return b
+}
+
+// Where appends the conds, which are joined with and, the nil conds are ignored.
+func (b *UpdateBuilder) Where(conds ...Cond) *UpdateBuilder {
+	b.where = append(b.where, conds...)
+	return b
+}
+
+// AllRows allows to delete all the rows without conds.
+func (b *DeleteBuilder) AllRows() *DeleteBuilder {
+	b.all = true
+	return b
+}
+
+// Build builds the delete statement and its args.
+func (b *DeleteBuilder) Build() (string, []any, error) {
+	if len(b.table) == 0 {
+		return "", nil, ErrEmptyTable
+	}
+	if !b.all && len(nonEmpty(b.where)) == 0 {
+		return "", nil, ErrEmptyWhere
+	}
+
+	w := newWriter(b.dialect)
+	w.write("delete from ", b.table)
+	w.where("where", b.where)
+
+	return w.result()
+}
+
+// Where appends the conds, which are joined with and, the nil conds are ignored.
+func (b *DeleteBuilder) Where(conds ...Cond) *DeleteBuilder {
+	b.where = append(b.where, conds...)
+	return b
+}
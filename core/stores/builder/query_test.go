@@ -0,0 +1,128 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuilder(t *testing.T) {
+	query, args, err := Select("u.id", "count(*)").From("users u").
+		LeftJoin("orders o", Expr("o.user_id = u.id and o.state = ?", 1)).
+		Where(Eq("u.type", "vip"), If(false, Eq("u.name", "x")), In("u.age", []int{18, 20})).
+		GroupBy("u.id").
+		Having(Gt("count(*)", 2)).
+		OrderBy("u.id desc").
+		Limit(10).
+		Offset(20).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select u.id, count(*) from users u left join orders o on o.user_id = u.id and o.state = ?"+
+		" where u.type = ? and u.age in (?, ?) group by u.id having count(*) > ? order by u.id desc limit 10 offset 20",
+		query)
+	assert.Equal(t, []any{1, "vip", 18, 20, 2}, args)
+
+	query, args, err = Postgres.Select().From("users").
+		Join("orders", Expr("orders.user_id = users.id")).
+		RightJoin("teams", nil).
+		Where(Eq("id", 1), Or(Eq("name", "a"), Expr("name = ?", "b"))).
+		ForUpdate().
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users join orders on orders.user_id = users.id right join teams"+
+		" where id = $1 and (name = $2 or (name = $3)) for update", query)
+	assert.Equal(t, []any{1, "a", "b"}, args)
+
+	query, args, err = Select().From("users").Where(If(false, Eq("id", 1))).Limit(0).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users limit 0", query)
+	assert.Empty(t, args)
+
+	// mysql requires limit with offset.
+	query, _, err = Select().From("users").Offset(10).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users limit 18446744073709551615 offset 10", query)
+	query, _, err = Postgres.Select().From("users").Offset(10).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users offset 10", query)
+
+	_, _, err = Select().Build()
+	assert.Equal(t, ErrEmptyTable, err)
+}
+
+func TestInsertBuilder(t *testing.T) {
+	query, args, err := Insert("users").Columns("id", "name").Values(1, "a").Values(2, "b").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "insert into users (id, name) values (?, ?), (?, ?)", query)
+	assert.Equal(t, []any{1, "a", 2, "b"}, args)
+
+	query, _, err = Insert("users").Columns("id", "name").Values(1, "a").
+		Upsert([]string{"id"}, "name").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "insert into users (id, name) values (?, ?) on duplicate key update name = values(name)", query)
+
+	query, _, err = Insert("users").Columns("id").Values(1).Upsert(nil).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "insert ignore into users (id) values (?)", query)
+
+	query, args, err = Postgres.Insert("users").Columns("id", "name").Values(1, "a").
+		Upsert([]string{"id"}, "name").Returning("id").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "insert into users (id, name) values ($1, $2)"+
+		" on conflict (id) do update set name = excluded.name returning id", query)
+	assert.Equal(t, []any{1, "a"}, args)
+
+	query, _, err = Postgres.Insert("users").Columns("id").Values(1).Upsert(nil).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "insert into users (id) values ($1) on conflict do nothing", query)
+
+	_, _, err = Postgres.Insert("users").Columns("id").Values(1).Upsert(nil, "id").Build()
+	assert.Error(t, err)
+	_, _, err = Insert("users").Columns("id").Values(1).Returning("id").Build()
+	assert.Error(t, err)
+	_, _, err = Insert("users").Columns("id", "name").Values(1).Build()
+	assert.ErrorIs(t, err, ErrArgsMismatch)
+	_, _, err = Insert("users").Build()
+	assert.Equal(t, ErrEmptyColumns, err)
+	_, _, err = Insert("").Build()
+	assert.Equal(t, ErrEmptyTable, err)
+}
+
+func TestUpdateBuilder(t *testing.T) {
+	query, args, err := Postgres.Update("users").Set("name", "a").SetExpr("version", "version + ?", 1).
+		Where(Eq("id", 2), Eq("version", 3)).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "update users set name = $1, version = version + $2 where id = $3 and version = $4", query)
+	assert.Equal(t, []any{"a", 1, 2, 3}, args)
+
+	_, _, err = Update("users").Build()
+	assert.Equal(t, ErrEmptyColumns, err)
+	_, _, err = Update("").Set("a", 1).Build()
+	assert.Equal(t, ErrEmptyTable, err)
+	// the empty conds don't update all the rows.
+	_, _, err = Update("users").Set("a", 1).Where(If(false, In("id", []int{1}))).Build()
+	assert.Equal(t, ErrEmptyWhere, err)
+	query, args, err = Update("users").Set("a", 1).AllRows().Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "update users set a = ?", query)
+	assert.Equal(t, []any{1}, args)
+}
+
+func TestDeleteBuilder(t *testing.T) {
+	query, args, err := Delete("users").Where(Lt("id", 10)).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "delete from users where id < ?", query)
+	assert.Equal(t, []any{10}, args)
+
+	_, _, err = Delete("").Build()
+	assert.Equal(t, ErrEmptyTable, err)
+	// the empty conds don't delete all the rows.
+	_, _, err = Delete("users").Build()
+	assert.Equal(t, ErrEmptyWhere, err)
+	_, _, err = Delete("users").Where(If(false, In("id", []int{1}))).Build()
+	assert.Equal(t, ErrEmptyWhere, err)
+	query, args, err = Delete("users").AllRows().Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "delete from users", query)
+	assert.Empty(t, args)
+}
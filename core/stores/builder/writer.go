@@ -0,0 +1,104 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrEmptyTable is an error that indicates the table is not set.
+	ErrEmptyTable = errors.New("builder: empty table")
+	// ErrEmptyColumns is an error that indicates no columns are set to insert or update.
+	ErrEmptyColumns = errors.New("builder: empty columns")
+	// ErrArgsMismatch is an error that indicates the args don't match the placeholders.
+	ErrArgsMismatch = errors.New("builder: args mismatch with placeholders")
+	// ErrEmptyWhere is an error that indicates no conds are set to update or delete,
+	// use AllRows to update or delete all the rows.
+	ErrEmptyWhere = errors.New("builder: empty where")
+)
+
+// writer writes the sql and collects the args, with the placeholders of the dialect.
+type writer struct {
+	dialect Dialect
+	buf     strings.Builder
+	args    []any
+	err     error
+}
+
+func newWriter(dialect Dialect) *writer {
+	return &writer{
+		dialect: dialect,
+	}
+}
+
+func (w *writer) arg(val any) {
+	w.args = append(w.args, val)
+	if w.dialect == Postgres {
+		w.buf.WriteByte('$')
+		w.buf.WriteString(strconv.Itoa(len(w.args)))
+	} else {
+		w.buf.WriteByte('?')
+	}
+}
+
+// expr writes the raw sql, the ? outside the quotes are replaced with the placeholders of args.
+func (w *writer) expr(sql string, args []any) {
+	var quote byte
+	var used int
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			w.buf.WriteByte(c)
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			w.buf.WriteByte(c)
+		case c == '?':
+			if used < len(args) {
+				w.arg(args[used])
+			}
+			used++
+		default:
+			w.buf.WriteByte(c)
+		}
+	}
+
+	if used != len(args) && w.err == nil {
+		w.err = fmt.Errorf("%w: %q has %d placeholders but %d args", ErrArgsMismatch, sql, used, len(args))
+	}
+}
+
+func (w *writer) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *writer) result() (string, []any, error) {
+	if w.err != nil {
+		return "", nil, w.err
+	}
+
+	return w.buf.String(), w.args, nil
+}
+
+func (w *writer) where(keyword string, conds []Cond) {
+	conds = nonEmpty(conds)
+	if len(conds) == 0 {
+		return
+	}
+
+	w.write(" ", keyword, " ")
+	andCond(conds).build(w)
+}
+
+func (w *writer) write(ss ...string) {
+	for _, s := range ss {
+		w.buf.WriteString(s)
+	}
+}
@@ -62,13 +62,16 @@ func New(c ClusterConf, barrier syncx.SingleFlight, st *Stat, errNotFound error,
 		log.Fatal("no cache nodes")
 	}
 
+	o := newOptions(opts...)
+	// the in-process cache is shared by the nodes.
+	store := newLocalStore(o, st)
 	if len(c) == 1 {
-		return NewNode(redis.MustNewRedis(c[0].RedisConf), barrier, st, errNotFound, opts...)
+		return newNode(redis.MustNewRedis(c[0].RedisConf), barrier, st, errNotFound, o, store)
 	}
 
 	dispatcher := hash.NewConsistentHash()
 	for _, node := range c {
-		cn := NewNode(redis.MustNewRedis(node.RedisConf), barrier, st, errNotFound, opts...)
+		cn := newNode(redis.MustNewRedis(node.RedisConf), barrier, st, errNotFound, o, store)
 		dispatcher.AddWithWeight(cn, node.Weight)
 	}
 
@@ -0,0 +1,264 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/collection"
+	"github.com/r27153733/fastgozero/core/jsonx"
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/stores/redis"
+)
+
+const invalidationChannel = "cache:invalidation"
+
+type (
+	// localStore is the in-process cache, which might be shared by the nodes of a cluster.
+	localStore struct {
+		items *collection.Cache
+		stat  *Stat
+		// generation changes on invalidations, to avoid caching the values
+		// that were loaded from redis before the invalidations.
+		generation atomic.Uint64
+		// the items cached before the flushed generation are stale.
+		flushed  atomic.Uint64
+		watching map[string]struct{}
+		lock     sync.Mutex
+	}
+
+	localItem struct {
+		data       []byte
+		generation uint64
+	}
+
+	// localNode is a cacheNode with the in-process cache in front of it.
+	localNode struct {
+		cacheNode
+		store *localStore
+	}
+)
+
+func newLocalStore(o Options, st *Stat) *localStore {
+	if o.LocalSize <= 0 {
+		return nil
+	}
+
+	items, err := collection.NewCache(o.LocalExpiry, collection.WithLimit(o.LocalSize),
+		collection.WithName(st.name+"-local"))
+	logx.Must(err)
+
+	return &localStore{
+		items:    items,
+		stat:     st,
+		watching: make(map[string]struct{}),
+	}
+}
+
+func (s *localStore) get(key string, val any) bool {
+	v, ok := s.items.Get(key)
+	if !ok {
+		s.stat.IncrementLocalMiss()
+		return false
+	}
+
+	item := v.(localItem)
+	if item.generation < s.flushed.Load() {
+		s.items.Del(key)
+		s.stat.IncrementLocalMiss()
+		return false
+	}
+
+	if err := jsonx.Unmarshal(item.data, val); err != nil {
+		s.items.Del(key)
+		s.stat.IncrementLocalMiss()
+		return false
+	}
+
+	s.stat.IncrementLocalHit()
+	return true
+}
+
+// flush drops all the cached items, which is used if invalidations might be missed.
+func (s *localStore) flush() {
+	s.flushed.Store(s.generation.Add(1))
+}
+
+func (s *localStore) invalidate(keys ...string) {
+	s.generation.Add(1)
+	for _, key := range keys {
+		s.items.Del(key)
+	}
+}
+
+// set caches val if there are no invalidations since generation.
+func (s *localStore) set(key string, val any, generation uint64) {
+	data, err := jsonx.Marshal(val)
+	if err != nil {
+		return
+	}
+
+	if s.generation.Load() == generation {
+		s.items.Set(key, localItem{
+			data:       data,
+			generation: generation,
+		})
+	}
+}
+
+// watch subscribes the invalidations on rds, once for each redis.
+func (s *localStore) watch(rds *redis.Redis) {
+	s.lock.Lock()
+	if _, ok := s.watching[rds.Addr]; ok {
+		s.lock.Unlock()
+		return
+	}
+	s.watching[rds.Addr] = struct{}{}
+	s.lock.Unlock()
+
+	// the invalidations published while disconnected are lost, so flush on resubscribing.
+	redis.NewSubscriber(rds, []string{invalidationChannel}, func(msg *redis.Message) {
+		var keys []string
+		if err := jsonx.UnmarshalFromString(msg.Payload, &keys); err != nil {
+			logx.Errorf("invalid cache invalidation: %q, error: %v", msg.Payload, err)
+			return
+		}
+
+		s.invalidate(keys...)
+	}, redis.WithResubscribeListener(s.flush))
+}
+
+func newLocalNode(node cacheNode, store *localStore) localNode {
+	store.watch(node.rds)
+	return localNode{
+		cacheNode: node,
+		store:     store,
+	}
+}
+
+// Del deletes cached values with keys.
+func (c localNode) Del(keys ...string) error {
+	return c.DelCtx(context.Background(), keys...)
+}
+
+// DelCtx deletes cached values with keys, and invalidates them on all the instances.
+func (c localNode) DelCtx(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	err := c.cacheNode.DelCtx(ctx, keys...)
+	c.invalidate(ctx, keys...)
+	return err
+}
+
+// Get gets the cache with key and fills into v.
+func (c localNode) Get(key string, val any) error {
+	return c.GetCtx(context.Background(), key, val)
+}
+
+// GetCtx gets the cache with key and fills into v.
+func (c localNode) GetCtx(ctx context.Context, key string, val any) error {
+	if c.store.get(key, val) {
+		return nil
+	}
+
+	generation := c.store.generation.Load()
+	if err := c.cacheNode.GetCtx(ctx, key, val); err != nil {
+		return err
+	}
+
+	c.store.set(key, val, generation)
+	return nil
+}
+
+// Set sets the cache with key and v, using c.expiry.
+func (c localNode) Set(key string, val any) error {
+	return c.SetCtx(context.Background(), key, val)
+}
+
+// SetCtx sets the cache with key and v, using c.expiry.
+func (c localNode) SetCtx(ctx context.Context, key string, val any) error {
+	return c.SetWithExpireCtx(ctx, key, val, c.aroundDuration(c.expiry))
+}
+
+// SetWithExpire sets the cache with key and v, using given expire.
+func (c localNode) SetWithExpire(key string, val any, expire time.Duration) error {
+	return c.SetWithExpireCtx(context.Background(), key, val, expire)
+}
+
+// SetWithExpireCtx sets the cache with key and v, using given expire.
+// The other instances are not invalidated, use Del on changes instead.
+func (c localNode) SetWithExpireCtx(ctx context.Context, key string, val any,
+	expire time.Duration) error {
+	if err := c.cacheNode.SetWithExpireCtx(ctx, key, val, expire); err != nil {
+		c.store.invalidate(key)
+		return err
+	}
+
+	c.store.set(key, val, c.store.generation.Load())
+	return nil
+}
+
+// Take takes the result from cache first, if not found,
+// query from DB and set cache using c.expiry, then return the result.
+func (c localNode) Take(val any, key string, query func(val any) error) error {
+	return c.TakeCtx(context.Background(), val, key, query)
+}
+
+// TakeCtx takes the result from cache first, if not found,
+// query from DB and set cache using c.expiry, then return the result.
+func (c localNode) TakeCtx(ctx context.Context, val any, key string,
+	query func(val any) error) error {
+	if c.store.get(key, val) {
+		return nil
+	}
+
+	generation := c.store.generation.Load()
+	if err := c.cacheNode.TakeCtx(ctx, val, key, query); err != nil {
+		return err
+	}
+
+	c.store.set(key, val, generation)
+	return nil
+}
+
+// TakeWithExpire takes the result from cache first, if not found,
+// query from DB and set cache using given expire, then return the result.
+func (c localNode) TakeWithExpire(val any, key string, query func(val any,
+	expire time.Duration) error) error {
+	return c.TakeWithExpireCtx(context.Background(), val, key, query)
+}
+
+// TakeWithExpireCtx takes the result from cache first, if not found,
+// query from DB and set cache using given expire, then return the result.
+func (c localNode) TakeWithExpireCtx(ctx context.Context, val any, key string,
+	query func(val any, expire time.Duration) error) error {
+	if c.store.get(key, val) {
+		return nil
+	}
+
+	generation := c.store.generation.Load()
+	if err := c.cacheNode.TakeWithExpireCtx(ctx, val, key, query); err != nil {
+		return err
+	}
+
+	c.store.set(key, val, generation)
+	return nil
+}
+
+func (c localNode) invalidate(ctx context.Context, keys ...string) {
+	c.store.invalidate(keys...)
+
+	payload, err := jsonx.MarshalToString(keys)
+	if err != nil {
+		logx.WithContext(ctx).Error(err)
+		return
+	}
+
+	if _, err = c.rds.PublishCtx(ctx, invalidationChannel, payload); err != nil {
+		logx.WithContext(ctx).Errorf("failed to broadcast cache invalidation of keys: %q, error: %v",
+			formatKeys(keys), err)
+	}
+}
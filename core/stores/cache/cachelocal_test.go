@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/r27153733/fastgozero/core/stores/redis"
+	"github.com/r27153733/fastgozero/core/syncx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalNode_Take(t *testing.T) {
+	r := miniredis.RunT(t)
+	st := NewStat("local")
+	c := NewNode(redis.New(r.Addr()), syncx.NewSingleFlight(), st, errTestNotFound,
+		WithLocalCache(10, time.Minute))
+	assert.IsType(t, localNode{}, c)
+
+	var queries int32
+	query := func(v any) error {
+		atomic.AddInt32(&queries, 1)
+		*v.(*string) = "value"
+		return nil
+	}
+	var val string
+	assert.NoError(t, c.Take(&val, "key", query))
+	assert.Equal(t, "value", val)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&st.LocalMiss))
+
+	// served by the local cache, even if removed from redis directly.
+	r.Del("key")
+	val = ""
+	assert.NoError(t, c.Take(&val, "key", query))
+	assert.Equal(t, "value", val)
+	val = ""
+	assert.NoError(t, c.Get("key", &val))
+	assert.Equal(t, "value", val)
+	val = ""
+	assert.NoError(t, c.TakeWithExpire(&val, "key", func(v any, _ time.Duration) error {
+		return query(v)
+	}))
+	assert.Equal(t, "value", val)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&queries))
+	assert.Equal(t, uint64(3), atomic.LoadUint64(&st.LocalHit))
+
+	assert.NoError(t, c.Del("key"))
+	assert.True(t, c.IsNotFound(c.Get("key", &val)))
+	assert.NoError(t, c.TakeWithExpire(&val, "key", func(v any, _ time.Duration) error {
+		return query(v)
+	}))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&queries))
+}
+
+func TestLocalNode_Invalidation(t *testing.T) {
+	r := miniredis.RunT(t)
+	rds := redis.New(r.Addr())
+	c1 := NewNode(rds, syncx.NewSingleFlight(), NewStat("c1"), errTestNotFound,
+		WithLocalCache(10, time.Minute))
+	c2 := NewNode(rds, syncx.NewSingleFlight(), NewStat("c2"), errTestNotFound,
+		WithLocalCache(10, time.Minute))
+	assert.Eventually(t, func() bool {
+		return r.PubSubNumSub(invalidationChannel)[invalidationChannel] == 2
+	}, time.Second, time.Millisecond*10)
+
+	assert.NoError(t, c1.Set("key", "v1"))
+	var val string
+	assert.NoError(t, c2.Get("key", &val))
+	assert.Equal(t, "v1", val)
+
+	// the sets are not broadcast.
+	assert.NoError(t, c1.Set("key", "v2"))
+	assert.NoError(t, c1.Get("key", &val))
+	assert.Equal(t, "v2", val)
+	assert.NoError(t, c2.Get("key", &val))
+	assert.Equal(t, "v1", val)
+
+	assert.NoError(t, c1.Del("key"))
+	assert.Eventually(t, func() bool {
+		var val string
+		return c2.IsNotFound(c2.Get("key", &val))
+	}, time.Second, time.Millisecond*10)
+	assert.NoError(t, c1.Del())
+
+	// the invalid messages are ignored.
+	_, err := rds.Publish(invalidationChannel, "bad")
+	assert.NoError(t, err)
+}
+
+func TestLocalStore(t *testing.T) {
+	st := NewStat("store")
+	assert.Nil(t, newLocalStore(newOptions(), st))
+	store := newLocalStore(newOptions(WithLocalCache(10, time.Minute)), st)
+
+	// the values loaded before the invalidations are not cached.
+	generation := store.generation.Load()
+	store.invalidate("other")
+	store.set("key", "value", generation)
+	var val string
+	assert.False(t, store.get("key", &val))
+
+	store.set("key", "value", store.generation.Load())
+	assert.True(t, store.get("key", &val))
+	assert.Equal(t, "value", val)
+
+	// the values cached before flushing are dropped.
+	store.flush()
+	assert.False(t, store.get("key", &val))
+	store.set("key", "value", store.generation.Load())
+	assert.True(t, store.get("key", &val))
+
+	// the values that can't be unmarshalled are dropped.
+	var num int
+	assert.False(t, store.get("key", &num))
+	assert.False(t, store.get("key", &val))
+}
+
+func TestCache_LocalShared(t *testing.T) {
+	r1 := miniredis.RunT(t)
+	r2 := miniredis.RunT(t)
+	conf := ClusterConf{
+		{
+			RedisConf: redis.RedisConf{
+				Host: r1.Addr(),
+				Type: redis.NodeType,
+			},
+			Weight: 100,
+		},
+		{
+			RedisConf: redis.RedisConf{
+				Host: r2.Addr(),
+				Type: redis.NodeType,
+			},
+			Weight: 100,
+		},
+	}
+	c := New(conf, syncx.NewSingleFlight(), NewStat("shared"), errTestNotFound,
+		WithLocalCache(100, time.Minute))
+	cluster := c.(cacheCluster)
+	var stores []*localStore
+	for i := 0; i < 100; i++ {
+		node, ok := cluster.dispatcher.Get(fmt.Sprintf("key/%d", i))
+		assert.True(t, ok)
+		stores = append(stores, node.(localNode).store)
+	}
+	for _, store := range stores {
+		assert.Equal(t, stores[0], store)
+	}
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, c.Set(fmt.Sprintf("key/%d", i), i))
+	}
+	for i := 0; i < 10; i++ {
+		var val int
+		assert.NoError(t, c.Get(fmt.Sprintf("key/%d", i), &val))
+		assert.Equal(t, i, val)
+	}
+}
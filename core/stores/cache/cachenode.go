@@ -48,7 +48,12 @@ type cacheNode struct {
 func NewNode(rds *redis.Redis, barrier syncx.SingleFlight, st *Stat,
 	errNotFound error, opts ...Option) Cache {
 	o := newOptions(opts...)
-	return cacheNode{
+	return newNode(rds, barrier, st, errNotFound, o, newLocalStore(o, st))
+}
+
+func newNode(rds *redis.Redis, barrier syncx.SingleFlight, st *Stat, errNotFound error,
+	o Options, store *localStore) Cache {
+	node := cacheNode{
 		rds:            rds,
 		expiry:         o.Expiry,
 		notFoundExpiry: o.NotFoundExpiry,
@@ -59,6 +64,11 @@ func NewNode(rds *redis.Redis, barrier syncx.SingleFlight, st *Stat,
 		stat:           st,
 		errNotFound:    errNotFound,
 	}
+	if store == nil {
+		return node
+	}
+
+	return newLocalNode(node, store)
 }
 
 // Del deletes cached values with keys.
@@ -5,6 +5,7 @@ import "time"
 const (
 	defaultExpiry         = time.Hour * 24 * 7
 	defaultNotFoundExpiry = time.Minute
+	defaultLocalExpiry    = time.Second * 5
 )
 
 type (
@@ -12,6 +13,9 @@ type (
 	Options struct {
 		Expiry         time.Duration
 		NotFoundExpiry time.Duration
+		// LocalSize is the max items of the in-process cache, 0 means disabled.
+		LocalSize   int
+		LocalExpiry time.Duration
 	}
 
 	// Option defines the method to customize an Options.
@@ -30,6 +34,9 @@ func newOptions(opts ...Option) Options {
 	if o.NotFoundExpiry <= 0 {
 		o.NotFoundExpiry = defaultNotFoundExpiry
 	}
+	if o.LocalExpiry <= 0 {
+		o.LocalExpiry = defaultLocalExpiry
+	}
 
 	return o
 }
@@ -47,3 +54,14 @@ func WithNotFoundExpiry(expiry time.Duration) Option {
 		o.NotFoundExpiry = expiry
 	}
 }
+
+// WithLocalCache returns a func to customize an Options with an in-process cache
+// in front of redis, which keeps at most size items for the given expiry.
+// The deletions are broadcast over redis pub/sub to invalidate the other instances,
+// the expiry bounds the staleness if the broadcasts are lost or on sets.
+func WithLocalCache(size int, expiry time.Duration) Option {
+	return func(o *Options) {
+		o.LocalSize = size
+		o.LocalExpiry = expiry
+	}
+}
@@ -25,4 +25,12 @@ func TestCacheOptions(t *testing.T) {
 		assert.Equal(t, defaultExpiry, o.Expiry)
 		assert.Equal(t, time.Second, o.NotFoundExpiry)
 	})
+
+	t.Run("with local cache", func(t *testing.T) {
+		o := newOptions(WithLocalCache(100, time.Second))
+		assert.Equal(t, 100, o.LocalSize)
+		assert.Equal(t, time.Second, o.LocalExpiry)
+		o = newOptions(WithLocalCache(100, 0))
+		assert.Equal(t, defaultLocalExpiry, o.LocalExpiry)
+	})
 }
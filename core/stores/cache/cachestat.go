@@ -5,10 +5,26 @@ import (
 	"time"
 
 	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/metric"
 	"github.com/r27153733/fastgozero/core/timex"
 )
 
-const statInterval = time.Minute
+const (
+	statInterval = time.Minute
+
+	localLevel  = "l1"
+	remoteLevel = "l2"
+	resultHit   = "hit"
+	resultMiss  = "miss"
+)
+
+var metricRequests = metric.NewCounterVec(&metric.CounterVecOpts{
+	Namespace: "cache",
+	Subsystem: "requests",
+	Name:      "total",
+	Help:      "cache requests count, l1 is the in-process cache, l2 is redis.",
+	Labels:    []string{"name", "level", "result"},
+})
 
 // A Stat is used to stat the cache.
 type Stat struct {
@@ -19,6 +35,10 @@ type Stat struct {
 	Hit     uint64
 	Miss    uint64
 	DbFails uint64
+	// LocalHit and LocalMiss are the stats of the in-process cache,
+	// the local misses go to redis, which are counted in Total.
+	LocalHit  uint64
+	LocalMiss uint64
 }
 
 // NewStat returns a Stat.
@@ -45,11 +65,25 @@ func (s *Stat) IncrementTotal() {
 // IncrementHit increments the hit count.
 func (s *Stat) IncrementHit() {
 	atomic.AddUint64(&s.Hit, 1)
+	metricRequests.Inc(s.name, remoteLevel, resultHit)
 }
 
 // IncrementMiss increments the miss count.
 func (s *Stat) IncrementMiss() {
 	atomic.AddUint64(&s.Miss, 1)
+	metricRequests.Inc(s.name, remoteLevel, resultMiss)
+}
+
+// IncrementLocalHit increments the local hit count.
+func (s *Stat) IncrementLocalHit() {
+	atomic.AddUint64(&s.LocalHit, 1)
+	metricRequests.Inc(s.name, localLevel, resultHit)
+}
+
+// IncrementLocalMiss increments the local miss count.
+func (s *Stat) IncrementLocalMiss() {
+	atomic.AddUint64(&s.LocalMiss, 1)
+	metricRequests.Inc(s.name, localLevel, resultMiss)
 }
 
 // IncrementDbFails increments the db fail count.
@@ -59,6 +93,8 @@ func (s *Stat) IncrementDbFails() {
 
 func (s *Stat) statLoop(ticker timex.Ticker) {
 	for range ticker.Chan() {
+		s.logLocal()
+
 		total := atomic.SwapUint64(&s.Total, 0)
 		if total == 0 {
 			continue
@@ -72,3 +108,16 @@ func (s *Stat) statLoop(ticker timex.Ticker) {
 			s.name, total, percent, hit, miss, dbf)
 	}
 }
+
+func (s *Stat) logLocal() {
+	hit := atomic.SwapUint64(&s.LocalHit, 0)
+	miss := atomic.SwapUint64(&s.LocalMiss, 0)
+	total := hit + miss
+	if total == 0 {
+		return
+	}
+
+	percent := 100 * float32(hit) / float32(total)
+	logx.Statf("dbcache(%s) - l1 qpm: %d, l1 hit_ratio: %.1f%%, l1 hit: %d, l1 miss: %d",
+		s.name, total, percent, hit, miss)
+}
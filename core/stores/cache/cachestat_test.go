@@ -19,6 +19,8 @@ func TestCacheStat_statLoop(t *testing.T) {
 	t.Run("stat loop total not 0", func(t *testing.T) {
 		var stat Stat
 		stat.IncrementTotal()
+		stat.IncrementLocalHit()
+		stat.IncrementLocalMiss()
 		ticker := timex.NewFakeTicker()
 		go stat.statLoop(ticker)
 		ticker.Tick()
@@ -0,0 +1,141 @@
+// Package outbox implements the transactional outbox, the messages are written into
+// an outbox table in the same transaction with the business data, and relayed to
+// the message queues by a Relay, so that no messages are lost if the process crashes
+// between writing the data and publishing the messages.
+//
+// The outbox table is created as below in MySQL:
+//
+//	create table outbox (
+//	  id bigint unsigned not null auto_increment,
+//	  topic varchar(255) not null,
+//	  msg_key varchar(255) not null default '',
+//	  payload mediumblob not null,
+//	  status tinyint not null default 0,
+//	  attempts int not null default 0,
+//	  last_error varchar(1024) not null default '',
+//	  created_at timestamp not null default current_timestamp,
+//	  primary key (id),
+//	  key idx_status_id (status, id)
+//	);
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/r27153733/fastgozero/core/stores/builder"
+	"github.com/r27153733/fastgozero/core/stores/sqlx"
+)
+
+const (
+	statusPending = 0
+	statusDead    = 1
+
+	maxErrorLen = 1024
+)
+
+// ErrEmptyTable is an error that indicates no outbox table is set.
+var ErrEmptyTable = errors.New("empty outbox table")
+
+var messageColumns = []string{"id", "topic", "msg_key", "payload", "attempts"}
+
+type (
+	// A Message is a message in the outbox.
+	Message struct {
+		// Id is the auto increment id, which is set by the outbox.
+		Id    int64  `db:"id"`
+		Topic string `db:"topic"`
+		// Key is used to route the message, like the partition key of kafka, optional.
+		Key     string `db:"msg_key"`
+		Payload string `db:"payload"`
+		// Attempts is the number of the failed publishing attempts.
+		Attempts int `db:"attempts"`
+	}
+
+	// An Outbox writes the messages into the outbox table.
+	Outbox struct {
+		table string
+	}
+)
+
+// NewOutbox returns an Outbox on the given table.
+func NewOutbox(table string) *Outbox {
+	return &Outbox{
+		table: table,
+	}
+}
+
+// AddCtx writes msgs into the outbox with session, which should be the session of
+// sqlx.TransactCtx, to commit or rollback the messages with the business data.
+//
+//	err := conn.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+//		if _, err := session.ExecCtx(ctx, "insert into orders ...", ...); err != nil {
+//			return err
+//		}
+//
+//		return ob.AddCtx(ctx, session, &outbox.Message{Topic: "order.created", Payload: payload})
+//	})
+func (o *Outbox) AddCtx(ctx context.Context, session sqlx.Session, msgs ...*Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	ib := builder.Insert(o.table).Columns("topic", "msg_key", "payload")
+	for _, msg := range msgs {
+		ib.Values(msg.Topic, msg.Key, msg.Payload)
+	}
+
+	_, err := sqlx.ExecBuilderCtx(ctx, session, ib)
+	return err
+}
+
+// RedriveCtx moves the dead messages with the given ids back to pending, to be relayed again.
+// All the dead messages are moved if no ids given.
+func (o *Outbox) RedriveCtx(ctx context.Context, session sqlx.Session, ids ...int64) (int64, error) {
+	ub := builder.Update(o.table).Set("status", statusPending).Set("attempts", 0).
+		Set("last_error", "").Where(builder.Eq("status", statusDead))
+	if len(ids) > 0 {
+		ub.Where(builder.In("id", ids))
+	}
+
+	result, err := sqlx.ExecBuilderCtx(ctx, session, ub)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (o *Outbox) deleteCtx(ctx context.Context, session sqlx.Session, ids []int64) error {
+	_, err := sqlx.ExecBuilderCtx(ctx, session, builder.Delete(o.table).Where(builder.In("id", ids)))
+	return err
+}
+
+func (o *Outbox) failCtx(ctx context.Context, session sqlx.Session, msg *Message, dead bool,
+	cause error) error {
+	status := statusPending
+	if dead {
+		status = statusDead
+	}
+
+	errMsg := cause.Error()
+	if len(errMsg) > maxErrorLen {
+		errMsg = strings.ToValidUTF8(errMsg[:maxErrorLen], "")
+	}
+
+	_, err := sqlx.ExecBuilderCtx(ctx, session, builder.Update(o.table).Set("status", status).
+		Set("attempts", msg.Attempts).Set("last_error", errMsg).Where(builder.Eq("id", msg.Id)))
+	return err
+}
+
+func (o *Outbox) pendingCtx(ctx context.Context, session sqlx.Session, limit int64) ([]*Message, error) {
+	var msgs []*Message
+	sb := builder.Select(messageColumns...).From(o.table).Where(builder.Eq("status", statusPending)).
+		OrderBy("id").Limit(limit)
+	if err := sqlx.QueryRowsBuilderCtx(ctx, session, &msgs, sb); err != nil {
+		return nil, err
+	}
+
+	return msgs, nil
+}
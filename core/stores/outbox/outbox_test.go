@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/r27153733/fastgozero/core/queue"
+	"github.com/r27153733/fastgozero/core/stores/dbtest"
+	"github.com/r27153733/fastgozero/core/stores/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutbox_AddCtx(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		conn := sqlx.NewSqlConnFromDB(db)
+		ob := NewOutbox("outbox")
+
+		mock.ExpectBegin()
+		mock.ExpectExec("insert into orders").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("insert into outbox \\(topic, msg_key, payload\\) values \\(\\?, \\?, \\?\\), \\(\\?, \\?, \\?\\)").
+			WithArgs("order", "1", "created", "order", "1", "paid").
+			WillReturnResult(sqlmock.NewResult(2, 2))
+		mock.ExpectCommit()
+		assert.NoError(t, conn.TransactCtx(context.Background(),
+			func(ctx context.Context, session sqlx.Session) error {
+				if _, err := session.ExecCtx(ctx, "insert into orders (id) values (1)"); err != nil {
+					return err
+				}
+
+				return ob.AddCtx(ctx, session, &Message{Topic: "order", Key: "1", Payload: "created"},
+					&Message{Topic: "order", Key: "1", Payload: "paid"})
+			}))
+
+		// the messages are rolled back with the business data.
+		mock.ExpectBegin()
+		mock.ExpectExec("insert into outbox").WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectRollback()
+		assert.Error(t, conn.TransactCtx(context.Background(),
+			func(ctx context.Context, session sqlx.Session) error {
+				if err := ob.AddCtx(ctx, session, &Message{Topic: "order", Payload: "created"}); err != nil {
+					return err
+				}
+
+				return errors.New("any")
+			}))
+
+		assert.NoError(t, ob.AddCtx(context.Background(), conn))
+	})
+}
+
+func TestOutbox_RedriveCtx(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		conn := sqlx.NewSqlConnFromDB(db)
+		ob := NewOutbox("outbox")
+
+		mock.ExpectExec("update outbox set status = \\?, attempts = \\?, last_error = \\? where status = \\?$").
+			WithArgs(statusPending, 0, "", statusDead).WillReturnResult(sqlmock.NewResult(0, 3))
+		n, err := ob.RedriveCtx(context.Background(), conn)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+
+		mock.ExpectExec("update outbox set .* where status = \\? and id in \\(\\?, \\?\\)").
+			WithArgs(statusPending, 0, "", statusDead, int64(1), int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		n, err = ob.RedriveCtx(context.Background(), conn, 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+
+		mock.ExpectExec("update outbox").WillReturnError(errors.New("any"))
+		_, err = ob.RedriveCtx(context.Background(), conn)
+		assert.Error(t, err)
+	})
+}
+
+func TestOutbox_FailCtx(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		conn := sqlx.NewSqlConnFromDB(db)
+		ob := NewOutbox("outbox")
+
+		mock.ExpectExec("update outbox set status = \\?, attempts = \\?, last_error = \\? where id = \\?").
+			WithArgs(statusDead, 3, strings.Repeat("a", maxErrorLen), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, ob.failCtx(context.Background(), conn, &Message{Id: 1, Attempts: 3}, true,
+			errors.New(strings.Repeat("a", maxErrorLen+1))))
+	})
+}
+
+func TestPusherPublisher(t *testing.T) {
+	var pushed []string
+	publisher := NewPusherPublisher(mockedPusher{
+		push: func(msg string) error {
+			pushed = append(pushed, msg)
+			return nil
+		},
+	})
+	assert.NoError(t, publisher.Publish(context.Background(), &Message{Payload: "a"}))
+	assert.Equal(t, []string{"a"}, pushed)
+}
+
+type mockedPusher struct {
+	push func(string) error
+}
+
+var _ queue.Pusher = mockedPusher{}
+
+func (p mockedPusher) Name() string {
+	return "mocked"
+}
+
+func (p mockedPusher) Push(msg string) error {
+	return p.push(msg)
+}
@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/r27153733/fastgozero/core/queue"
+)
+
+type (
+	// A Publisher publishes the messages relayed from the outbox.
+	// The message is retried if an error returned, so Publish should be idempotent,
+	// or the consumers should handle the duplicate messages.
+	Publisher interface {
+		Publish(ctx context.Context, msg *Message) error
+	}
+
+	// PublisherFunc is an adapter to allow the use of ordinary functions as Publisher.
+	PublisherFunc func(ctx context.Context, msg *Message) error
+
+	pusherPublisher struct {
+		pusher queue.Pusher
+	}
+)
+
+// Publish calls fn(ctx, msg).
+func (fn PublisherFunc) Publish(ctx context.Context, msg *Message) error {
+	return fn(ctx, msg)
+}
+
+// NewPusherPublisher returns a Publisher that pushes the payloads of the messages with pusher.
+func NewPusherPublisher(pusher queue.Pusher) Publisher {
+	return pusherPublisher{
+		pusher: pusher,
+	}
+}
+
+func (p pusherPublisher) Publish(_ context.Context, msg *Message) error {
+	return p.pusher.Push(msg.Payload)
+}
@@ -0,0 +1,297 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/proc"
+	"github.com/r27153733/fastgozero/core/stores/redis"
+	"github.com/r27153733/fastgozero/core/stores/sqlx"
+	"github.com/r27153733/fastgozero/core/syncx"
+)
+
+const (
+	relayLockPrefix       = "outbox:relay:"
+	defaultRelayInterval  = time.Second
+	defaultRetryDelay     = time.Second
+	defaultMaxRetryDelay  = time.Minute
+	defaultPublishTimeout = 5 * time.Second
+)
+
+type (
+	// A RelayConf is the config of a Relay.
+	RelayConf struct {
+		Table string
+		// LockKey is the redis lock to elect the leader, outbox:relay:<Table> by default.
+		LockKey   string `json:",optional"`
+		BatchSize int64  `json:",default=100,range=[1:]"`
+		// Interval is the interval to poll the outbox table if no more pending messages.
+		Interval time.Duration `json:",default=1s"`
+		// MaxAttempts is the max publishing attempts of a message, it's dead after that.
+		MaxAttempts int `json:",default=10,range=[1:]"`
+		// RetryDelay is the delay of the first retry, doubled on each retry up to MaxRetryDelay.
+		RetryDelay     time.Duration `json:",default=1s"`
+		MaxRetryDelay  time.Duration `json:",default=1m"`
+		PublishTimeout time.Duration `json:",default=5s"`
+	}
+
+	// DeadLetterHandler handles the dead message with the error of its last attempt.
+	DeadLetterHandler func(ctx context.Context, msg *Message, err error)
+
+	// RelayOption defines the method to customize a Relay.
+	RelayOption func(r *Relay)
+
+	// A Relay publishes the pending messages in the outbox in order, and deletes them after published.
+	// Only the leader, which holds the redis lock, relays the messages, the others stand by.
+	// A failed message is retried with backoff before the following messages,
+	// and it's marked dead after MaxAttempts, then the following messages go on.
+	// The messages are delivered at least once, they might be published again
+	// if the process crashes after publishing, or the leader changes on network partitions.
+	Relay struct {
+		conn       sqlx.SqlConn
+		outbox     *Outbox
+		lock       *redis.RedisLock
+		c          RelayConf
+		publisher  Publisher
+		deadLetter DeadLetterHandler
+		ctx        context.Context
+		cancel     context.CancelFunc
+		started    *syncx.AtomicBool
+		stopOnce   sync.Once
+		done       chan struct{}
+	}
+)
+
+// Validate validates the RelayConf.
+func (c RelayConf) Validate() error {
+	if len(c.Table) == 0 {
+		return ErrEmptyTable
+	}
+
+	return nil
+}
+
+// MustNewRelay returns a Relay, exits on errors.
+func MustNewRelay(conn sqlx.SqlConn, rds *redis.Redis, c RelayConf, publisher Publisher,
+	opts ...RelayOption) *Relay {
+	r, err := NewRelay(conn, rds, c, publisher, opts...)
+	logx.Must(err)
+	return r
+}
+
+// NewRelay returns a Relay that relays the messages in the outbox of conn with publisher,
+// the leader is elected with rds.
+func NewRelay(conn sqlx.SqlConn, rds *redis.Redis, c RelayConf, publisher Publisher,
+	opts ...RelayOption) (*Relay, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(c.LockKey) == 0 {
+		c.LockKey = relayLockPrefix + c.Table
+	}
+	c.BatchSize = max(c.BatchSize, 1)
+	c.MaxAttempts = max(c.MaxAttempts, 1)
+	if c.Interval <= 0 {
+		c.Interval = defaultRelayInterval
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = defaultRetryDelay
+	}
+	if c.MaxRetryDelay <= 0 {
+		c.MaxRetryDelay = defaultMaxRetryDelay
+	}
+	if c.PublishTimeout <= 0 {
+		c.PublishTimeout = defaultPublishTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Relay{
+		conn:      conn,
+		outbox:    NewOutbox(c.Table),
+		lock:      redis.NewRedisLock(rds, c.LockKey, redis.WithWatchdog()),
+		c:         c,
+		publisher: publisher,
+		ctx:       ctx,
+		cancel:    cancel,
+		started:   syncx.NewAtomicBool(),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// WithDeadLetterHandler sets the handler of the dead messages,
+// like forwarding them to a dead letter queue.
+func WithDeadLetterHandler(handler DeadLetterHandler) RelayOption {
+	return func(r *Relay) {
+		r.deadLetter = handler
+	}
+}
+
+// Start starts relaying, it blocks until stopped, either by Stop or on process shutdown.
+func (r *Relay) Start() {
+	if !r.started.CompareAndSwap(false, true) {
+		return
+	}
+	defer close(r.done)
+
+	proc.AddShutdownListener(r.Stop)
+	defer r.resign()
+
+	for r.ctx.Err() == nil {
+		r.wait(r.relay())
+	}
+}
+
+// Stop stops relaying, and waits for the relaying message to finish.
+func (r *Relay) Stop() {
+	r.stopOnce.Do(func() {
+		r.cancel()
+		if r.started.True() {
+			<-r.done
+		}
+	})
+}
+
+// backoff returns the delay before retrying the message that failed the given attempts.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.c.RetryDelay
+	for i := 1; i < attempts && delay < r.c.MaxRetryDelay; i++ {
+		delay <<= 1
+	}
+
+	return min(delay, r.c.MaxRetryDelay)
+}
+
+// fail records the failed attempt of msg, returns the delay before retrying,
+// or 0 if msg is dead.
+func (r *Relay) fail(ctx context.Context, msg *Message, cause error) time.Duration {
+	msg.Attempts++
+	dead := msg.Attempts >= r.c.MaxAttempts
+	if err := r.outbox.failCtx(ctx, r.conn, msg, dead, cause); err != nil {
+		logx.WithContext(ctx).Errorf("outbox relay: failed to update message %d of %s, error: %v",
+			msg.Id, r.c.Table, err)
+		return r.backoff(msg.Attempts)
+	}
+
+	if !dead {
+		logx.WithContext(ctx).Errorf("outbox relay: failed to publish message %d of %s, attempts: %d, error: %v",
+			msg.Id, r.c.Table, msg.Attempts, cause)
+		return r.backoff(msg.Attempts)
+	}
+
+	logx.WithContext(ctx).Errorf("outbox relay: message %d of %s is dead after %d attempts, error: %v",
+		msg.Id, r.c.Table, msg.Attempts, cause)
+	if r.deadLetter != nil {
+		r.safeDeadLetter(ctx, msg, cause)
+	}
+
+	return 0
+}
+
+func (r *Relay) publish(msg *Message) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.c.PublishTimeout)
+	defer cancel()
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return r.publisher.Publish(ctx, msg)
+}
+
+// relay relays a batch of the pending messages if it's the leader,
+// returns the delay before the next batch.
+func (r *Relay) relay() time.Duration {
+	// acquiring the held lock extends the lease, and fails if the lock is lost.
+	leader, err := r.lock.AcquireCtx(r.ctx)
+	if err != nil || !leader {
+		return r.c.Interval
+	}
+
+	// the published messages might be still on the replicas, so reads the primary.
+	msgs, err := r.outbox.pendingCtx(sqlx.ForcePrimary(r.ctx), r.conn, r.c.BatchSize)
+	if err != nil {
+		if r.ctx.Err() == nil {
+			logx.Errorf("outbox relay: failed to query pending messages of %s, error: %v", r.c.Table, err)
+		}
+		return r.c.Interval
+	}
+
+	// the messages are marked without r.ctx, to finish the batch on stopping.
+	ctx := context.Background()
+	var (
+		sent  []int64
+		delay time.Duration
+	)
+	for _, msg := range msgs {
+		if r.ctx.Err() != nil {
+			break
+		}
+
+		if err := r.publish(msg); err != nil {
+			// the following messages wait for the retries to keep the order.
+			if delay = r.fail(ctx, msg, err); delay > 0 {
+				break
+			}
+			continue
+		}
+
+		sent = append(sent, msg.Id)
+	}
+
+	if len(sent) > 0 {
+		if err := r.outbox.deleteCtx(ctx, r.conn, sent); err != nil {
+			logx.Errorf("outbox relay: failed to delete published messages of %s, error: %v", r.c.Table, err)
+			return r.c.Interval
+		}
+	}
+
+	if delay > 0 {
+		return delay
+	}
+	if int64(len(msgs)) < r.c.BatchSize {
+		return r.c.Interval
+	}
+
+	return 0
+}
+
+func (r *Relay) resign() {
+	if _, err := r.lock.Release(); err != nil {
+		logx.Errorf("outbox relay: failed to release lock %s, error: %v", r.c.LockKey, err)
+	}
+}
+
+func (r *Relay) safeDeadLetter(ctx context.Context, msg *Message, cause error) {
+	defer func() {
+		if p := recover(); p != nil {
+			logx.WithContext(ctx).Errorf("outbox relay: dead letter handler panics on message %d of %s: %v",
+				msg.Id, r.c.Table, p)
+		}
+	}()
+
+	r.deadLetter(ctx, msg, cause)
+}
+
+func (r *Relay) wait(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-r.ctx.Done():
+	}
+}
@@ -0,0 +1,236 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/r27153733/fastgozero/core/stores/dbtest"
+	"github.com/r27153733/fastgozero/core/stores/redis"
+	"github.com/r27153733/fastgozero/core/stores/redis/redistest"
+	"github.com/r27153733/fastgozero/core/stores/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+const pendingQuery = "select id, topic, msg_key, payload, attempts from outbox where status = \\? order by id limit 10"
+
+func TestNewRelay(t *testing.T) {
+	rds := redistest.CreateRedis(t)
+	_, err := NewRelay(nil, rds, RelayConf{}, nil)
+	assert.Equal(t, ErrEmptyTable, err)
+
+	r, err := NewRelay(nil, rds, RelayConf{Table: "outbox"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "outbox:relay:outbox", r.c.LockKey)
+	assert.Equal(t, int64(1), r.c.BatchSize)
+	assert.Equal(t, 1, r.c.MaxAttempts)
+	assert.Equal(t, defaultRelayInterval, r.c.Interval)
+	assert.Equal(t, defaultRetryDelay, r.c.RetryDelay)
+	assert.Equal(t, defaultMaxRetryDelay, r.c.MaxRetryDelay)
+	assert.Equal(t, defaultPublishTimeout, r.c.PublishTimeout)
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	r := newTestRelay(t, nil, nil)
+	assert.Equal(t, time.Second, r.backoff(1))
+	assert.Equal(t, 4*time.Second, r.backoff(3))
+	assert.Equal(t, time.Minute, r.backoff(7))
+	assert.Equal(t, time.Minute, r.backoff(100))
+}
+
+func TestRelay_Relay(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		var published []int64
+		r := newTestRelay(t, sqlx.NewSqlConnFromDB(db), PublisherFunc(func(_ context.Context, msg *Message) error {
+			published = append(published, msg.Id)
+			return nil
+		}))
+
+		mock.ExpectQuery(pendingQuery).WithArgs(statusPending).WillReturnRows(
+			sqlmock.NewRows(messageColumns).AddRow(1, "a", "", "x", 0).AddRow(2, "a", "", "y", 0))
+		mock.ExpectExec("delete from outbox where id in \\(\\?, \\?\\)").WithArgs(int64(1), int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		assert.Equal(t, r.c.Interval, r.relay())
+		assert.Equal(t, []int64{1, 2}, published)
+
+		// a full batch, relays the next batch immediately.
+		rows := sqlmock.NewRows(messageColumns)
+		for i := 1; i <= 10; i++ {
+			rows.AddRow(i, "a", "", "x", 0)
+		}
+		mock.ExpectQuery(pendingQuery).WithArgs(statusPending).WillReturnRows(rows)
+		mock.ExpectExec("delete from outbox").WillReturnResult(sqlmock.NewResult(0, 10))
+		assert.Equal(t, time.Duration(0), r.relay())
+
+		mock.ExpectQuery(pendingQuery).WithArgs(statusPending).WillReturnError(errors.New("any"))
+		assert.Equal(t, r.c.Interval, r.relay())
+	})
+}
+
+func TestRelay_ReadPrimary(t *testing.T) {
+	primaryDB, primary, err := sqlmock.NewWithDSN("outbox_relay_primary")
+	assert.NoError(t, err)
+	defer primaryDB.Close()
+	replicaDB, replica, err := sqlmock.NewWithDSN("outbox_relay_replica")
+	assert.NoError(t, err)
+	defer replicaDB.Close()
+
+	conn, err := sqlx.NewConn(sqlx.SqlConf{
+		DataSource: "outbox_relay_primary",
+		DriverName: "sqlmock",
+		Replicas:   []string{"outbox_relay_replica"},
+	})
+	assert.NoError(t, err)
+
+	var published []int64
+	r := newTestRelay(t, conn, PublisherFunc(func(_ context.Context, msg *Message) error {
+		published = append(published, msg.Id)
+		return nil
+	}))
+
+	// the pending messages are read from the primary, the replicas might lag behind.
+	primary.ExpectQuery(pendingQuery).WithArgs(statusPending).WillReturnRows(
+		sqlmock.NewRows(messageColumns).AddRow(1, "a", "", "x", 0))
+	primary.ExpectExec("delete from outbox where id in \\(\\?\\)").WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	primary.ExpectQuery(pendingQuery).WithArgs(statusPending).WillReturnRows(sqlmock.NewRows(messageColumns))
+	assert.Equal(t, r.c.Interval, r.relay())
+	assert.Equal(t, r.c.Interval, r.relay())
+	assert.Equal(t, []int64{1}, published)
+	assert.NoError(t, primary.ExpectationsWereMet())
+	assert.NoError(t, replica.ExpectationsWereMet())
+}
+
+func TestRelay_Retry(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		var published []int64
+		r := newTestRelay(t, sqlx.NewSqlConnFromDB(db), PublisherFunc(func(_ context.Context, msg *Message) error {
+			published = append(published, msg.Id)
+			if msg.Id == 2 {
+				return errors.New("any")
+			}
+			return nil
+		}))
+
+		// the messages after the failed one wait for the retry.
+		mock.ExpectQuery(pendingQuery).WithArgs(statusPending).WillReturnRows(
+			sqlmock.NewRows(messageColumns).AddRow(1, "a", "", "x", 0).AddRow(2, "a", "", "y", 1).
+				AddRow(3, "a", "", "z", 0))
+		mock.ExpectExec("update outbox set status = \\?, attempts = \\?, last_error = \\? where id = \\?").
+			WithArgs(statusPending, 2, "any", int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("delete from outbox where id in \\(\\?\\)").WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.Equal(t, 2*time.Second, r.relay())
+		assert.Equal(t, []int64{1, 2}, published)
+	})
+}
+
+func TestRelay_DeadLetter(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		var (
+			published []int64
+			dead      []int64
+		)
+		r := newTestRelay(t, sqlx.NewSqlConnFromDB(db), PublisherFunc(func(_ context.Context, msg *Message) error {
+			published = append(published, msg.Id)
+			if msg.Id == 1 {
+				panic("any")
+			}
+			return nil
+		}), WithDeadLetterHandler(func(_ context.Context, msg *Message, err error) {
+			dead = append(dead, msg.Id)
+			assert.Equal(t, 3, msg.Attempts)
+			assert.Error(t, err)
+			panic("any")
+		}))
+
+		// the dead message is skipped, the following messages go on.
+		mock.ExpectQuery(pendingQuery).WithArgs(statusPending).WillReturnRows(
+			sqlmock.NewRows(messageColumns).AddRow(1, "a", "", "x", 2).AddRow(2, "a", "", "y", 0))
+		mock.ExpectExec("update outbox set status = \\?, attempts = \\?, last_error = \\? where id = \\?").
+			WithArgs(statusDead, 3, "panic: any", int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("delete from outbox where id in \\(\\?\\)").WithArgs(int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.Equal(t, r.c.Interval, r.relay())
+		assert.Equal(t, []int64{1, 2}, published)
+		assert.Equal(t, []int64{1}, dead)
+	})
+}
+
+func TestRelay_Leader(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		rds := redistest.CreateRedis(t)
+		c := RelayConf{
+			Table:     "outbox",
+			BatchSize: 10,
+		}
+		leader, err := NewRelay(sqlx.NewSqlConnFromDB(db), rds, c, PublisherFunc(
+			func(_ context.Context, _ *Message) error {
+				return nil
+			}))
+		assert.NoError(t, err)
+		standby, err := NewRelay(sqlx.NewSqlConnFromDB(db), rds, c, PublisherFunc(
+			func(_ context.Context, _ *Message) error {
+				t.Fatal("standby relay should not publish")
+				return nil
+			}))
+		assert.NoError(t, err)
+
+		mock.ExpectQuery(pendingQuery).WithArgs(statusPending).WillReturnRows(sqlmock.NewRows(messageColumns))
+		assert.Equal(t, leader.c.Interval, leader.relay())
+		// the standby relay doesn't query the outbox.
+		assert.Equal(t, standby.c.Interval, standby.relay())
+
+		leader.resign()
+		mock.ExpectQuery(pendingQuery).WithArgs(statusPending).WillReturnRows(sqlmock.NewRows(messageColumns))
+		assert.Equal(t, standby.c.Interval, standby.relay())
+		assert.Equal(t, leader.c.Interval, leader.relay())
+		standby.resign()
+	})
+}
+
+func TestRelay_StartStop(t *testing.T) {
+	rds := redistest.CreateRedis(t)
+	c := RelayConf{Table: "outbox"}
+	// the lock is held by others, so the relay stands by.
+	ok, err := redis.NewRedisLock(rds, relayLockPrefix+c.Table).Acquire()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	r, err := NewRelay(nil, rds, c, nil)
+	assert.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		r.Start()
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	r.Stop()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay should be stopped")
+	}
+
+	// starting a stopped relay returns immediately.
+	r.Start()
+	r.Stop()
+}
+
+func newTestRelay(t *testing.T, conn sqlx.SqlConn, publisher Publisher, opts ...RelayOption) *Relay {
+	r, err := NewRelay(conn, redistest.CreateRedis(t), RelayConf{
+		Table:         "outbox",
+		BatchSize:     10,
+		Interval:      time.Second,
+		MaxAttempts:   3,
+		RetryDelay:    time.Second,
+		MaxRetryDelay: time.Minute,
+	}, publisher, opts...)
+	assert.NoError(t, err)
+	t.Cleanup(r.resign)
+	return r
+}
@@ -8,8 +8,11 @@ import (
 	red "github.com/redis/go-redis/v9"
 )
 
+// ignoreCmds are the blocking commands, which are not protected by the breaker,
+// and not logged as slow calls.
 var ignoreCmds = map[string]lang.PlaceholderType{
-	"blpop": {},
+	"blpop":      {},
+	"xreadgroup": {},
 }
 
 type breakerHook struct {
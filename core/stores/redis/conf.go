@@ -12,18 +12,31 @@ var (
 	ErrEmptyType = errors.New("empty redis type")
 	// ErrEmptyKey is an error that indicates no redis key is set.
 	ErrEmptyKey = errors.New("empty redis key")
+	// ErrEmptyMasterName is an error that indicates no master name is set for redis sentinel.
+	ErrEmptyMasterName = errors.New("empty redis sentinel master name")
 )
 
 type (
 	// A RedisConf is a redis config.
 	RedisConf struct {
+		// Host is the address of the node, or the comma separated addresses
+		// of the cluster nodes or the sentinels.
 		Host     string
-		Type     string `json:",default=node,options=node|cluster"`
+		Type     string `json:",default=node,options=node|cluster|sentinel"`
 		Pass     string `json:",optional"`
 		Tls      bool   `json:",optional"`
 		NonBlock bool   `json:",default=true"`
 		// PingTimeout is the timeout for ping redis.
 		PingTimeout time.Duration `json:",default=1s"`
+		// MasterName is the name of the master monitored by the sentinels, only for sentinel type.
+		MasterName   string `json:",optional"`
+		SentinelUser string `json:",optional"`
+		SentinelPass string `json:",optional"`
+		// ReadOnly routes the read-only commands to the replicas, only for cluster and sentinel types.
+		ReadOnly bool `json:",optional"`
+		// RouteByLatency routes the read-only commands to the node with the lowest latency,
+		// either a master or a replica, only for cluster and sentinel types.
+		RouteByLatency bool `json:",optional"`
 	}
 
 	// A RedisKeyConf is a redis config with key.
@@ -36,18 +49,7 @@ type (
 // NewRedis returns a Redis.
 // Deprecated: use MustNewRedis or NewRedis instead.
 func (rc RedisConf) NewRedis() *Redis {
-	var opts []Option
-	if rc.Type == ClusterType {
-		opts = append(opts, Cluster())
-	}
-	if len(rc.Pass) > 0 {
-		opts = append(opts, WithPass(rc.Pass))
-	}
-	if rc.Tls {
-		opts = append(opts, WithTLS())
-	}
-
-	return newRedis(rc.Host, opts...)
+	return newRedis(rc.Host, rc.options()...)
 }
 
 // Validate validates the RedisConf.
@@ -60,6 +62,10 @@ func (rc RedisConf) Validate() error {
 		return ErrEmptyType
 	}
 
+	if rc.Type == SentinelType && len(rc.MasterName) == 0 {
+		return ErrEmptyMasterName
+	}
+
 	return nil
 }
 
@@ -75,3 +81,30 @@ func (rkc RedisKeyConf) Validate() error {
 
 	return nil
 }
+
+func (rc RedisConf) options() []Option {
+	var opts []Option
+	switch rc.Type {
+	case ClusterType:
+		opts = append(opts, Cluster())
+	case SentinelType:
+		opts = append(opts, Sentinel(rc.MasterName))
+		if len(rc.SentinelUser) > 0 || len(rc.SentinelPass) > 0 {
+			opts = append(opts, WithSentinelAuth(rc.SentinelUser, rc.SentinelPass))
+		}
+	}
+	if len(rc.Pass) > 0 {
+		opts = append(opts, WithPass(rc.Pass))
+	}
+	if rc.Tls {
+		opts = append(opts, WithTLS())
+	}
+	if rc.ReadOnly {
+		opts = append(opts, WithReadOnly())
+	}
+	if rc.RouteByLatency {
+		opts = append(opts, WithRouteByLatency())
+	}
+
+	return opts
+}
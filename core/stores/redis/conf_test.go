@@ -50,6 +50,25 @@ func TestRedisConf(t *testing.T) {
 			},
 			ok: true,
 		},
+		{
+			name: "missing master name",
+			RedisConf: RedisConf{
+				Host: "localhost:26379",
+				Type: SentinelType,
+			},
+			ok: false,
+		},
+		{
+			name: "ok",
+			RedisConf: RedisConf{
+				Host:         "localhost:26379,localhost:26380",
+				Type:         SentinelType,
+				MasterName:   "mymaster",
+				SentinelPass: "pwd",
+				ReadOnly:     true,
+			},
+			ok: true,
+		},
 	}
 
 	for _, test := range tests {
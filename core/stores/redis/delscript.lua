@@ -1,5 +1,8 @@
-if redis.call("GET", KEYS[1]) == ARGV[1] then
-    return redis.call("DEL", KEYS[1])
-else
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
     return 0
-end
\ No newline at end of file
+end
+if ARGV[2] == "1" and redis.call("DECR", KEYS[2]) > 0 then
+    return 1
+end
+redis.call("DEL", KEYS[2])
+return redis.call("DEL", KEYS[1])
\ No newline at end of file
@@ -44,7 +44,7 @@ func (h durationHook) ProcessHook(next red.ProcessHook) red.ProcessHook {
 		endSpan(err)
 		duration := timex.Since(start)
 
-		if duration > slowThreshold.Load() {
+		if _, ok := ignoreCmds[cmd.Name()]; !ok && duration > slowThreshold.Load() {
 			logDuration(ctx, []red.Cmder{cmd}, duration)
 			metricSlowCount.Inc(cmd.Name())
 		}
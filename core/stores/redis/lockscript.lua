@@ -1,6 +1,26 @@
 if redis.call("GET", KEYS[1]) == ARGV[1] then
     redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
-    return "OK"
-else
-    return redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2])
-end
\ No newline at end of file
+    if ARGV[3] == "1" then
+        redis.call("INCR", KEYS[3])
+        redis.call("PEXPIRE", KEYS[3], ARGV[2])
+    end
+    if ARGV[4] ~= "1" then
+        return 1
+    end
+    local token = redis.call("GET", KEYS[2])
+    if not token then
+        token = redis.call("INCR", KEYS[2])
+    end
+    return tonumber(token)
+end
+
+if not redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+    return false
+end
+if ARGV[3] == "1" then
+    redis.call("SET", KEYS[3], 1, "PX", ARGV[2])
+end
+if ARGV[4] ~= "1" then
+    return 1
+end
+return redis.call("INCR", KEYS[2])
\ No newline at end of file
@@ -20,6 +20,8 @@ const (
 	ClusterType = "cluster"
 	// NodeType means redis node.
 	NodeType = "node"
+	// SentinelType means redis master/replicas monitored by sentinels.
+	SentinelType = "sentinel"
 	// Nil is an alias of redis.Nil.
 	Nil = red.Nil
 
@@ -59,6 +61,12 @@ type (
 		tls   bool
 		brk   breaker.Breaker
 		hooks []red.Hook
+		// masterName, sentinelUser and sentinelPass are used for sentinel type.
+		masterName     string
+		sentinelUser   string
+		sentinelPass   string
+		readOnly       bool
+		routeByLatency bool
 	}
 
 	// RedisNode interface represents a redis node.
@@ -102,6 +110,22 @@ type (
 
 	// Cmder is an alias of redis.Cmder.
 	Cmder = red.Cmder
+
+	// PubSub is an alias of redis.PubSub.
+	PubSub = red.PubSub
+	// Message is an alias of redis.Message.
+	Message = red.Message
+
+	// XMessage is an alias of redis.XMessage.
+	XMessage = red.XMessage
+	// XStream is an alias of redis.XStream.
+	XStream = red.XStream
+	// XPendingExt is an alias of redis.XPendingExt.
+	XPendingExt = red.XPendingExt
+
+	subscribable interface {
+		Subscribe(ctx context.Context, channels ...string) *red.PubSub
+	}
 )
 
 // MustNewRedis returns a Redis with given options.
@@ -123,16 +147,7 @@ func NewRedis(conf RedisConf, opts ...Option) (*Redis, error) {
 		return nil, err
 	}
 
-	if conf.Type == ClusterType {
-		opts = append([]Option{Cluster()}, opts...)
-	}
-	if len(conf.Pass) > 0 {
-		opts = append([]Option{WithPass(conf.Pass)}, opts...)
-	}
-	if conf.Tls {
-		opts = append([]Option{WithTLS()}, opts...)
-	}
-
+	opts = append(conf.options(), opts...)
 	rds := newRedis(conf.Host, opts...)
 	if !conf.NonBlock {
 		if err := rds.checkConnection(conf.PingTimeout); err != nil {
@@ -1209,6 +1224,33 @@ func (s *Redis) PublishCtx(ctx context.Context, channel string, message interfac
 	return conn.Publish(ctx, channel, message).Result()
 }
 
+// Subscribe subscribes the given channels, the returned PubSub needs to be closed after use.
+func (s *Redis) Subscribe(channels ...string) (*PubSub, error) {
+	return s.SubscribeCtx(context.Background(), channels...)
+}
+
+// SubscribeCtx subscribes the given channels, the returned PubSub needs to be closed after use.
+func (s *Redis) SubscribeCtx(ctx context.Context, channels ...string) (*PubSub, error) {
+	conn, err := getRedis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, ok := conn.(subscribable)
+	if !ok {
+		return nil, fmt.Errorf("redis type '%s' doesn't support subscribing", s.Type)
+	}
+
+	pubsub := sub.Subscribe(ctx, channels...)
+	// wait for the confirmation, to make sure the subscription is created.
+	if _, err = pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	return pubsub, nil
+}
+
 // Rpop is the implementation of redis rpop command.
 func (s *Redis) Rpop(key string) (string, error) {
 	return s.RpopCtx(context.Background(), key)
@@ -1689,6 +1731,152 @@ func (s *Redis) UnlinkCtx(ctx context.Context, keys ...string) (int64, error) {
 	return conn.Unlink(ctx, keys...).Result()
 }
 
+// XAck is the implementation of redis xack command.
+func (s *Redis) XAck(stream, group string, ids ...string) (int64, error) {
+	return s.XAckCtx(context.Background(), stream, group, ids...)
+}
+
+// XAckCtx is the implementation of redis xack command.
+func (s *Redis) XAckCtx(ctx context.Context, stream, group string, ids ...string) (int64, error) {
+	conn, err := getRedis(s)
+	if err != nil {
+		return 0, err
+	}
+
+	return conn.XAck(ctx, stream, group, ids...).Result()
+}
+
+// XAdd is the implementation of redis xadd command, id is * to be generated by redis,
+// the stream is not created if noCreate is true.
+func (s *Redis) XAdd(stream string, noCreate bool, id string, values any) (string, error) {
+	return s.XAddCtx(context.Background(), stream, noCreate, id, values)
+}
+
+// XAddCtx is the implementation of redis xadd command, id is * to be generated by redis,
+// the stream is not created if noCreate is true.
+func (s *Redis) XAddCtx(ctx context.Context, stream string, noCreate bool, id string,
+	values any) (string, error) {
+	conn, err := getRedis(s)
+	if err != nil {
+		return "", err
+	}
+
+	return conn.XAdd(ctx, &red.XAddArgs{
+		Stream:     stream,
+		NoMkStream: noCreate,
+		ID:         id,
+		Values:     values,
+	}).Result()
+}
+
+// XClaim is the implementation of redis xclaim command.
+func (s *Redis) XClaim(stream, group, consumer string, minIdle time.Duration,
+	ids ...string) ([]XMessage, error) {
+	return s.XClaimCtx(context.Background(), stream, group, consumer, minIdle, ids...)
+}
+
+// XClaimCtx is the implementation of redis xclaim command.
+func (s *Redis) XClaimCtx(ctx context.Context, stream, group, consumer string,
+	minIdle time.Duration, ids ...string) ([]XMessage, error) {
+	conn, err := getRedis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.XClaim(ctx, &red.XClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Messages: ids,
+	}).Result()
+}
+
+// XGroupCreate is the implementation of redis xgroup create command.
+func (s *Redis) XGroupCreate(stream, group, start string) (string, error) {
+	return s.XGroupCreateCtx(context.Background(), stream, group, start)
+}
+
+// XGroupCreateCtx is the implementation of redis xgroup create command.
+func (s *Redis) XGroupCreateCtx(ctx context.Context, stream, group, start string) (string, error) {
+	conn, err := getRedis(s)
+	if err != nil {
+		return "", err
+	}
+
+	return conn.XGroupCreate(ctx, stream, group, start).Result()
+}
+
+// XGroupCreateMkStream is the implementation of redis xgroup create command with mkstream.
+func (s *Redis) XGroupCreateMkStream(stream, group, start string) (string, error) {
+	return s.XGroupCreateMkStreamCtx(context.Background(), stream, group, start)
+}
+
+// XGroupCreateMkStreamCtx is the implementation of redis xgroup create command with mkstream.
+func (s *Redis) XGroupCreateMkStreamCtx(ctx context.Context, stream, group, start string) (
+	string, error) {
+	conn, err := getRedis(s)
+	if err != nil {
+		return "", err
+	}
+
+	return conn.XGroupCreateMkStream(ctx, stream, group, start).Result()
+}
+
+// XPending is the implementation of redis xpending command with the extended form,
+// which returns at most count pending entries that idle for at least minIdle.
+func (s *Redis) XPending(stream, group string, minIdle time.Duration,
+	count int64) ([]XPendingExt, error) {
+	return s.XPendingCtx(context.Background(), stream, group, minIdle, count)
+}
+
+// XPendingCtx is the implementation of redis xpending command with the extended form,
+// which returns at most count pending entries that idle for at least minIdle.
+func (s *Redis) XPendingCtx(ctx context.Context, stream, group string, minIdle time.Duration,
+	count int64) ([]XPendingExt, error) {
+	conn, err := getRedis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.XPendingExt(ctx, &red.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Idle:   minIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  count,
+	}).Result()
+}
+
+// XReadGroup uses passed in redis connection to execute redis xreadgroup command,
+// which blocks for block if not negative, streams are the stream names and the ids,
+// like stream1, stream2, >, >.
+func (s *Redis) XReadGroup(node RedisNode, group, consumer string, count int64,
+	block time.Duration, noAck bool, streams ...string) ([]XStream, error) {
+	return s.XReadGroupCtx(context.Background(), node, group, consumer, count, block, noAck,
+		streams...)
+}
+
+// XReadGroupCtx uses passed in redis connection to execute redis xreadgroup command,
+// which blocks for block if not negative, streams are the stream names and the ids,
+// like stream1, stream2, >, >.
+func (s *Redis) XReadGroupCtx(ctx context.Context, node RedisNode, group, consumer string,
+	count int64, block time.Duration, noAck bool, streams ...string) ([]XStream, error) {
+	if node == nil {
+		return nil, ErrNilNode
+	}
+
+	return node.XReadGroup(ctx, &red.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  streams,
+		Count:    count,
+		Block:    block,
+		NoAck:    noAck,
+	}).Result()
+}
+
 // Zadd is the implementation of redis zadd command.
 func (s *Redis) Zadd(key string, score int64, value string) (bool, error) {
 	return s.ZaddCtx(context.Background(), key, score, value)
@@ -2400,6 +2588,15 @@ func Cluster() Option {
 	}
 }
 
+// Sentinel customizes the given Redis as the master named masterName monitored by sentinels,
+// the address of the Redis is the comma separated addresses of the sentinels.
+func Sentinel(masterName string) Option {
+	return func(r *Redis) {
+		r.Type = SentinelType
+		r.masterName = masterName
+	}
+}
+
 // SetSlowThreshold sets the slow threshold.
 func SetSlowThreshold(threshold time.Duration) {
 	slowThreshold.Set(threshold)
@@ -2412,6 +2609,30 @@ func WithPass(pass string) Option {
 	}
 }
 
+// WithReadOnly customizes the given Redis to route the read-only commands to the replicas,
+// only for cluster and sentinel types.
+func WithReadOnly() Option {
+	return func(r *Redis) {
+		r.readOnly = true
+	}
+}
+
+// WithRouteByLatency customizes the given Redis to route the read-only commands
+// to the node with the lowest latency, only for cluster and sentinel types.
+func WithRouteByLatency() Option {
+	return func(r *Redis) {
+		r.routeByLatency = true
+	}
+}
+
+// WithSentinelAuth customizes the given Redis with the credentials of the sentinels.
+func WithSentinelAuth(user, pass string) Option {
+	return func(r *Redis) {
+		r.sentinelUser = user
+		r.sentinelPass = pass
+	}
+}
+
 // WithTLS customizes the given Redis with TLS enabled.
 func WithTLS() Option {
 	return func(r *Redis) {
@@ -2431,12 +2652,37 @@ func acceptable(err error) bool {
 	return err == nil || errorx.In(err, red.Nil, context.Canceled)
 }
 
+// addHooks adds the duration, breaker and custom hooks of r to client.
+func addHooks(client interface{ AddHook(red.Hook) }, r *Redis) {
+	hooks := append([]red.Hook{defaultDurationHook, breakerHook{
+		brk: r.brk,
+	}}, r.hooks...)
+	for _, hook := range hooks {
+		client.AddHook(hook)
+	}
+}
+
+// clientKey returns the key to share the client of r,
+// the clients with different read routings are not shared.
+func clientKey(r *Redis, key string) string {
+	switch {
+	case r.routeByLatency:
+		return key + "#latency"
+	case r.readOnly:
+		return key + "#readonly"
+	default:
+		return key
+	}
+}
+
 func getRedis(r *Redis) (RedisNode, error) {
 	switch r.Type {
 	case ClusterType:
 		return getCluster(r)
 	case NodeType:
 		return getClient(r)
+	case SentinelType:
+		return getSentinel(r)
 	default:
 		return nil, fmt.Errorf("redis type '%s' is not supported", r.Type)
 	}
@@ -2090,6 +2090,26 @@ func TestRedisPublish(t *testing.T) {
 	})
 }
 
+func TestRedisSubscribe(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		_, err := newRedis(client.Addr, badType()).Subscribe("Test")
+		assert.NotNil(t, err)
+		pubsub, err := client.Subscribe("Test")
+		assert.Nil(t, err)
+		defer pubsub.Close()
+
+		_, err = client.Publish("Test", "message")
+		assert.Nil(t, err)
+		select {
+		case msg := <-pubsub.Channel():
+			assert.Equal(t, "Test", msg.Channel)
+			assert.Equal(t, "message", msg.Payload)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	})
+}
+
 func TestRedisRPopLPush(t *testing.T) {
 	runOnRedis(t, func(client *Redis) {
 		_, err := newRedis(client.Addr, badType()).RPopLPush("Source", "Destination")
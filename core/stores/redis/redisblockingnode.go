@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"crypto/tls"
 	"fmt"
 
 	"github.com/r27153733/fastgozero/core/logx"
@@ -28,6 +29,7 @@ func CreateBlockingNode(r *Redis) (ClosableNode, error) {
 			MinIdleConns: 1,
 			ReadTimeout:  timeout,
 		})
+		addHooks(client, r)
 		return &clientBridge{client}, nil
 	case ClusterType:
 		client := red.NewClusterClient(&red.ClusterOptions{
@@ -38,7 +40,31 @@ func CreateBlockingNode(r *Redis) (ClosableNode, error) {
 			MinIdleConns: 1,
 			ReadTimeout:  timeout,
 		})
+		addHooks(client, r)
 		return &clusterBridge{client}, nil
+	case SentinelType:
+		var tlsConfig *tls.Config
+		if r.tls {
+			tlsConfig = &tls.Config{
+				InsecureSkipVerify: true,
+			}
+		}
+		// the blocking commands always go to the master.
+		client := red.NewFailoverClient(&red.FailoverOptions{
+			MasterName:       r.masterName,
+			SentinelAddrs:    splitClusterAddrs(r.Addr),
+			SentinelUsername: r.sentinelUser,
+			SentinelPassword: r.sentinelPass,
+			Password:         r.Pass,
+			DB:               defaultDatabase,
+			MaxRetries:       maxRetries,
+			PoolSize:         1,
+			MinIdleConns:     1,
+			ReadTimeout:      timeout,
+			TLSConfig:        tlsConfig,
+		})
+		addHooks(client, r)
+		return &clientBridge{client}, nil
 	default:
 		return nil, fmt.Errorf("unknown redis type: %s", r.Type)
 	}
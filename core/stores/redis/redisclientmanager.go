@@ -38,12 +38,7 @@ func getClient(r *Redis) (*red.Client, error) {
 			TLSConfig:    tlsConfig,
 		})
 
-		hooks := append([]red.Hook{defaultDurationHook, breakerHook{
-			brk: r.brk,
-		}}, r.hooks...)
-		for _, hook := range hooks {
-			store.AddHook(hook)
-		}
+		addHooks(store, r)
 
 		connCollector.registerClient(&statGetter{
 			clientType: NodeType,
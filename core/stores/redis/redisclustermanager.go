@@ -19,7 +19,8 @@ var (
 )
 
 func getCluster(r *Redis) (*red.ClusterClient, error) {
-	val, err := clusterManager.GetResource(r.Addr, func() (io.Closer, error) {
+	key := clientKey(r, r.Addr)
+	val, err := clusterManager.GetResource(key, func() (io.Closer, error) {
 		var tlsConfig *tls.Config
 		if r.tls {
 			tlsConfig = &tls.Config{
@@ -27,23 +28,20 @@ func getCluster(r *Redis) (*red.ClusterClient, error) {
 			}
 		}
 		store := red.NewClusterClient(&red.ClusterOptions{
-			Addrs:        splitClusterAddrs(r.Addr),
-			Password:     r.Pass,
-			MaxRetries:   maxRetries,
-			MinIdleConns: idleConns,
-			TLSConfig:    tlsConfig,
+			Addrs:          splitClusterAddrs(r.Addr),
+			Password:       r.Pass,
+			MaxRetries:     maxRetries,
+			MinIdleConns:   idleConns,
+			TLSConfig:      tlsConfig,
+			ReadOnly:       r.readOnly,
+			RouteByLatency: r.routeByLatency,
 		})
 
-		hooks := append([]red.Hook{defaultDurationHook, breakerHook{
-			brk: r.brk,
-		}}, r.hooks...)
-		for _, hook := range hooks {
-			store.AddHook(hook)
-		}
+		addHooks(store, r)
 
 		connCollector.registerClient(&statGetter{
 			clientType: ClusterType,
-			key:        r.Addr,
+			key:        key,
 			poolSize:   clusterPoolSize,
 			poolStats: func() *red.PoolStats {
 				return store.PoolStats()
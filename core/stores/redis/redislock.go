@@ -6,18 +6,30 @@ import (
 	"errors"
 	"math/rand"
 	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/mathx"
 	"github.com/r27153733/fastgozero/core/stringx"
+	"github.com/r27153733/fastgozero/core/threading"
+	"github.com/r27153733/fastgozero/core/timex"
 	red "github.com/redis/go-redis/v9"
 )
 
 const (
-	randomLen       = 16
-	tolerance       = 500 // milliseconds
-	millisPerSecond = 1000
+	randomLen   = 16
+	tolerance   = 500 // milliseconds
+	fenceSuffix = ":fence"
+	holdsSuffix = ":holds"
+	// defaultLeaseSeconds is the lease of the locks with watchdog if no expiration set.
+	defaultLeaseSeconds = 30
+	// the watchdog extends the lease every 1/3 lease.
+	watchdogRatio     = 3
+	lockRetryInterval = 50 * time.Millisecond
+	lockRetryJitter   = 0.5
 )
 
 var (
@@ -28,26 +40,87 @@ var (
 	//go:embed delscript.lua
 	delLuaScript string
 	delScript    = NewScript(delLuaScript)
+
+	//go:embed refreshscript.lua
+	refreshLuaScript string
+	refreshScript    = NewScript(refreshLuaScript)
+
+	lockRetryUnstable = mathx.NewUnstable(lockRetryJitter)
 )
 
-// A RedisLock is a redis lock.
-type RedisLock struct {
-	store   *Redis
-	seconds uint32
-	key     string
-	id      string
-}
+type (
+	// A RedisLock is a redis lock.
+	// With WithFencing, each successful acquisition gets a fencing token, which is monotonically
+	// increasing on the key, the protected resources can reject the requests with stale tokens.
+	RedisLock struct {
+		store     *Redis
+		seconds   uint32
+		key       string
+		fenceKey  string
+		holdsKey  string
+		id        string
+		reentrant bool
+		watchdog  bool
+		fencing   bool
+		token     atomic.Int64
+		// holds and dog are guarded by lock.
+		holds int
+		dog   *watchdog
+		lock  sync.Mutex
+	}
+
+	// LockOption defines the method to customize a RedisLock.
+	LockOption func(rl *RedisLock)
+
+	watchdog struct {
+		cancel context.CancelFunc
+		done   chan struct{}
+	}
+)
 
 func init() {
 	rand.NewSource(time.Now().UnixNano())
 }
 
 // NewRedisLock returns a RedisLock.
-func NewRedisLock(store *Redis, key string) *RedisLock {
-	return &RedisLock{
-		store: store,
-		key:   key,
-		id:    stringx.Randn(randomLen),
+func NewRedisLock(store *Redis, key string, opts ...LockOption) *RedisLock {
+	rl := &RedisLock{
+		store:    store,
+		key:      key,
+		fenceKey: sameSlotKey(key, fenceSuffix),
+		holdsKey: sameSlotKey(key, holdsSuffix),
+		id:       stringx.Randn(randomLen),
+	}
+	for _, opt := range opts {
+		opt(rl)
+	}
+
+	return rl
+}
+
+// WithLockOwner makes the lock reentrant for the owner, the locks with the same owner
+// can be acquired by each other, and the lock is released after all the holds released.
+func WithLockOwner(owner string) LockOption {
+	return func(rl *RedisLock) {
+		rl.id = owner
+		rl.reentrant = true
+	}
+}
+
+// WithFencing makes the lock return a fencing token on each acquisition.
+// The fencing tokens are kept in {key}:fence, which is never expired to keep them increasing,
+// so only use it on the keys of a limited set, not on the keys like per request ids.
+func WithFencing() LockOption {
+	return func(rl *RedisLock) {
+		rl.fencing = true
+	}
+}
+
+// WithWatchdog extends the lease of the lock in background while it's held,
+// the lease is 30s if no expiration set.
+func WithWatchdog() LockOption {
+	return func(rl *RedisLock) {
+		rl.watchdog = true
 	}
 }
 
@@ -58,26 +131,46 @@ func (rl *RedisLock) Acquire() (bool, error) {
 
 // AcquireCtx acquires the lock with the given ctx.
 func (rl *RedisLock) AcquireCtx(ctx context.Context) (bool, error) {
-	seconds := atomic.LoadUint32(&rl.seconds)
-	resp, err := rl.store.ScriptRunCtx(ctx, lockScript, []string{rl.key}, []string{
-		rl.id, strconv.Itoa(int(seconds)*millisPerSecond + tolerance),
-	})
-	if errors.Is(err, red.Nil) {
-		return false, nil
-	} else if err != nil {
+	token, err := rl.acquire(ctx)
+	if err != nil {
 		logx.Errorf("Error on acquiring lock for %s, %s", rl.key, err.Error())
 		return false, err
-	} else if resp == nil {
+	}
+	if token == 0 {
 		return false, nil
 	}
 
-	reply, ok := resp.(string)
-	if ok && reply == "OK" {
-		return true, nil
+	if rl.fencing {
+		rl.token.Store(token)
+	}
+	rl.lock.Lock()
+	if rl.reentrant {
+		rl.holds++
+	} else {
+		rl.holds = 1
 	}
+	if rl.watchdog && (rl.dog == nil || rl.dog.exited()) {
+		rl.dog = startWatchdog(rl.key, rl.leaseDuration(), rl.refresh)
+	}
+	rl.lock.Unlock()
 
-	logx.Errorf("Unknown reply when acquiring lock for %s: %v", rl.key, resp)
-	return false, nil
+	return true, nil
+}
+
+// Lock acquires the lock, blocks until acquired, returns the fencing token.
+func (rl *RedisLock) Lock() (int64, error) {
+	return rl.LockCtx(context.Background())
+}
+
+// LockCtx acquires the lock, blocks until acquired or ctx is done, returns the fencing token,
+// which is 0 without WithFencing.
+func (rl *RedisLock) LockCtx(ctx context.Context) (int64, error) {
+	err := retryLock(ctx, rl.AcquireCtx)
+	if err != nil {
+		return 0, err
+	}
+
+	return rl.Token(), nil
 }
 
 // Release releases the lock.
@@ -86,21 +179,194 @@ func (rl *RedisLock) Release() (bool, error) {
 }
 
 // ReleaseCtx releases the lock with the given ctx.
+// For reentrant locks, it releases one hold.
 func (rl *RedisLock) ReleaseCtx(ctx context.Context) (bool, error) {
-	resp, err := rl.store.ScriptRunCtx(ctx, delScript, []string{rl.key}, []string{rl.id})
+	released, err := rl.release(ctx)
 	if err != nil {
 		return false, err
 	}
 
-	reply, ok := resp.(int64)
-	if !ok {
-		return false, nil
+	rl.lock.Lock()
+	if released && rl.holds > 1 {
+		rl.holds--
+	} else {
+		rl.holds = 0
+		if rl.dog != nil {
+			rl.dog.stop()
+			rl.dog = nil
+		}
 	}
+	rl.lock.Unlock()
 
-	return reply == 1, nil
+	return released, nil
 }
 
 // SetExpire sets the expiration.
 func (rl *RedisLock) SetExpire(seconds int) {
 	atomic.StoreUint32(&rl.seconds, uint32(seconds))
 }
+
+// Token returns the fencing token of the last acquisition, 0 without WithFencing.
+func (rl *RedisLock) Token() int64 {
+	return rl.token.Load()
+}
+
+// acquire returns the fencing token, or 1 without fencing, 0 if the lock is held by others.
+func (rl *RedisLock) acquire(ctx context.Context) (int64, error) {
+	resp, err := rl.store.ScriptRunCtx(ctx, lockScript, []string{rl.key, rl.fenceKey, rl.holdsKey},
+		[]string{rl.id, strconv.FormatInt(rl.leaseMillis(), 10), rl.reentrantArg(), rl.fencingArg()})
+	if errors.Is(err, red.Nil) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	} else if resp == nil {
+		return 0, nil
+	}
+
+	token, ok := resp.(int64)
+	if !ok {
+		logx.Errorf("Unknown reply when acquiring lock for %s: %v", rl.key, resp)
+		return 0, nil
+	}
+
+	return token, nil
+}
+
+func (rl *RedisLock) fencingArg() string {
+	if rl.fencing {
+		return "1"
+	}
+
+	return "0"
+}
+
+func (rl *RedisLock) leaseDuration() time.Duration {
+	seconds := atomic.LoadUint32(&rl.seconds)
+	if seconds == 0 && rl.watchdog {
+		seconds = defaultLeaseSeconds
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func (rl *RedisLock) leaseMillis() int64 {
+	return rl.leaseDuration().Milliseconds() + tolerance
+}
+
+func (rl *RedisLock) reentrantArg() string {
+	if rl.reentrant {
+		return "1"
+	}
+
+	return "0"
+}
+
+// release releases one hold, returns false if the lock is not held.
+func (rl *RedisLock) release(ctx context.Context) (bool, error) {
+	resp, err := rl.store.ScriptRunCtx(ctx, delScript, []string{rl.key, rl.holdsKey},
+		[]string{rl.id, rl.reentrantArg()})
+	if err != nil {
+		return false, err
+	}
+
+	reply, ok := resp.(int64)
+	return ok && reply == 1, nil
+}
+
+// refresh extends the lease, returns false if the lock is not held anymore.
+func (rl *RedisLock) refresh(ctx context.Context) (bool, error) {
+	resp, err := rl.store.ScriptRunCtx(ctx, refreshScript, []string{rl.key, rl.holdsKey},
+		[]string{rl.id, strconv.FormatInt(rl.leaseMillis(), 10)})
+	if err != nil {
+		return false, err
+	}
+
+	reply, ok := resp.(int64)
+	return ok && reply == 1, nil
+}
+
+// retryLock calls acquire until acquired, or ctx is done.
+func retryLock(ctx context.Context, acquire func(ctx context.Context) (bool, error)) error {
+	for {
+		ok, err := acquire(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(lockRetryUnstable.AroundDuration(lockRetryInterval))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// sameSlotKey returns key with suffix, which is in the same cluster slot as key,
+// to be used in the same script.
+func sameSlotKey(key, suffix string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key + suffix
+		}
+	}
+
+	return "{" + key + "}" + suffix
+}
+
+func startWatchdog(key string, lease time.Duration, refresh func(ctx context.Context) (bool, error)) *watchdog {
+	ctx, cancel := context.WithCancel(context.Background())
+	dog := &watchdog{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	threading.GoSafe(func() {
+		defer close(dog.done)
+
+		ticker := timex.NewTicker(lease / watchdogRatio)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.Chan():
+				ok, err := refresh(ctx)
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+
+					logx.Errorf("Error on extending lock for %s, %s", key, err.Error())
+					continue
+				}
+				if !ok {
+					logx.Errorf("Lock for %s is lost, stop extending", key)
+					return
+				}
+			}
+		}
+	})
+
+	return dog
+}
+
+// exited returns true if the watchdog exited on losing the lock.
+func (dog *watchdog) exited() bool {
+	select {
+	case <-dog.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (dog *watchdog) stop() {
+	dog.cancel()
+	<-dog.done
+}
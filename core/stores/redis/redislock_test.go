@@ -3,7 +3,9 @@ package redis
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/r27153733/fastgozero/core/stringx"
@@ -63,3 +65,157 @@ func TestRedisLock_Expired(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestRedisLock_FencingToken(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := stringx.Rand()
+		firstLock := NewRedisLock(client, key, WithFencing())
+		ok, err := firstLock.Acquire()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		token := firstLock.Token()
+		assert.True(t, token > 0)
+
+		// acquiring again by the holder keeps the token.
+		ok, err = firstLock.Acquire()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, token, firstLock.Token())
+
+		ok, err = firstLock.Release()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		secondLock := NewRedisLock(client, key, WithFencing())
+		secondToken, err := secondLock.Lock()
+		assert.NoError(t, err)
+		assert.Equal(t, token+1, secondToken)
+	})
+}
+
+func TestRedisLock_WithoutFencing(t *testing.T) {
+	r := miniredis.RunT(t)
+	client := New(r.Addr())
+	key := stringx.Rand()
+	lock := NewRedisLock(client, key)
+	token, err := lock.Lock()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), token)
+	assert.Equal(t, int64(0), lock.Token())
+	assert.False(t, r.Exists(sameSlotKey(key, fenceSuffix)))
+
+	ok, err := lock.Acquire()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = lock.Release()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, r.Exists(sameSlotKey(key, fenceSuffix)))
+}
+
+func TestRedisLock_Reentrant(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := stringx.Rand()
+		outer := NewRedisLock(client, key, WithLockOwner("owner"))
+		inner := NewRedisLock(client, key, WithLockOwner("owner"))
+		other := NewRedisLock(client, key, WithLockOwner("other"))
+
+		ok, err := outer.Acquire()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = inner.Acquire()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, outer.Token(), inner.Token())
+		ok, err = other.Acquire()
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		// still held by outer.
+		ok, err = inner.Release()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = other.Acquire()
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = outer.Release()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = other.Acquire()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = outer.Release()
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestRedisLock_LockCtx(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		key := stringx.Rand()
+		firstLock := NewRedisLock(client, key, WithFencing())
+		firstLock.SetExpire(5)
+		ok, err := firstLock.Acquire()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		secondLock := NewRedisLock(client, key, WithFencing())
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		_, err = secondLock.LockCtx(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		time.AfterFunc(time.Millisecond*100, func() {
+			_, _ = firstLock.Release()
+		})
+		token, err := secondLock.LockCtx(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, firstLock.Token()+1, token)
+	})
+}
+
+func TestRedisLock_Watchdog(t *testing.T) {
+	r := miniredis.RunT(t)
+	client := New(r.Addr())
+	key := stringx.Rand()
+	lock := NewRedisLock(client, key, WithWatchdog())
+	lock.SetExpire(1)
+	ok, err := lock.Acquire()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// extended by the watchdog.
+	r.SetTTL(key, time.Millisecond*100)
+	assert.Eventually(t, func() bool {
+		return r.TTL(key) > time.Second
+	}, time.Second*2, time.Millisecond*10)
+
+	ok, err = lock.Release()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, r.Exists(key))
+
+	// the watchdog restarts after the lock is lost.
+	ok, err = lock.Acquire()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	r.Del(key)
+	time.Sleep(time.Millisecond * 500)
+	ok, err = lock.Acquire()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	r.SetTTL(key, time.Millisecond*100)
+	assert.Eventually(t, func() bool {
+		return r.TTL(key) > time.Second
+	}, time.Second*2, time.Millisecond*10)
+	ok, err = lock.Release()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestSameSlotKey(t *testing.T) {
+	assert.Equal(t, "{foo}:fence", sameSlotKey("foo", fenceSuffix))
+	assert.Equal(t, "{foo}bar:fence", sameSlotKey("{foo}bar", fenceSuffix))
+	assert.Equal(t, "{{}foo}:fence", sameSlotKey("{}foo", fenceSuffix))
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"crypto/tls"
+	"io"
+
+	"github.com/r27153733/fastgozero/core/syncx"
+	red "github.com/redis/go-redis/v9"
+)
+
+const masterNameSep = "@"
+
+var sentinelManager = syncx.NewResourceManager()
+
+func getSentinel(r *Redis) (RedisNode, error) {
+	key := clientKey(r, r.masterName+masterNameSep+r.Addr)
+	val, err := sentinelManager.GetResource(key, func() (io.Closer, error) {
+		var tlsConfig *tls.Config
+		if r.tls {
+			tlsConfig = &tls.Config{
+				InsecureSkipVerify: true,
+			}
+		}
+		opt := &red.FailoverOptions{
+			MasterName:       r.masterName,
+			SentinelAddrs:    splitClusterAddrs(r.Addr),
+			SentinelUsername: r.sentinelUser,
+			SentinelPassword: r.sentinelPass,
+			Password:         r.Pass,
+			DB:               defaultDatabase,
+			MaxRetries:       maxRetries,
+			MinIdleConns:     idleConns,
+			TLSConfig:        tlsConfig,
+			RouteByLatency:   r.routeByLatency,
+		}
+
+		// reading from the replicas needs the routing of the cluster client.
+		if r.readOnly || r.routeByLatency {
+			store := red.NewFailoverClusterClient(opt)
+			// the failover options have no ReadOnly, which routes the reads to the replicas,
+			// and the master serves if no replicas available.
+			store.Options().ReadOnly = true
+			addHooks(store, r)
+			registerSentinel(key, store.PoolStats)
+			return store, nil
+		}
+
+		store := red.NewFailoverClient(opt)
+		addHooks(store, r)
+		registerSentinel(key, store.PoolStats)
+		return store, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return val.(RedisNode), nil
+}
+
+func registerSentinel(key string, poolStats func() *red.PoolStats) {
+	connCollector.registerClient(&statGetter{
+		clientType: SentinelType,
+		key:        key,
+		poolSize:   nodePoolSize,
+		poolStats:  poolStats,
+	})
+}
@@ -0,0 +1,265 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentinel(t *testing.T) {
+	master := miniredis.RunT(t)
+	sentinel := runSentinel(t, "mymaster", master.Addr())
+
+	rds, err := NewRedis(RedisConf{
+		Host:         sentinel.addr,
+		Type:         SentinelType,
+		MasterName:   "mymaster",
+		SentinelUser: "user",
+		SentinelPass: "pass",
+		PingTimeout:  time.Second,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, rds.Set("foo", "bar"))
+	val, err := master.Get("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", val)
+	assert.Contains(t, sentinel.commands(), "auth user pass")
+
+	// the reads go through the replicas routing, the master serves if no replicas.
+	rds = MustNewRedis(RedisConf{
+		Host:       sentinel.addr,
+		Type:       SentinelType,
+		MasterName: "mymaster",
+		ReadOnly:   true,
+		NonBlock:   true,
+	})
+	val, err = rds.Get("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", val)
+
+	node, err := CreateBlockingNode(rds)
+	assert.NoError(t, err)
+	defer node.Close()
+	_, err = master.Lpush("list", "value")
+	assert.NoError(t, err)
+	val, err = rds.Blpop(node, "list")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", val)
+}
+
+func TestSentinel_ReadReplicas(t *testing.T) {
+	master := miniredis.RunT(t)
+	replica := miniredis.RunT(t)
+	sentinel := runSentinel(t, "mymaster", master.Addr(), replica.Addr())
+	assert.NoError(t, master.Set("foo", "master"))
+	assert.NoError(t, replica.Set("foo", "replica"))
+
+	rds := MustNewRedis(RedisConf{
+		Host:       sentinel.addr,
+		Type:       SentinelType,
+		MasterName: "mymaster",
+		ReadOnly:   true,
+		NonBlock:   true,
+	})
+	val, err := rds.Get("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "replica", val)
+
+	// the writes still go to the master.
+	assert.NoError(t, rds.Set("bar", "baz"))
+	val, err = master.Get("bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "baz", val)
+	assert.False(t, replica.Exists("bar"))
+}
+
+func TestSentinel_BlockingNodeTLS(t *testing.T) {
+	node, err := CreateBlockingNode(New("localhost:26379", Sentinel("mymaster"), WithTLS()))
+	assert.NoError(t, err)
+	defer node.Close()
+	assert.NotNil(t, node.(*clientBridge).Options().TLSConfig)
+}
+
+func TestSentinel_Unavailable(t *testing.T) {
+	_, err := NewRedis(RedisConf{
+		Host:        "localhost:0",
+		Type:        SentinelType,
+		MasterName:  "mymaster",
+		PingTimeout: time.Millisecond * 100,
+	})
+	assert.Error(t, err)
+
+	_, err = NewRedis(RedisConf{
+		Host:     "localhost:0",
+		Type:     SentinelType,
+		NonBlock: true,
+	})
+	assert.Equal(t, ErrEmptyMasterName, err)
+}
+
+func TestClientKey(t *testing.T) {
+	assert.Equal(t, "key", clientKey(New("addr"), "key"))
+	assert.Equal(t, "key#readonly", clientKey(New("addr", WithReadOnly()), "key"))
+	assert.Equal(t, "key#latency", clientKey(New("addr", WithReadOnly(), WithRouteByLatency()), "key"))
+
+	rc := RedisConf{
+		Host:           "localhost:6379",
+		Type:           ClusterType,
+		ReadOnly:       true,
+		RouteByLatency: true,
+	}
+	rds := rc.NewRedis()
+	assert.Equal(t, ClusterType, rds.Type)
+	assert.True(t, rds.readOnly)
+	assert.True(t, rds.routeByLatency)
+	cluster, err := getCluster(rds)
+	assert.NoError(t, err)
+	assert.True(t, cluster.Options().ReadOnly)
+	assert.True(t, cluster.Options().RouteByLatency)
+	plain, err := getCluster(New("localhost:6379", Cluster()))
+	assert.NoError(t, err)
+	assert.NotSame(t, cluster, plain)
+}
+
+// fakeSentinel is a sentinel that always reports the given master and replicas.
+type fakeSentinel struct {
+	addr       string
+	masterName string
+	masterHost string
+	masterPort string
+	replicas   []string
+	received   []string
+	lock       sync.Mutex
+}
+
+func runSentinel(t *testing.T, masterName, masterAddr string, replicaAddrs ...string) *fakeSentinel {
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	host, port, err := net.SplitHostPort(masterAddr)
+	assert.NoError(t, err)
+	s := &fakeSentinel{
+		addr:       listener.Addr().String(),
+		masterName: masterName,
+		masterHost: host,
+		masterPort: port,
+		replicas:   replicaAddrs,
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeSentinel) commands() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.received...)
+}
+
+func (s *fakeSentinel) reply(args []string) string {
+	switch strings.ToLower(args[0]) {
+	case "auth", "client":
+		return "+OK\r\n"
+	case "ping":
+		return "+PONG\r\n"
+	case "subscribe", "psubscribe":
+		return fmt.Sprintf("*3\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n:1\r\n",
+			len(args[0]), strings.ToLower(args[0]), len(args[1]), args[1])
+	case "sentinel":
+		if len(args) > 2 && args[2] != s.masterName {
+			return "*-1\r\n"
+		}
+
+		switch strings.ToLower(args[1]) {
+		case "get-master-addr-by-name":
+			return fmt.Sprintf("*2\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n",
+				len(s.masterHost), s.masterHost, len(s.masterPort), s.masterPort)
+		case "replicas", "slaves":
+			return s.replicasReply()
+		case "sentinels":
+			return "*0\r\n"
+		}
+	}
+
+	return "-ERR unknown command\r\n"
+}
+
+func (s *fakeSentinel) replicasReply() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(s.replicas))
+	for _, replica := range s.replicas {
+		host, port, _ := net.SplitHostPort(replica)
+		fields := []string{"ip", host, "port", port, "flags", "slave"}
+		fmt.Fprintf(&sb, "*%d\r\n", len(fields))
+		for _, field := range fields {
+			fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(field), field)
+		}
+	}
+
+	return sb.String()
+}
+
+func (s *fakeSentinel) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		s.lock.Lock()
+		s.received = append(s.received, strings.ToLower(strings.Join(args, " ")))
+		s.lock.Unlock()
+		if _, err = conn.Write([]byte(s.reply(args))); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if _, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.TrimSuffix(arg, "\r\n"))
+	}
+
+	return args, nil
+}
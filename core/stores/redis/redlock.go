@@ -0,0 +1,224 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/errorx"
+	"github.com/r27153733/fastgozero/core/threading"
+	"github.com/r27153733/fastgozero/core/timex"
+)
+
+const (
+	// the clock drift between the nodes is 1% of the lease plus 2ms.
+	clockDriftFactor = 0.01
+	clockDriftMin    = 2 * time.Millisecond
+)
+
+// ErrEmptyRedLockNodes is an error that indicates no redis nodes are set for RedLock.
+var ErrEmptyRedLockNodes = errors.New("empty redis nodes of redlock")
+
+type (
+	// A RedLockConf is the config of the independent redis nodes of a RedLock.
+	RedLockConf []RedisConf
+
+	// A RedLock is a lock over independent redis nodes, which is held if acquired
+	// on the majority of the nodes within the lease, as the Redlock algorithm.
+	// The fencing tokens are not provided, because the nodes count them separately.
+	RedLock struct {
+		locks    []*RedisLock
+		quorum   int
+		watchdog bool
+		// holds and dog are guarded by lock.
+		holds int
+		dog   *watchdog
+		lock  sync.Mutex
+	}
+)
+
+// Validate validates the RedLockConf.
+func (c RedLockConf) Validate() error {
+	if len(c) == 0 {
+		return ErrEmptyRedLockNodes
+	}
+
+	for _, node := range c {
+		if err := node.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewRedLock returns a RedLock on key over the nodes of c.
+func (c RedLockConf) NewRedLock(key string, opts ...LockOption) (*RedLock, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	stores := make([]*Redis, 0, len(c))
+	for _, node := range c {
+		store, err := NewRedis(node)
+		if err != nil {
+			return nil, err
+		}
+
+		stores = append(stores, store)
+	}
+
+	return NewRedLock(stores, key, opts...), nil
+}
+
+// NewRedLock returns a RedLock on key over the independent redis nodes.
+func NewRedLock(stores []*Redis, key string, opts ...LockOption) *RedLock {
+	rl := &RedLock{
+		quorum: len(stores)/2 + 1,
+	}
+	for _, store := range stores {
+		lock := NewRedisLock(store, key, opts...)
+		if len(rl.locks) > 0 {
+			// the same owner on all the nodes.
+			lock.id = rl.locks[0].id
+		}
+		// the lease is extended over the nodes together.
+		rl.watchdog = lock.watchdog
+		lock.watchdog = false
+		rl.locks = append(rl.locks, lock)
+	}
+
+	return rl
+}
+
+// Acquire acquires the lock.
+func (rl *RedLock) Acquire() (bool, error) {
+	return rl.AcquireCtx(context.Background())
+}
+
+// AcquireCtx acquires the lock with the given ctx.
+// It returns an error only if the lock can't be acquired because of the errors.
+func (rl *RedLock) AcquireCtx(ctx context.Context) (bool, error) {
+	if len(rl.locks) == 0 {
+		return false, ErrEmptyRedLockNodes
+	}
+
+	start := timex.Now()
+	acquired, err := rl.each(func(lock *RedisLock) (bool, error) {
+		token, err := lock.acquire(ctx)
+		return token > 0, err
+	})
+
+	lease := time.Duration(rl.locks[0].leaseMillis()) * time.Millisecond
+	drift := time.Duration(float64(lease)*clockDriftFactor) + clockDriftMin
+	if acquired < rl.quorum || timex.Since(start)+drift >= lease {
+		// release the nodes that were acquired, with a fresh context
+		// in case that ctx is timed out.
+		_, _ = rl.each(func(lock *RedisLock) (bool, error) {
+			return lock.release(context.Background())
+		})
+		if acquired < rl.quorum {
+			return false, err
+		}
+
+		return false, nil
+	}
+
+	rl.lock.Lock()
+	if rl.locks[0].reentrant {
+		rl.holds++
+	} else {
+		rl.holds = 1
+	}
+	if rl.watchdog && (rl.dog == nil || rl.dog.exited()) {
+		rl.dog = startWatchdog(rl.locks[0].key, lease, rl.refresh)
+	}
+	rl.lock.Unlock()
+
+	return true, nil
+}
+
+// Lock acquires the lock, blocks until acquired.
+func (rl *RedLock) Lock() error {
+	return rl.LockCtx(context.Background())
+}
+
+// LockCtx acquires the lock, blocks until acquired or ctx is done.
+func (rl *RedLock) LockCtx(ctx context.Context) error {
+	return retryLock(ctx, rl.AcquireCtx)
+}
+
+// Release releases the lock.
+func (rl *RedLock) Release() (bool, error) {
+	return rl.ReleaseCtx(context.Background())
+}
+
+// ReleaseCtx releases the lock with the given ctx, returns true if released on the majority.
+// For reentrant locks, it releases one hold.
+func (rl *RedLock) ReleaseCtx(ctx context.Context) (bool, error) {
+	released, err := rl.each(func(lock *RedisLock) (bool, error) {
+		return lock.release(ctx)
+	})
+	ok := released >= rl.quorum
+
+	rl.lock.Lock()
+	if ok && rl.holds > 1 {
+		rl.holds--
+	} else {
+		rl.holds = 0
+		if rl.dog != nil {
+			rl.dog.stop()
+			rl.dog = nil
+		}
+	}
+	rl.lock.Unlock()
+
+	if !ok && err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
+
+// SetExpire sets the expiration.
+func (rl *RedLock) SetExpire(seconds int) {
+	for _, lock := range rl.locks {
+		lock.SetExpire(seconds)
+	}
+}
+
+// each calls fn on all the nodes concurrently, returns the number of the nodes that succeeded.
+func (rl *RedLock) each(fn func(lock *RedisLock) (bool, error)) (int, error) {
+	var succeeded atomic.Int32
+	var be errorx.BatchError
+	group := threading.NewRoutineGroup()
+	for _, lock := range rl.locks {
+		lock := lock
+		group.RunSafe(func() {
+			ok, err := fn(lock)
+			if err != nil {
+				be.Add(err)
+				return
+			}
+			if ok {
+				succeeded.Add(1)
+			}
+		})
+	}
+	group.Wait()
+
+	return int(succeeded.Load()), be.Err()
+}
+
+func (rl *RedLock) refresh(ctx context.Context) (bool, error) {
+	refreshed, err := rl.each(func(lock *RedisLock) (bool, error) {
+		return lock.refresh(ctx)
+	})
+	if refreshed >= rl.quorum {
+		return true, nil
+	}
+
+	return false, err
+}
@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedLockConf(t *testing.T) {
+	assert.Equal(t, ErrEmptyRedLockNodes, RedLockConf{}.Validate())
+	assert.Equal(t, ErrEmptyHost, RedLockConf{{Type: NodeType}}.Validate())
+	_, err := RedLockConf{}.NewRedLock("foo")
+	assert.Equal(t, ErrEmptyRedLockNodes, err)
+
+	_, err = RedLockConf{{
+		Host:        "localhost:0",
+		Type:        NodeType,
+		PingTimeout: time.Millisecond * 100,
+	}}.NewRedLock("foo")
+	assert.Error(t, err)
+
+	_, err = NewRedLock(nil, "foo").Acquire()
+	assert.Equal(t, ErrEmptyRedLockNodes, err)
+}
+
+func TestRedLock(t *testing.T) {
+	nodes := []*miniredis.Miniredis{miniredis.RunT(t), miniredis.RunT(t), miniredis.RunT(t)}
+	var c RedLockConf
+	for _, node := range nodes {
+		c = append(c, RedisConf{
+			Host:     node.Addr(),
+			Type:     NodeType,
+			NonBlock: true,
+		})
+	}
+
+	first, err := c.NewRedLock("key")
+	assert.NoError(t, err)
+	first.SetExpire(5)
+	second, err := c.NewRedLock("key")
+	assert.NoError(t, err)
+	second.SetExpire(5)
+
+	ok, err := first.Acquire()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	for _, node := range nodes {
+		assert.True(t, node.Exists("key"))
+	}
+	ok, err = second.Acquire()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	// the failed acquisition keeps the lock of others.
+	val, err := nodes[0].Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, first.locks[0].id, val)
+
+	ok, err = first.Release()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// acquired on the majority.
+	nodes[2].Close()
+	assert.NoError(t, second.LockCtx(context.Background()))
+	ok, err = second.Release()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// not acquired on the minority.
+	nodes[1].Close()
+	ok, err = first.Acquire()
+	assert.Error(t, err)
+	assert.False(t, ok)
+	assert.False(t, nodes[0].Exists("key"))
+}
+
+func TestRedLock_MinorityHeld(t *testing.T) {
+	nodes := []*miniredis.Miniredis{miniredis.RunT(t), miniredis.RunT(t), miniredis.RunT(t)}
+	var stores []*Redis
+	for _, node := range nodes {
+		stores = append(stores, New(node.Addr()))
+	}
+	assert.NoError(t, nodes[1].Set("key", "other"))
+	assert.NoError(t, nodes[2].Set("key", "other"))
+
+	lock := NewRedLock(stores, "key")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	assert.ErrorIs(t, lock.LockCtx(ctx), context.DeadlineExceeded)
+	assert.False(t, nodes[0].Exists("key"))
+}
+
+func TestRedLock_ReentrantWatchdog(t *testing.T) {
+	nodes := []*miniredis.Miniredis{miniredis.RunT(t), miniredis.RunT(t), miniredis.RunT(t)}
+	var stores []*Redis
+	for _, node := range nodes {
+		stores = append(stores, New(node.Addr()))
+	}
+
+	outer := NewRedLock(stores, "key", WithLockOwner("owner"), WithWatchdog())
+	outer.SetExpire(1)
+	inner := NewRedLock(stores, "key", WithLockOwner("owner"))
+	inner.SetExpire(1)
+	assert.NoError(t, outer.Lock())
+	assert.NoError(t, inner.Lock())
+	ok, err := inner.Release()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	for _, node := range nodes {
+		node.SetTTL("key", time.Millisecond*100)
+	}
+	assert.Eventually(t, func() bool {
+		for _, node := range nodes {
+			if node.TTL("key") < time.Second {
+				return false
+			}
+		}
+		return true
+	}, time.Second*2, time.Millisecond*10)
+
+	ok, err = outer.Release()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	for _, node := range nodes {
+		assert.False(t, node.Exists("key"))
+	}
+}
@@ -0,0 +1,5 @@
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+    return 0
+end
+redis.call("PEXPIRE", KEYS[2], ARGV[2])
+return redis.call("PEXPIRE", KEYS[1], ARGV[2])
\ No newline at end of file
@@ -0,0 +1,327 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/proc"
+	"github.com/r27153733/fastgozero/core/syncx"
+	"github.com/r27153733/fastgozero/core/sysx"
+	"github.com/r27153733/fastgozero/core/threading"
+	"github.com/r27153733/fastgozero/core/timex"
+)
+
+const (
+	busyGroupPrefix    = "BUSYGROUP"
+	streamHistoryStart = "0"
+	streamNewMessages  = ">"
+	streamRetryDelay   = time.Second
+)
+
+var (
+	// ErrEmptyStream is an error that indicates no stream is set.
+	ErrEmptyStream = errors.New("empty redis stream")
+	// ErrEmptyGroup is an error that indicates no consumer group is set.
+	ErrEmptyGroup = errors.New("empty redis consumer group")
+)
+
+type (
+	// A StreamWorkerConf is the config of a StreamWorker.
+	StreamWorkerConf struct {
+		Stream string
+		Group  string
+		// Consumer is the consumer name in the group, hostname-pid by default.
+		Consumer string `json:",optional"`
+		// Start is the id to consume from on creating the group, $ means the new messages.
+		Start string `json:",default=$"`
+		// Workers is the number of the messages that are handled concurrently,
+		// the reading is paused if all the workers are busy.
+		Workers   int   `json:",default=8,range=[1:]"`
+		BatchSize int64 `json:",default=10,range=[1:]"`
+		// Block is the max time to block on reading, up to 5s.
+		Block time.Duration `json:",default=5s"`
+		// ClaimIdle is the idle time of the pending messages to be reclaimed,
+		// which should be longer than the handling time of a message.
+		ClaimIdle     time.Duration `json:",default=1m"`
+		ClaimInterval time.Duration `json:",default=30s"`
+	}
+
+	// StreamHandler handles a message, the message is acknowledged if nil returned,
+	// otherwise, it's retried after ClaimIdle.
+	StreamHandler func(ctx context.Context, msg XMessage) error
+
+	// A StreamWorker consumes a stream as a consumer of a consumer group.
+	// The messages are delivered at least once.
+	StreamWorker struct {
+		rds      *Redis
+		c        StreamWorkerConf
+		handler  StreamHandler
+		msgs     chan XMessage
+		ctx      context.Context
+		cancel   context.CancelFunc
+		started  *syncx.AtomicBool
+		stopOnce sync.Once
+		done     chan struct{}
+	}
+)
+
+// Validate validates the StreamWorkerConf.
+func (c StreamWorkerConf) Validate() error {
+	if len(c.Stream) == 0 {
+		return ErrEmptyStream
+	}
+
+	if len(c.Group) == 0 {
+		return ErrEmptyGroup
+	}
+
+	return nil
+}
+
+// MustNewStreamWorker returns a StreamWorker, exits on errors.
+func MustNewStreamWorker(rds *Redis, c StreamWorkerConf, handler StreamHandler) *StreamWorker {
+	w, err := NewStreamWorker(rds, c, handler)
+	logx.Must(err)
+	return w
+}
+
+// NewStreamWorker returns a StreamWorker, the consumer group is created if not exists.
+func NewStreamWorker(rds *Redis, c StreamWorkerConf, handler StreamHandler) (*StreamWorker, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(c.Consumer) == 0 {
+		c.Consumer = fmt.Sprintf("%s-%d", sysx.Hostname(), os.Getpid())
+	}
+	if len(c.Start) == 0 {
+		c.Start = "$"
+	}
+	c.Workers = max(c.Workers, 1)
+	c.BatchSize = max(c.BatchSize, 1)
+	if c.Block <= 0 || c.Block > blockingQueryTimeout {
+		c.Block = blockingQueryTimeout
+	}
+
+	_, err := rds.XGroupCreateMkStream(c.Stream, c.Group, c.Start)
+	if err != nil && !strings.HasPrefix(err.Error(), busyGroupPrefix) {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &StreamWorker{
+		rds:     rds,
+		c:       c,
+		handler: handler,
+		msgs:    make(chan XMessage, c.Workers),
+		ctx:     ctx,
+		cancel:  cancel,
+		started: syncx.NewAtomicBool(),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Start starts consuming, it blocks until stopped, either by Stop or on process shutdown.
+func (w *StreamWorker) Start() {
+	if !w.started.CompareAndSwap(false, true) {
+		return
+	}
+	defer close(w.done)
+
+	proc.AddShutdownListener(w.Stop)
+
+	node, err := CreateBlockingNode(w.rds)
+	if err != nil {
+		logx.Errorf("redis stream worker: failed to create blocking node, error: %v", err)
+		return
+	}
+	defer node.Close()
+
+	var workers sync.WaitGroup
+	for i := 0; i < w.c.Workers; i++ {
+		workers.Add(1)
+		threading.GoSafe(func() {
+			defer workers.Done()
+			for msg := range w.msgs {
+				w.handle(msg)
+			}
+		})
+	}
+
+	var producers sync.WaitGroup
+	producers.Add(1)
+	threading.GoSafe(func() {
+		defer producers.Done()
+		w.claimLoop()
+	})
+	w.readLoop(node)
+	producers.Wait()
+
+	// the read messages are drained before returning.
+	close(w.msgs)
+	workers.Wait()
+}
+
+// Stop stops consuming, and waits for the handling messages to finish.
+func (w *StreamWorker) Stop() {
+	w.stopOnce.Do(func() {
+		w.cancel()
+		if w.started.True() {
+			<-w.done
+		}
+	})
+}
+
+func (w *StreamWorker) claim() {
+	pending, err := w.rds.XPendingCtx(w.ctx, w.c.Stream, w.c.Group, w.c.ClaimIdle, w.c.BatchSize)
+	if errors.Is(err, Nil) {
+		return
+	}
+	if err != nil {
+		if w.ctx.Err() == nil {
+			logx.Errorf("redis stream worker: failed to list pending messages of %s, error: %v",
+				w.c.Stream, err)
+		}
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(pending))
+	for _, p := range pending {
+		ids = append(ids, p.ID)
+	}
+
+	msgs, err := w.rds.XClaimCtx(w.ctx, w.c.Stream, w.c.Group, w.c.Consumer, w.c.ClaimIdle, ids...)
+	if err != nil {
+		if w.ctx.Err() == nil {
+			logx.Errorf("redis stream worker: failed to claim pending messages of %s, error: %v",
+				w.c.Stream, err)
+		}
+		return
+	}
+
+	for _, msg := range msgs {
+		if !w.push(msg) {
+			return
+		}
+	}
+}
+
+func (w *StreamWorker) claimLoop() {
+	ticker := timex.NewTicker(w.c.ClaimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.Chan():
+			w.claim()
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *StreamWorker) handle(msg XMessage) {
+	ctx := context.Background()
+	if err := w.safeHandle(ctx, msg); err != nil {
+		logx.WithContext(ctx).Errorf("redis stream worker: failed to handle message %s of %s, error: %v",
+			msg.ID, w.c.Stream, err)
+		return
+	}
+
+	w.ack(ctx, msg.ID)
+}
+
+func (w *StreamWorker) ack(ctx context.Context, id string) {
+	if _, err := w.rds.XAckCtx(ctx, w.c.Stream, w.c.Group, id); err != nil {
+		logx.WithContext(ctx).Errorf("redis stream worker: failed to ack message %s of %s, error: %v",
+			id, w.c.Stream, err)
+	}
+}
+
+// push pushes msg to the workers, blocks if all the workers are busy, returns false if stopped.
+func (w *StreamWorker) push(msg XMessage) bool {
+	select {
+	case w.msgs <- msg:
+		return true
+	case <-w.ctx.Done():
+		return false
+	}
+}
+
+// readLoop reads the pending messages of this consumer first,
+// which were read before restarting, then the new messages.
+func (w *StreamWorker) readLoop(node RedisNode) {
+	id := streamHistoryStart
+	for w.ctx.Err() == nil {
+		block := w.c.Block
+		if id != streamNewMessages {
+			// reading the history never blocks.
+			block = -1
+		}
+
+		streams, err := w.rds.XReadGroupCtx(w.ctx, node, w.c.Group, w.c.Consumer, w.c.BatchSize,
+			block, false, w.c.Stream, id)
+		if errors.Is(err, Nil) {
+			continue
+		}
+		if err != nil {
+			if w.ctx.Err() != nil {
+				return
+			}
+
+			logx.Errorf("redis stream worker: failed to read %s, error: %v", w.c.Stream, err)
+			w.wait(streamRetryDelay)
+			continue
+		}
+
+		var read int
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				read++
+				if id != streamNewMessages {
+					id = msg.ID
+				}
+				// the deleted messages in the history have no values.
+				if msg.Values == nil {
+					w.ack(w.ctx, msg.ID)
+					continue
+				}
+				if !w.push(msg) {
+					return
+				}
+			}
+		}
+		if read == 0 && id != streamNewMessages {
+			id = streamNewMessages
+		}
+	}
+}
+
+func (w *StreamWorker) safeHandle(ctx context.Context, msg XMessage) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return w.handler(ctx, msg)
+}
+
+func (w *StreamWorker) wait(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-w.ctx.Done():
+	}
+}
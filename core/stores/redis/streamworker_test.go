@@ -0,0 +1,191 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamWorkerConf_Validate(t *testing.T) {
+	assert.Equal(t, ErrEmptyStream, StreamWorkerConf{}.Validate())
+	assert.Equal(t, ErrEmptyGroup, StreamWorkerConf{Stream: "foo"}.Validate())
+	assert.NoError(t, StreamWorkerConf{Stream: "foo", Group: "bar"}.Validate())
+
+	_, err := NewStreamWorker(New("localhost:6379"), StreamWorkerConf{}, nil)
+	assert.Equal(t, ErrEmptyStream, err)
+	_, err = NewStreamWorker(New("localhost:6379", badType()), StreamWorkerConf{
+		Stream: "foo",
+		Group:  "bar",
+	}, nil)
+	assert.Error(t, err)
+}
+
+func TestStreamWorker(t *testing.T) {
+	r := miniredis.RunT(t)
+	rds := New(r.Addr())
+	c := StreamWorkerConf{
+		Stream:        "events",
+		Group:         "group",
+		Start:         "0",
+		Workers:       2,
+		BatchSize:     2,
+		Block:         time.Millisecond * 100,
+		ClaimIdle:     time.Minute,
+		ClaimInterval: time.Minute,
+	}
+
+	_, err := rds.XAdd("events", false, "*", map[string]any{"n": "0"})
+	assert.NoError(t, err)
+
+	var lock sync.Mutex
+	var handled []string
+	var failed int32
+	w := MustNewStreamWorker(rds, c, func(ctx context.Context, msg XMessage) error {
+		n := msg.Values["n"].(string)
+		if n == "3" && atomic.AddInt32(&failed, 1) == 1 {
+			return errors.New("fail once")
+		}
+		if n == "4" {
+			panic("panic")
+		}
+
+		lock.Lock()
+		handled = append(handled, n)
+		lock.Unlock()
+		return nil
+	})
+	// the existing group is reused.
+	_, err = NewStreamWorker(rds, c, nil)
+	assert.NoError(t, err)
+
+	started := make(chan struct{})
+	go func() {
+		close(started)
+		w.Start()
+	}()
+	<-started
+
+	for _, n := range []string{"1", "2", "3", "4"} {
+		_, err = rds.XAdd("events", false, "*", map[string]any{"n": n})
+		assert.NoError(t, err)
+	}
+
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(handled) == 3
+	}, time.Second*5, time.Millisecond*10)
+	w.Stop()
+	assert.ElementsMatch(t, []string{"0", "1", "2"}, handled)
+
+	// the failed messages are left pending.
+	pending, err := rds.XPending("events", "group", 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+}
+
+func TestStreamWorker_Claim(t *testing.T) {
+	r := miniredis.RunT(t)
+	rds := New(r.Addr())
+	c := StreamWorkerConf{
+		Stream:        "events",
+		Group:         "group",
+		Consumer:      "worker",
+		Workers:       1,
+		BatchSize:     10,
+		Block:         time.Millisecond * 100,
+		ClaimIdle:     time.Millisecond,
+		ClaimInterval: time.Millisecond * 50,
+	}
+
+	handled := make(chan string, 10)
+	w := MustNewStreamWorker(rds, c, func(ctx context.Context, msg XMessage) error {
+		handled <- msg.Values["n"].(string)
+		return nil
+	})
+
+	// read by another consumer, but never acked.
+	_, err := rds.XAdd("events", false, "*", map[string]any{"n": "1"})
+	assert.NoError(t, err)
+	node, err := CreateBlockingNode(rds)
+	assert.NoError(t, err)
+	defer node.Close()
+	streams, err := rds.XReadGroup(node, "group", "crashed", 10, -1, false, "events", ">")
+	assert.NoError(t, err)
+	assert.Len(t, streams, 1)
+	time.Sleep(time.Millisecond * 10)
+
+	go w.Start()
+	defer w.Stop()
+
+	select {
+	case n := <-handled:
+		assert.Equal(t, "1", n)
+	case <-time.After(time.Second * 5):
+		t.Fatal("pending message not reclaimed")
+	}
+
+	assert.Eventually(t, func() bool {
+		pending, err := rds.XPending("events", "group", 0, 10)
+		// an empty pending list might be returned as redis.Nil.
+		return (err == nil || errors.Is(err, Nil)) && len(pending) == 0
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestStreamWorker_StopBeforeStart(t *testing.T) {
+	r := miniredis.RunT(t)
+	w := MustNewStreamWorker(New(r.Addr()), StreamWorkerConf{
+		Stream: "events",
+		Group:  "group",
+	}, func(ctx context.Context, msg XMessage) error {
+		return nil
+	})
+	w.Stop()
+	w.Start()
+}
+
+func TestRedis_Streams(t *testing.T) {
+	runOnRedis(t, func(client *Redis) {
+		bad := newRedis(client.Addr, badType())
+		_, err := bad.XAdd("s", false, "*", []string{"k", "v"})
+		assert.Error(t, err)
+		_, err = bad.XAck("s", "g", "0-1")
+		assert.Error(t, err)
+		_, err = bad.XClaim("s", "g", "c", 0, "0-1")
+		assert.Error(t, err)
+		_, err = bad.XGroupCreate("s", "g", "0")
+		assert.Error(t, err)
+		_, err = bad.XGroupCreateMkStream("s", "g", "0")
+		assert.Error(t, err)
+		_, err = bad.XPending("s", "g", 0, 10)
+		assert.Error(t, err)
+		_, err = client.XReadGroup(nil, "g", "c", 1, -1, false, "s", ">")
+		assert.Equal(t, ErrNilNode, err)
+
+		id, err := client.XAdd("s", false, "*", []string{"k", "v"})
+		assert.NoError(t, err)
+		_, err = client.XGroupCreate("s", "g", "0")
+		assert.NoError(t, err)
+		node, err := CreateBlockingNode(client)
+		assert.NoError(t, err)
+		defer node.Close()
+		streams, err := client.XReadGroup(node, "g", "c", 1, -1, false, "s", ">")
+		assert.NoError(t, err)
+		assert.Equal(t, id, streams[0].Messages[0].ID)
+		msgs, err := client.XClaim("s", "g", "d", 0, id)
+		assert.NoError(t, err)
+		assert.Len(t, msgs, 1)
+		pending, err := client.XPending("s", "g", 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "d", pending[0].Consumer)
+		acked, err := client.XAck("s", "g", id)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), acked)
+	})
+}
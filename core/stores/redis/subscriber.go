@@ -0,0 +1,188 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/threading"
+	red "github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultPingInterval = 30 * time.Second
+	resubscribeInterval = time.Second
+)
+
+var errPingTimeout = errors.New("redis subscriber: ping timeout")
+
+type (
+	// A Subscriber subscribes the channels, and resubscribes on the disconnections.
+	Subscriber struct {
+		rds                 *Redis
+		channels            []string
+		handler             func(msg *Message)
+		resubscribeListener func()
+		pingInterval        time.Duration
+		ctx                 context.Context
+		cancel              context.CancelFunc
+		pubsub              *PubSub
+		lock                sync.Mutex
+		done                chan struct{}
+	}
+
+	// SubscriberOption defines the method to customize a Subscriber.
+	SubscriberOption func(s *Subscriber)
+)
+
+// NewSubscriber returns a Subscriber that subscribes the channels on rds in background,
+// and calls handler with the messages in order.
+// The messages published while disconnected are lost, use WithResubscribeListener to resync.
+func NewSubscriber(rds *Redis, channels []string, handler func(msg *Message),
+	opts ...SubscriberOption) *Subscriber {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Subscriber{
+		rds:          rds,
+		channels:     channels,
+		handler:      handler,
+		pingInterval: defaultPingInterval,
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	threading.GoSafe(s.run)
+
+	return s
+}
+
+// WithPingInterval customizes the interval to ping redis while no messages received,
+// the subscription is considered broken if no pong received in the next interval.
+func WithPingInterval(interval time.Duration) SubscriberOption {
+	return func(s *Subscriber) {
+		s.pingInterval = interval
+	}
+}
+
+// WithResubscribeListener customizes the listener that is called on resubscribing,
+// which is not called on the first subscription.
+func WithResubscribeListener(listener func()) SubscriberOption {
+	return func(s *Subscriber) {
+		s.resubscribeListener = listener
+	}
+}
+
+// Stop stops the subscriber, and waits for the running handler to finish.
+func (s *Subscriber) Stop() {
+	s.cancel()
+
+	s.lock.Lock()
+	if s.pubsub != nil {
+		// interrupt the blocking receive.
+		_ = s.pubsub.Close()
+	}
+	s.lock.Unlock()
+
+	<-s.done
+}
+
+func (s *Subscriber) receive(pubsub *PubSub) error {
+	var pinged bool
+	for {
+		msg, err := pubsub.ReceiveTimeout(s.ctx, s.pingInterval)
+		if err != nil {
+			var netErr net.Error
+			if s.ctx.Err() != nil || !errors.As(err, &netErr) || !netErr.Timeout() {
+				return err
+			}
+			if pinged {
+				return errPingTimeout
+			}
+
+			if err = pubsub.Ping(s.ctx); err != nil {
+				return err
+			}
+			pinged = true
+			continue
+		}
+
+		pinged = false
+		if m, ok := msg.(*red.Message); ok {
+			threading.RunSafe(func() {
+				s.handler(m)
+			})
+		}
+	}
+}
+
+func (s *Subscriber) run() {
+	defer close(s.done)
+
+	var subscribed bool
+	for {
+		pubsub, err := s.rds.SubscribeCtx(s.ctx, s.channels...)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+
+			logx.Errorf("redis subscriber: failed to subscribe %q on %s, error: %v",
+				s.channels, s.rds.Addr, err)
+			if !s.wait(resubscribeInterval) {
+				return
+			}
+			continue
+		}
+
+		if !s.setPubSub(pubsub) {
+			_ = pubsub.Close()
+			return
+		}
+
+		if subscribed && s.resubscribeListener != nil {
+			threading.RunSafe(s.resubscribeListener)
+		}
+		subscribed = true
+
+		err = s.receive(pubsub)
+		s.setPubSub(nil)
+		_ = pubsub.Close()
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		logx.Errorf("redis subscriber: lost subscription of %q on %s, error: %v",
+			s.channels, s.rds.Addr, err)
+	}
+}
+
+// setPubSub sets the current pubsub, returns false if stopped.
+func (s *Subscriber) setPubSub(pubsub *PubSub) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.ctx.Err() != nil {
+		return false
+	}
+
+	s.pubsub = pubsub
+	return true
+}
+
+func (s *Subscriber) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
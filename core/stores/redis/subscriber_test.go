@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriber(t *testing.T) {
+	r := miniredis.RunT(t)
+	rds := New(r.Addr())
+	msgs := make(chan *Message, 10)
+	resubscribed := make(chan struct{}, 10)
+	sub := NewSubscriber(rds, []string{"foo", "bar"}, func(msg *Message) {
+		msgs <- msg
+	}, WithResubscribeListener(func() {
+		resubscribed <- struct{}{}
+	}), WithPingInterval(time.Millisecond*100))
+	defer sub.Stop()
+
+	waitSubscribed(t, r, "foo")
+	_, err := rds.Publish("foo", "hello")
+	assert.NoError(t, err)
+	_, err = rds.Publish("bar", "world")
+	assert.NoError(t, err)
+	assertMessage(t, msgs, "foo", "hello")
+	assertMessage(t, msgs, "bar", "world")
+	assert.Empty(t, resubscribed)
+
+	// resubscribe on reconnection.
+	r.Close()
+	assert.NoError(t, r.Restart())
+	select {
+	case <-resubscribed:
+	case <-time.After(time.Second * 5):
+		t.Fatal("not resubscribed")
+	}
+	waitSubscribed(t, r, "foo")
+	// kept alive by the pings while idle.
+	time.Sleep(time.Millisecond * 300)
+	assert.Empty(t, resubscribed)
+	_, err = rds.Publish("foo", "again")
+	assert.NoError(t, err)
+	assertMessage(t, msgs, "foo", "again")
+}
+
+func TestSubscriber_PanicHandler(t *testing.T) {
+	r := miniredis.RunT(t)
+	rds := New(r.Addr())
+	msgs := make(chan *Message, 10)
+	sub := NewSubscriber(rds, []string{"foo"}, func(msg *Message) {
+		if msg.Payload == "panic" {
+			panic(msg.Payload)
+		}
+		msgs <- msg
+	})
+	defer sub.Stop()
+
+	waitSubscribed(t, r, "foo")
+	_, err := rds.Publish("foo", "panic")
+	assert.NoError(t, err)
+	_, err = rds.Publish("foo", "hello")
+	assert.NoError(t, err)
+	assertMessage(t, msgs, "foo", "hello")
+}
+
+func TestSubscriber_Stop(t *testing.T) {
+	r := miniredis.RunT(t)
+	rds := New(r.Addr())
+	sub := NewSubscriber(rds, []string{"foo"}, func(msg *Message) {})
+	waitSubscribed(t, r, "foo")
+	sub.Stop()
+	assert.Eventually(t, func() bool {
+		return r.PubSubNumSub("foo")["foo"] == 0
+	}, time.Second, time.Millisecond*10)
+
+	// stop while failing to subscribe.
+	sub = NewSubscriber(New(r.Addr(), badType()), []string{"foo"}, func(msg *Message) {})
+	sub.Stop()
+}
+
+func assertMessage(t *testing.T, msgs <-chan *Message, channel, payload string) {
+	select {
+	case msg := <-msgs:
+		assert.Equal(t, channel, msg.Channel)
+		assert.Equal(t, payload, msg.Payload)
+	case <-time.After(time.Second):
+		t.Fatalf("message %q not received", payload)
+	}
+}
+
+func waitSubscribed(t *testing.T, r *miniredis.Miniredis, channel string) {
+	assert.Eventually(t, func() bool {
+		return r.PubSubNumSub(channel)[channel] > 0
+	}, time.Second*5, time.Millisecond*10)
+}
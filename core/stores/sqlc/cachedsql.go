@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/r27153733/fastgozero/core/stores/builder"
 	"github.com/r27153733/fastgozero/core/stores/cache"
 	"github.com/r27153733/fastgozero/core/stores/redis"
 	"github.com/r27153733/fastgozero/core/stores/sqlx"
@@ -110,6 +111,14 @@ func (cc CachedConn) ExecCtx(ctx context.Context, exec ExecCtxFn, keys ...string
 	return res, cc.DelCacheCtx(ctx, keys...)
 }
 
+// ExecBuilderCtx runs the statement built by b, and deletes cache with given keys if succeeded.
+func (cc CachedConn) ExecBuilderCtx(ctx context.Context, b sqlx.QueryBuilder, keys ...string) (
+	sql.Result, error) {
+	return cc.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		return sqlx.ExecBuilderCtx(ctx, conn, b)
+	}, keys...)
+}
+
 // ExecNoCache runs exec with given sql statement, without affecting cache.
 func (cc CachedConn) ExecNoCache(q string, args ...any) (sql.Result, error) {
 	return cc.ExecNoCacheCtx(context.Background(), q, args...)
@@ -190,6 +199,11 @@ func (cc CachedConn) QueryRowNoCacheCtx(ctx context.Context, v any, q string,
 	return cc.db.QueryRowCtx(ctx, v, q, args...)
 }
 
+// QueryRowNoCacheBuilderCtx unmarshals into v with the statement built by b.
+func (cc CachedConn) QueryRowNoCacheBuilderCtx(ctx context.Context, v any, b sqlx.QueryBuilder) error {
+	return sqlx.QueryRowBuilderCtx(ctx, cc.db, v, b)
+}
+
 // QueryRowPartialNoCache unmarshals into v with given statement.
 func (cc CachedConn) QueryRowPartialNoCache(v any, q string, args ...any) error {
 	return cc.QueryRowPartialNoCacheCtx(context.Background(), v, q, args...)
@@ -214,6 +228,19 @@ func (cc CachedConn) QueryRowsNoCacheCtx(ctx context.Context, v any, q string,
 	return cc.db.QueryRowsCtx(ctx, v, q, args...)
 }
 
+// QueryPageNoCacheCtx queries a page of rows after cursor into v with the keyset pagination,
+// and returns the cursor of the next page. It doesn't use cache.
+func (cc CachedConn) QueryPageNoCacheCtx(ctx context.Context, v any, sb *builder.SelectBuilder,
+	keyset builder.Keyset, cursor string, size int64) (string, error) {
+	return sqlx.QueryPageCtx(ctx, cc.db, v, sb, keyset, cursor, size)
+}
+
+// QueryRowsNoCacheBuilderCtx unmarshals into v with the statement built by b.
+// It doesn't use cache, because it might cause consistency problem.
+func (cc CachedConn) QueryRowsNoCacheBuilderCtx(ctx context.Context, v any, b sqlx.QueryBuilder) error {
+	return sqlx.QueryRowsBuilderCtx(ctx, cc.db, v, b)
+}
+
 // QueryRowsPartialNoCache unmarshals into v with given statement.
 // It doesn't use cache, because it might cause consistency problem.
 func (cc CachedConn) QueryRowsPartialNoCache(v any, q string, args ...any) error {
@@ -20,6 +20,7 @@ import (
 	"github.com/r27153733/fastgozero/core/fx"
 	"github.com/r27153733/fastgozero/core/logx"
 	"github.com/r27153733/fastgozero/core/stat"
+	"github.com/r27153733/fastgozero/core/stores/builder"
 	"github.com/r27153733/fastgozero/core/stores/cache"
 	"github.com/r27153733/fastgozero/core/stores/dbtest"
 	"github.com/r27153733/fastgozero/core/stores/redis"
@@ -116,6 +117,58 @@ func TestCachedConn_QueryRowIndex_NoCache(t *testing.T) {
 	assert.Equal(t, `"zero"`, val)
 }
 
+func TestCachedConn_QueryRowIndex_LocalCache(t *testing.T) {
+	resetStats()
+	r := redistest.CreateRedis(t)
+
+	c := NewConn(dummySqlConn{}, cache.CacheConf{
+		{
+			RedisConf: redis.RedisConf{
+				Host: r.Addr,
+				Type: redis.NodeType,
+			},
+			Weight: 100,
+		},
+	}, cache.WithExpiry(time.Second*10), cache.WithLocalCache(100, time.Minute))
+
+	var queries int
+	queryRowIndex := func() (string, error) {
+		var str string
+		err := c.QueryRowIndex(&str, "index", func(s any) string {
+			return fmt.Sprintf("%s/1234", s)
+		}, func(conn sqlx.SqlConn, v any) (any, error) {
+			queries++
+			*v.(*string) = "zero"
+			return "primary", nil
+		}, func(conn sqlx.SqlConn, v, pri any) error {
+			queries++
+			*v.(*string) = "xin"
+			return nil
+		})
+		return str, err
+	}
+
+	str, err := queryRowIndex()
+	assert.Nil(t, err)
+	assert.Equal(t, "zero", str)
+	assert.Equal(t, 1, queries)
+
+	// served by the local cache without the redis round trips.
+	_, err = r.Del("index", "primary/1234")
+	assert.Nil(t, err)
+	str, err = queryRowIndex()
+	assert.Nil(t, err)
+	assert.Equal(t, "zero", str)
+	assert.Equal(t, 1, queries)
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&stats.LocalHit))
+
+	assert.Nil(t, c.DelCache("index", "primary/1234"))
+	str, err = queryRowIndex()
+	assert.Nil(t, err)
+	assert.Equal(t, "zero", str)
+	assert.Equal(t, 2, queries)
+}
+
 func TestCachedConn_QueryRowIndex_HasCache(t *testing.T) {
 	resetStats()
 	r := redistest.CreateRedis(t)
@@ -755,6 +808,8 @@ func resetStats() {
 	atomic.StoreUint64(&stats.Hit, 0)
 	atomic.StoreUint64(&stats.Miss, 0)
 	atomic.StoreUint64(&stats.DbFails, 0)
+	atomic.StoreUint64(&stats.LocalHit, 0)
+	atomic.StoreUint64(&stats.LocalMiss, 0)
 }
 
 type dummySqlConn struct {
@@ -872,3 +927,50 @@ func (c *trackedConn) TransactCtx(ctx context.Context, fn func(context.Context,
 	c.transactValue = true
 	return c.dummySqlConn.TransactCtx(ctx, fn)
 }
+
+func TestCachedConn_Builder(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		r := redistest.CreateRedis(t)
+		c := NewNodeConn(sqlx.NewSqlConnFromDB(db), r, cache.WithExpiry(time.Second*30))
+		assert.NoError(t, c.SetCache("user", "any"))
+
+		mock.ExpectExec("delete from users where id = \\?").WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		_, err := c.ExecBuilderCtx(context.Background(),
+			builder.Delete("users").Where(builder.Eq("id", 1)), "user")
+		assert.NoError(t, err)
+		var val string
+		assert.Equal(t, ErrNotFound, c.GetCache("user", &val))
+
+		mock.ExpectQuery("select name from users where id = \\?").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a"))
+		assert.NoError(t, c.QueryRowNoCacheBuilderCtx(context.Background(), &val,
+			builder.Select("name").From("users").Where(builder.Eq("id", 1))))
+		assert.Equal(t, "a", val)
+
+		mock.ExpectQuery("select name from users order by id").
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a").AddRow("b"))
+		var vals []string
+		assert.NoError(t, c.QueryRowsNoCacheBuilderCtx(context.Background(), &vals,
+			builder.Select("name").From("users").OrderBy("id")))
+		assert.Equal(t, []string{"a", "b"}, vals)
+	})
+}
+
+func TestCachedConn_QueryPageNoCacheCtx(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		r := redistest.CreateRedis(t)
+		c := NewNodeConn(sqlx.NewSqlConnFromDB(db), r, cache.WithExpiry(time.Second*30))
+		mock.ExpectQuery("select id from users order by id limit 2").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		var rows []struct {
+			ID int64 `db:"id"`
+		}
+		next, err := c.QueryPageNoCacheCtx(context.Background(), &rows, builder.Select("id").From("users"),
+			builder.Keyset{Columns: []string{"id"}}, "", 1)
+		assert.NoError(t, err)
+		assert.Empty(t, next)
+		assert.Len(t, rows, 1)
+	})
+}
@@ -0,0 +1,83 @@
+package sqlx
+
+import "time"
+
+const (
+	// RoundRobinPolicy picks the replicas in turn.
+	RoundRobinPolicy = "round-robin"
+	// RandomPolicy picks the replicas randomly.
+	RandomPolicy = "random"
+)
+
+// SqlConf is the config of a sql connection, the reads are routed to the replicas if any.
+type SqlConf struct {
+	DataSource string
+	DriverName string   `json:",default=mysql"`
+	Replicas   []string `json:",optional"`
+	Policy     string   `json:",default=round-robin,options=round-robin|random"`
+	// ReadPrimaryAfterWrite is the time that the reads go to the primary after a write
+	// in the same trace, which should be longer than the replication lag.
+	// The writes without traces are not tracked, see WithReadYourWrites.
+	ReadPrimaryAfterWrite time.Duration `json:",default=1s"`
+}
+
+// MustNewConn returns a SqlConn with the given config, exits on any error.
+func MustNewConn(c SqlConf, opts ...SqlOption) SqlConn {
+	conn, err := NewConn(c, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return conn
+}
+
+// NewConn returns a SqlConn with the given config, the writes go to the primary,
+// and the reads go to the replicas unless forced to the primary.
+func NewConn(c SqlConf, opts ...SqlOption) (SqlConn, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if c.DriverName == mysqlDriverName {
+		opts = append([]SqlOption{withMysqlAcceptable()}, opts...)
+	}
+
+	primary := newCommonSqlConn(c.DriverName, c.DataSource, opts...)
+	if len(c.Replicas) == 0 {
+		return primary, nil
+	}
+
+	replicas := make([]*replicaConn, 0, len(c.Replicas))
+	for _, datasource := range c.Replicas {
+		replicas = append(replicas, &replicaConn{
+			commonSqlConn: newCommonSqlConn(c.DriverName, datasource, opts...),
+			name:          desensitize(datasource),
+		})
+	}
+
+	return newRwSqlConn(primary, replicas, c.Policy, c.ReadPrimaryAfterWrite), nil
+}
+
+// Validate validates the config.
+func (c SqlConf) Validate() error {
+	if len(c.DataSource) == 0 {
+		return errEmptyDatasource
+	}
+
+	if len(c.DriverName) == 0 {
+		return errEmptyDriverName
+	}
+
+	for _, replica := range c.Replicas {
+		if len(replica) == 0 {
+			return errEmptyDatasource
+		}
+	}
+
+	switch c.Policy {
+	case "", RoundRobinPolicy, RandomPolicy:
+		return nil
+	default:
+		return errUnknownPolicy
+	}
+}
@@ -0,0 +1,58 @@
+package sqlx
+
+import (
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlConf_Validate(t *testing.T) {
+	assert.Equal(t, errEmptyDatasource, SqlConf{}.Validate())
+	assert.Equal(t, errEmptyDriverName, SqlConf{DataSource: "foo"}.Validate())
+	assert.Equal(t, errEmptyDatasource, SqlConf{
+		DataSource: "foo",
+		DriverName: mysqlDriverName,
+		Replicas:   []string{""},
+	}.Validate())
+	assert.Equal(t, errUnknownPolicy, SqlConf{
+		DataSource: "foo",
+		DriverName: mysqlDriverName,
+		Policy:     "foo",
+	}.Validate())
+	assert.NoError(t, SqlConf{
+		DataSource: "foo",
+		DriverName: mysqlDriverName,
+		Replicas:   []string{"bar"},
+		Policy:     RandomPolicy,
+	}.Validate())
+}
+
+func TestNewConn(t *testing.T) {
+	_, err := NewConn(SqlConf{})
+	assert.Error(t, err)
+	assert.Panics(t, func() {
+		MustNewConn(SqlConf{})
+	})
+
+	conn := MustNewConn(SqlConf{
+		DataSource: "foo",
+		DriverName: mysqlDriverName,
+	})
+	assert.IsType(t, &commonSqlConn{}, conn)
+	assert.True(t, conn.(*commonSqlConn).acceptable(&mysql.MySQLError{
+		Number: duplicateEntryCode,
+	}))
+
+	conn = MustNewConn(SqlConf{
+		DataSource: "user:pass@tcp(primary:3306)/db",
+		DriverName: mysqlDriverName,
+		Replicas:   []string{"user:pass@tcp(replica:3306)/db"},
+	})
+	if assert.IsType(t, &rwSqlConn{}, conn) {
+		replicas := conn.(*rwSqlConn).replicas
+		assert.Len(t, replicas, 1)
+		assert.Equal(t, "tcp(replica:3306)/db", replicas[0].name)
+		assert.NotEqual(t, conn.(*rwSqlConn).primary.brk, replicas[0].brk)
+	}
+}
@@ -8,7 +8,13 @@ import (
 var (
 	// ErrNotFound is an alias of sql.ErrNoRows
 	ErrNotFound = sql.ErrNoRows
+	// ErrVersionConflict is an error that indicates no rows are updated with the optimistic locking,
+	// because the version is changed by others, or the row doesn't exist.
+	ErrVersionConflict = errors.New("version conflict")
 
-	errCantNestTx    = errors.New("cannot nest transactions")
-	errNoRawDBFromTx = errors.New("cannot get raw db from transaction")
+	errCantNestTx      = errors.New("cannot nest transactions")
+	errNoRawDBFromTx   = errors.New("cannot get raw db from transaction")
+	errEmptyDatasource = errors.New("empty datasource")
+	errEmptyDriverName = errors.New("empty driver name")
+	errUnknownPolicy   = errors.New("unknown replica policy")
 )
@@ -0,0 +1,122 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+
+	"github.com/r27153733/fastgozero/core/mapping"
+)
+
+var (
+	// ErrIterUnsupported is an error that indicates the session can't iterate the rows,
+	// like the sessions not created by sqlx.
+	ErrIterUnsupported = errors.New("sqlx: session doesn't support iterating rows")
+
+	// StopIter is used as a return value from the fn of QueryRowsIterCtx
+	// to stop the iteration, and it's not returned as an error.
+	StopIter = errors.New("stop iteration")
+)
+
+// rowsQuerier is the session that can scan the rows while iterating.
+type rowsQuerier interface {
+	queryRows(ctx context.Context, scanner func(*sql.Rows) error, q string, args ...any) error
+}
+
+// QueryRowsIterCtx queries the rows with session, and calls fn with the rows one by one,
+// instead of loading all the rows into memory, which is used to export the large tables.
+// T can be a struct, a pointer to struct or a basic type.
+// The iteration stops if fn returns an error, and the rows are closed before returning.
+// ErrIterUnsupported is returned if session is not created by sqlx, instead of loading
+// all the rows into memory.
+func QueryRowsIterCtx[T any](ctx context.Context, session Session, fn func(T) error,
+	q string, args ...any) (err error) {
+	ctx, span := startSpan(ctx, "QueryRowsIter")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	querier, ok := asRowsQuerier(session)
+	if !ok {
+		return ErrIterUnsupported
+	}
+
+	var fnErr error
+	err = querier.queryRows(ctx, func(rows *sql.Rows) error {
+		return iterRows(rows, func(row T) error {
+			if e := fn(row); e != nil {
+				// the errors of fn are not the errors of the database.
+				fnErr = e
+				return newAcceptableError(e)
+			}
+
+			return nil
+		})
+	}, q, args...)
+	if fnErr != nil {
+		err = fnErr
+	}
+
+	if errors.Is(err, StopIter) {
+		return nil
+	}
+
+	return err
+}
+
+func asRowsQuerier(session Session) (rowsQuerier, bool) {
+	if conn, ok := session.(txConn); ok {
+		session = conn.Session
+	}
+
+	querier, ok := session.(rowsQuerier)
+	return querier, ok
+}
+
+func iterRows[T any](scanner rowsScanner, fn func(T) error) error {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	ptr := rt.Kind() == reflect.Ptr
+	base := mapping.Deref(rt)
+
+	var columns []string
+	switch base.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+	case reflect.Struct:
+		var err error
+		if columns, err = scanner.Columns(); err != nil {
+			return err
+		}
+	default:
+		return ErrUnsupportedValueType
+	}
+
+	for scanner.Next() {
+		value := reflect.New(base)
+		if base.Kind() == reflect.Struct {
+			values, err := mapStructFieldsIntoSlice(value, columns, true)
+			if err != nil {
+				return err
+			}
+
+			if err := scanner.Scan(values...); err != nil {
+				return err
+			}
+		} else if err := scanner.Scan(value.Interface()); err != nil {
+			return err
+		}
+
+		if !ptr {
+			value = value.Elem()
+		}
+		if err := fn(value.Interface().(T)); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,90 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/r27153733/fastgozero/core/stores/dbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+type iterUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestQueryRowsIterCtx(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		conn := NewSqlConnFromDB(db)
+		mock.ExpectQuery("select id, name from users").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b"))
+		var users []iterUser
+		assert.NoError(t, QueryRowsIterCtx(context.Background(), conn, func(user iterUser) error {
+			users = append(users, user)
+			return nil
+		}, "select id, name from users"))
+		assert.Equal(t, []iterUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, users)
+
+		mock.ExpectQuery("select id, name from users").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b")).
+			RowsWillBeClosed()
+		var ptrs []*iterUser
+		assert.NoError(t, QueryRowsIterCtx(context.Background(), conn, func(user *iterUser) error {
+			ptrs = append(ptrs, user)
+			return StopIter
+		}, "select id, name from users"))
+		assert.Equal(t, []*iterUser{{ID: 1, Name: "a"}}, ptrs)
+
+		errFn := errors.New("fn")
+		mock.ExpectQuery("select name from users").
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a").AddRow("b"))
+		var names []string
+		err := QueryRowsIterCtx(context.Background(), conn, func(name string) error {
+			names = append(names, name)
+			return errFn
+		}, "select name from users")
+		assert.Equal(t, errFn, err)
+		assert.Equal(t, []string{"a"}, names)
+
+		mock.ExpectQuery("select id from users").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		assert.Equal(t, ErrUnsupportedValueType, QueryRowsIterCtx(context.Background(), conn,
+			func(map[string]any) error {
+				return nil
+			}, "select id from users"))
+	})
+}
+
+func TestQueryRowsIterCtx_Tx(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id from users").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectCommit()
+
+		var ids []int64
+		assert.NoError(t, NewSqlConnFromDB(db).TransactCtx(context.Background(),
+			func(ctx context.Context, session Session) error {
+				return QueryRowsIterCtx(ctx, NewSqlConnFromSession(session), func(id int64) error {
+					ids = append(ids, id)
+					return nil
+				}, "select id from users")
+			}))
+		assert.Equal(t, []int64{1, 2}, ids)
+	})
+}
+
+func TestQueryRowsIterCtx_UnknownSession(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		var ids []int64
+		session := struct{ Session }{NewSqlConnFromDB(db)}
+		assert.ErrorIs(t, QueryRowsIterCtx(context.Background(), session, func(id int64) error {
+			ids = append(ids, id)
+			return nil
+		}, "select id from users"), ErrIterUnsupported)
+		assert.Empty(t, ids)
+	})
+}
@@ -33,6 +33,21 @@ var (
 		Help:      "mysql client requests slow count.",
 		Labels:    []string{"command"},
 	})
+	metricReplicaReqDur = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: namespace,
+		Subsystem: "replica",
+		Name:      "duration_ms",
+		Help:      "sql client replica requests duration(ms).",
+		Labels:    []string{"replica"},
+		Buckets:   []float64{0.25, 0.5, 1, 1.5, 2, 3, 5, 10, 25, 50, 100, 250, 500, 1000, 2000, 5000, 10000, 15000},
+	})
+	metricReplicaReqErr = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "replica",
+		Name:      "error_total",
+		Help:      "sql client replica requests error count.",
+		Labels:    []string{"replica", "error"},
+	})
 
 	connLabels                         = []string{"db_name", "hash"}
 	connCollector                      = newCollector()
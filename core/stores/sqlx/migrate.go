@@ -0,0 +1,590 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/hash"
+	"github.com/r27153733/fastgozero/core/logx"
+)
+
+const (
+	// MysqlDialect is the migration dialect of mysql.
+	MysqlDialect = "mysql"
+	// PostgresDialect is the migration dialect of postgres.
+	PostgresDialect = "postgres"
+
+	defaultMigrationTable       = "schema_migrations"
+	defaultMigrationLockTimeout = time.Minute
+	migrationLockPrefix         = "sqlx_migrate:"
+	upSuffix                    = ".up.sql"
+)
+
+var (
+	// ErrMigrationLocked is an error that indicates the migrations are being applied by others.
+	ErrMigrationLocked = errors.New("sqlx: migrations are locked by others")
+	// ErrUnknownDialect is an error that indicates the migration dialect is not supported.
+	ErrUnknownDialect = errors.New("sqlx: unknown migration dialect")
+
+	migrationNameRegex = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+)
+
+type (
+	// A Migration is a versioned schema change,
+	// which is read from the files named like 0001_create_users.up.sql and 0001_create_users.down.sql.
+	Migration struct {
+		Version int64
+		Name    string
+		Up      string
+		Down    string
+	}
+
+	// A MigrationStatus is the status of a Migration.
+	MigrationStatus struct {
+		Migration
+		Applied   bool
+		AppliedAt time.Time
+	}
+
+	// A Migrator applies and reverts the migrations on a database.
+	Migrator struct {
+		conn        SqlConn
+		migrations  []Migration
+		dialect     string
+		table       string
+		lockTimeout time.Duration
+		dryRun      bool
+	}
+
+	// MigrateOption defines the method to customize a Migrator.
+	MigrateOption func(m *Migrator)
+)
+
+// Migrate applies the pending migrations in fsys on conn.
+func Migrate(conn SqlConn, fsys fs.FS, opts ...MigrateOption) error {
+	return MigrateCtx(context.Background(), conn, fsys, opts...)
+}
+
+// MigrateCtx applies the pending migrations in fsys on conn with the given ctx.
+func MigrateCtx(ctx context.Context, conn SqlConn, fsys fs.FS, opts ...MigrateOption) error {
+	m, err := NewMigrator(conn, fsys, opts...)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.Up(ctx)
+	return err
+}
+
+// NewMigrator returns a Migrator with the migrations in the root of fsys.
+func NewMigrator(conn SqlConn, fsys fs.FS, opts ...MigrateOption) (*Migrator, error) {
+	m := &Migrator{
+		conn:        conn,
+		dialect:     MysqlDialect,
+		table:       defaultMigrationTable,
+		lockTimeout: defaultMigrationLockTimeout,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.dialect != MysqlDialect && m.dialect != PostgresDialect {
+		return nil, ErrUnknownDialect
+	}
+
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	m.migrations = migrations
+	return m, nil
+}
+
+// WithMigrationDialect customizes the dialect, MysqlDialect by default.
+func WithMigrationDialect(dialect string) MigrateOption {
+	return func(m *Migrator) {
+		m.dialect = dialect
+	}
+}
+
+// WithMigrationDryRun makes the Migrator only report the migrations to apply or revert.
+func WithMigrationDryRun() MigrateOption {
+	return func(m *Migrator) {
+		m.dryRun = true
+	}
+}
+
+// WithMigrationLockTimeout customizes the time to wait for the other replicas to finish migrating.
+func WithMigrationLockTimeout(timeout time.Duration) MigrateOption {
+	return func(m *Migrator) {
+		m.lockTimeout = timeout
+	}
+}
+
+// WithMigrationTable customizes the history table, schema_migrations by default.
+func WithMigrationTable(table string) MigrateOption {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// Down reverts the last steps applied migrations, returns the reverted ones.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if len(migration.Down) == 0 {
+				return fmt.Errorf("sqlx: migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+
+			if !m.dryRun {
+				if err := m.run(ctx, conn, migration.Down, m.deleteHistoryQuery(), migration.Version); err != nil {
+					return fmt.Errorf("sqlx: failed to revert migration %d_%s: %w",
+						migration.Version, migration.Name, err)
+				}
+				logx.Infof("sqlx: reverted migration %d_%s", migration.Version, migration.Name)
+			}
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status returns the status of all the migrations in order.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	db, err := m.conn.RawDB()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		at, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			Applied:   ok,
+			AppliedAt: at,
+		})
+	}
+
+	return statuses, nil
+}
+
+// Up applies all the pending migrations in order, returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var pending []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			if !m.dryRun {
+				if err := m.run(ctx, conn, migration.Up, m.insertHistoryQuery(),
+					migration.Version, migration.Name); err != nil {
+					return fmt.Errorf("sqlx: failed to apply migration %d_%s: %w",
+						migration.Version, migration.Name, err)
+				}
+				logx.Infof("sqlx: applied migration %d_%s", migration.Version, migration.Name)
+			}
+			pending = append(pending, migration)
+		}
+
+		return nil
+	})
+
+	return pending, err
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	applied := make(map[int64]time.Time)
+	// the history table is not created on dry-run or status.
+	exists, err := m.tableExists(ctx, conn)
+	if err != nil || !exists {
+		return applied, err
+	}
+
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("select version, applied_at from %s", m.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int64
+		var at appliedTime
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+
+		applied[version] = at.Time
+	}
+
+	return applied, rows.Err()
+}
+
+func (m *Migrator) deleteHistoryQuery() string {
+	if m.dialect == PostgresDialect {
+		return fmt.Sprintf("delete from %s where version = $1", m.table)
+	}
+
+	return fmt.Sprintf("delete from %s where version = ?", m.table)
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, fmt.Sprintf(`create table if not exists %s (
+	version bigint not null primary key,
+	name varchar(255) not null,
+	applied_at timestamp not null default current_timestamp
+)`, m.table))
+	return err
+}
+
+func (m *Migrator) insertHistoryQuery() string {
+	if m.dialect == PostgresDialect {
+		return fmt.Sprintf("insert into %s (version, name) values ($1, $2)", m.table)
+	}
+
+	return fmt.Sprintf("insert into %s (version, name) values (?, ?)", m.table)
+}
+
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) error {
+	name := migrationLockPrefix + m.table
+	if m.dialect == PostgresDialect {
+		ctx, cancel := context.WithTimeout(ctx, m.lockTimeout)
+		defer cancel()
+
+		_, err := conn.ExecContext(ctx, "select pg_advisory_lock($1)", int64(hash.Hash([]byte(name))))
+		if errors.Is(err, context.DeadlineExceeded) {
+			return ErrMigrationLocked
+		}
+
+		return err
+	}
+
+	var locked sql.NullInt64
+	err := conn.QueryRowContext(ctx, "select get_lock(?, ?)", name,
+		int(m.lockTimeout/time.Second)).Scan(&locked)
+	if err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return ErrMigrationLocked
+	}
+
+	return nil
+}
+
+// run runs the statements of script and the history query in a transaction,
+// notice that mysql commits the DDL statements implicitly.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, script, history string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range splitStatements(script, m.dialect) {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, history, args...); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) unlock(conn *sql.Conn) {
+	name := migrationLockPrefix + m.table
+	var err error
+	if m.dialect == PostgresDialect {
+		_, err = conn.ExecContext(context.Background(), "select pg_advisory_unlock($1)",
+			int64(hash.Hash([]byte(name))))
+	} else {
+		_, err = conn.ExecContext(context.Background(), "select release_lock(?)", name)
+	}
+	if err != nil {
+		logx.Errorf("sqlx: failed to release migration lock %s, error: %v", name, err)
+	}
+}
+
+func (m *Migrator) tableExists(ctx context.Context, conn *sql.Conn) (bool, error) {
+	query := "select count(*) from information_schema.tables where table_schema = database() and table_name = ?"
+	if m.dialect == PostgresDialect {
+		query = "select count(*) from information_schema.tables where table_schema = current_schema() and table_name = $1"
+	}
+
+	var count int
+	if err := conn.QueryRowContext(ctx, query, m.table).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// withLock runs fn on a dedicated connection, which holds the advisory lock unless dry-run.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	db, err := m.conn.RawDB()
+	if err != nil {
+		return err
+	}
+
+	// the advisory locks are held by the session, so the same connection is required.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dryRun {
+		return fn(conn)
+	}
+
+	if err = m.lock(ctx, conn); err != nil {
+		return err
+	}
+	defer m.unlock(conn)
+
+	if err = m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// appliedTime scans the timestamps, even if mysql returns them as bytes without parseTime.
+type appliedTime struct {
+	time.Time
+}
+
+func (t *appliedTime) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		t.Time = v
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	}
+
+	return nil
+}
+
+func (t *appliedTime) parse(s string) (err error) {
+	t.Time, err = time.ParseInLocation(time.DateTime, s, time.Local)
+	return err
+}
+
+func readMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		matches := migrationNameRegex.FindStringSubmatch(entry.Name())
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("sqlx: invalid migration file name %q", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("sqlx: invalid migration version %q", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{
+				Version: version,
+				Name:    matches[2],
+			}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("sqlx: duplicate migration version %d", version)
+		}
+
+		if strings.HasSuffix(entry.Name(), upSuffix) {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if len(migration.Up) == 0 {
+			return nil, fmt.Errorf("sqlx: migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// splitStatements splits script by the semicolons that are not in the quotes, the comments,
+// or the dollar-quoted strings of postgres.
+func splitStatements(script, dialect string) []string {
+	mysql := dialect == MysqlDialect
+	var stmts []string
+	var start int
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(script, i, c, mysql)
+		case c == '-' && strings.HasPrefix(script[i:], "--"), c == '#' && mysql:
+			if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(script)
+			}
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			if end := strings.Index(script[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(script)
+			}
+		case c == '$' && !mysql:
+			if tag := dollarTag(script[i:]); len(tag) > 0 {
+				if end := strings.Index(script[i+len(tag):], tag); end >= 0 {
+					i += len(tag) + end + len(tag) - 1
+				} else {
+					i = len(script)
+				}
+			}
+		case c == ';':
+			stmts = appendStatement(stmts, script[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(script) {
+		stmts = appendStatement(stmts, script[start:])
+	}
+
+	return stmts
+}
+
+func appendStatement(stmts []string, stmt string) []string {
+	if stmt = strings.TrimSpace(stmt); len(stmt) > 0 && !isComment(stmt) {
+		stmts = append(stmts, stmt)
+	}
+
+	return stmts
+}
+
+// dollarTag returns the dollar quote tag like $$ or $body$ at the beginning of s.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1]
+		}
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 1 || c < '0' || c > '9') {
+			return ""
+		}
+	}
+
+	return ""
+}
+
+// isComment returns true if stmt only contains comments,
+// the executable comments of mysql like /*!40101 ... */ are not comments.
+func isComment(stmt string) bool {
+	for i := 0; i < len(stmt); i++ {
+		switch c := stmt[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		case c == '-' && strings.HasPrefix(stmt[i:], "--"), c == '#':
+			end := strings.IndexByte(stmt[i:], '\n')
+			if end < 0 {
+				return true
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(stmt[i:], "/*") && !strings.HasPrefix(stmt[i:], "/*!"):
+			end := strings.Index(stmt[i+2:], "*/")
+			if end < 0 {
+				return true
+			}
+			i += end + 3
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// skipQuoted returns the index of the closing quote, the backslashes escape in mysql.
+func skipQuoted(script string, start int, quote byte, backslash bool) int {
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			// the doubled quotes are escaped quotes.
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+
+	return len(script)
+}
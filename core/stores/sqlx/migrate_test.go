@@ -0,0 +1,234 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/r27153733/fastgozero/core/stores/dbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMigrations = fstest.MapFS{
+	"0001_create_users.up.sql": {
+		Data: []byte("create table users (id bigint primary key);\ncreate index idx_id on users (id);"),
+	},
+	"0001_create_users.down.sql": {Data: []byte("drop table users;")},
+	"0002_add_name.up.sql":       {Data: []byte("alter table users add name varchar(64) default ';';")},
+	"0002_add_name.down.sql":     {Data: []byte("alter table users drop name;")},
+	"readme.md":                  {Data: []byte("migrations")},
+}
+
+func TestReadMigrations(t *testing.T) {
+	migrations, err := readMigrations(testMigrations)
+	assert.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{
+			Version: 1,
+			Name:    "create_users",
+			Up:      "create table users (id bigint primary key);\ncreate index idx_id on users (id);",
+			Down:    "drop table users;",
+		},
+		{
+			Version: 2,
+			Name:    "add_name",
+			Up:      "alter table users add name varchar(64) default ';';",
+			Down:    "alter table users drop name;",
+		},
+	}, migrations)
+
+	_, err = readMigrations(fstest.MapFS{"create_users.sql": {}})
+	assert.Error(t, err)
+	_, err = readMigrations(fstest.MapFS{"0001_users.down.sql": {}})
+	assert.Error(t, err)
+	_, err = readMigrations(fstest.MapFS{
+		"0001_users.up.sql":  {Data: []byte("select 1")},
+		"0001_orders.up.sql": {Data: []byte("select 1")},
+	})
+	assert.Error(t, err)
+	// the sub directories are ignored.
+	migrations, err = readMigrations(fstest.MapFS{"sub/0001_users.up.sql": {Data: []byte("select 1")}})
+	assert.NoError(t, err)
+	assert.Empty(t, migrations)
+
+	_, err = NewMigrator(nil, testMigrations, WithMigrationDialect("oracle"))
+	assert.Equal(t, ErrUnknownDialect, err)
+}
+
+func TestSplitStatements(t *testing.T) {
+	// the comments are kept in the statements, the ones only with comments are dropped.
+	assert.Equal(t, []string{
+		"-- comment; here\ninsert into t values ('a;b', \"c;d\", `e;f`)",
+		"# mysql comment;\ninsert into t values ('it''s;', 'x\\';y')",
+		"/* block; comment */\nselect 1",
+	}, splitStatements(`-- comment; here
+insert into t values ('a;b', "c;d", `+"`e;f`"+`);
+# mysql comment;
+insert into t values ('it''s;', 'x\';y');
+/* block; comment */
+select 1;
+-- trailing`, MysqlDialect))
+
+	assert.Equal(t, []string{
+		"create function f() returns int as $$ begin return 1; end; $$ language plpgsql",
+		"create function g() returns int as $body$ select 1; $body$ language sql",
+		"select 'x\\'",
+		"select $1",
+	}, splitStatements(`create function f() returns int as $$ begin return 1; end; $$ language plpgsql;
+create function g() returns int as $body$ select 1; $body$ language sql;
+select 'x\';
+select $1`, PostgresDialect))
+
+	// the statements only with block comments are dropped, but not the executable comments.
+	assert.Equal(t, []string{
+		"/*!40101 SET NAMES utf8mb4 */",
+		"/* a */ select 1",
+	}, splitStatements(`/* only; comment */;
+/* multi
+line */ -- and line
+;
+/*!40101 SET NAMES utf8mb4 */;
+/* a */ select 1;
+/* unclosed`, MysqlDialect))
+}
+
+func TestIsComment(t *testing.T) {
+	assert.True(t, isComment("-- a\n# b\n/* c */ /* d\n */"))
+	assert.True(t, isComment("/* unclosed"))
+	assert.False(t, isComment("/* a */ select 1"))
+	assert.False(t, isComment("/*!40101 SET NAMES utf8mb4 */"))
+	assert.False(t, isComment("-- a\nselect 1"))
+}
+
+func TestMigrator_Up(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("select get_lock").WithArgs("sqlx_migrate:schema_migrations", 60).
+			WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(1))
+		mock.ExpectExec("create table if not exists schema_migrations").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("select count\\(\\*\\) from information_schema.tables where table_schema = database\\(\\)").
+			WithArgs("schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("select version, applied_at from schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+				AddRow(1, []byte("2024-01-02 03:04:05")))
+		mock.ExpectBegin()
+		mock.ExpectExec("alter table users add name varchar\\(64\\) default ';'").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into schema_migrations \\(version, name\\) values \\(\\?, \\?\\)").
+			WithArgs(2, "add_name").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectExec("select release_lock").WithArgs("sqlx_migrate:schema_migrations").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		m, err := NewMigrator(NewSqlConnFromDB(db), testMigrations)
+		assert.NoError(t, err)
+		applied, err := m.Up(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+		assert.Equal(t, int64(2), applied[0].Version)
+	})
+}
+
+func TestMigrator_UpFailed(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("select get_lock").
+			WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(1))
+		mock.ExpectExec("create table if not exists schema_migrations").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("select count").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectBegin()
+		mock.ExpectExec("create table users").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("create index idx_id").WillReturnError(errors.New("boom"))
+		mock.ExpectRollback()
+		mock.ExpectExec("select release_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := Migrate(NewSqlConnFromDB(db), testMigrations)
+		assert.ErrorContains(t, err, "1_create_users: boom")
+	})
+}
+
+func TestMigrator_Locked(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("select get_lock").WithArgs("sqlx_migrate:history", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(0))
+
+		err := Migrate(NewSqlConnFromDB(db), testMigrations, WithMigrationTable("history"),
+			WithMigrationLockTimeout(time.Second))
+		assert.Equal(t, ErrMigrationLocked, err)
+	})
+}
+
+func TestMigrator_DryRun(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("select count").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery("select count").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("select version, applied_at from schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+				AddRow(1, time.Now()).AddRow(2, time.Now()))
+
+		m, err := NewMigrator(NewSqlConnFromDB(db), testMigrations, WithMigrationDryRun())
+		assert.NoError(t, err)
+		applied, err := m.Up(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, applied, 2)
+
+		reverted, err := m.Down(context.Background(), 5)
+		assert.NoError(t, err)
+		assert.Len(t, reverted, 2)
+		assert.Equal(t, int64(2), reverted[0].Version)
+		assert.Equal(t, int64(1), reverted[1].Version)
+	})
+}
+
+func TestMigrator_DownPostgres(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		mock.ExpectExec("select pg_advisory_lock\\(\\$1\\)").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("create table if not exists schema_migrations").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("table_schema = current_schema\\(\\) and table_name = \\$1").
+			WithArgs("schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("select version, applied_at from schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+				AddRow(1, time.Now()).AddRow(2, time.Now()))
+		mock.ExpectBegin()
+		mock.ExpectExec("alter table users drop name").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("delete from schema_migrations where version = \\$1").
+			WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectExec("select pg_advisory_unlock\\(\\$1\\)").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		m, err := NewMigrator(NewSqlConnFromDB(db), testMigrations, WithMigrationDialect(PostgresDialect))
+		assert.NoError(t, err)
+		reverted, err := m.Down(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, reverted, 1)
+		assert.Equal(t, "add_name", reverted[0].Name)
+	})
+}
+
+func TestMigrator_Status(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		mock.ExpectQuery("select count").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("select version, applied_at from schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, at))
+
+		m, err := NewMigrator(NewSqlConnFromDB(db), testMigrations)
+		assert.NoError(t, err)
+		statuses, err := m.Status(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, statuses, 2)
+		assert.True(t, statuses[0].Applied)
+		assert.Equal(t, at, statuses[0].AppliedAt)
+		assert.False(t, statuses[1].Applied)
+		assert.True(t, statuses[1].AppliedAt.IsZero())
+	})
+}
@@ -0,0 +1,96 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/r27153733/fastgozero/core/mapping"
+	"github.com/r27153733/fastgozero/core/stores/builder"
+)
+
+var errInvalidPageSize = errors.New("page size must be positive")
+
+// QueryPageCtx queries a page of at most size rows into v with the keyset pagination,
+// the rows are after the given cursor, or from the first row if cursor is empty.
+// v must be a pointer to a slice of structs, which have the db tags of the keyset columns.
+// It returns the cursor of the next page, which is empty if no more rows.
+func QueryPageCtx(ctx context.Context, session Session, v any, sb *builder.SelectBuilder,
+	keyset builder.Keyset, cursor string, size int64) (string, error) {
+	if size <= 0 {
+		return "", errInvalidPageSize
+	}
+
+	rv := reflect.ValueOf(v)
+	if err := mapping.ValidatePtr(rv); err != nil {
+		return "", err
+	}
+
+	rt := rv.Type().Elem()
+	if rt.Kind() != reflect.Slice || mapping.Deref(rt.Elem()).Kind() != reflect.Struct {
+		return "", ErrUnsupportedValueType
+	}
+
+	var last []any
+	if len(cursor) > 0 {
+		keys, err := keysetValues(reflect.New(mapping.Deref(rt.Elem())), keyset)
+		if err != nil {
+			return "", err
+		}
+
+		if err := builder.DecodeCursor(cursor, keys...); err != nil {
+			return "", err
+		}
+
+		for _, key := range keys {
+			last = append(last, reflect.ValueOf(key).Elem().Interface())
+		}
+	}
+
+	// query one more row to know if there are more rows.
+	if err := QueryRowsBuilderCtx(ctx, session, v, keyset.Apply(sb, last, size+1)); err != nil {
+		return "", err
+	}
+
+	rows := rv.Elem()
+	if int64(rows.Len()) <= size {
+		return "", nil
+	}
+
+	rows.Set(rows.Slice(0, int(size)))
+	keys, err := keysetValues(rows.Index(int(size)-1), keyset)
+	if err != nil {
+		return "", err
+	}
+
+	vals := make([]any, 0, len(keys))
+	for _, key := range keys {
+		vals = append(vals, reflect.ValueOf(key).Elem().Interface())
+	}
+
+	return builder.EncodeCursor(vals...)
+}
+
+// keysetValues returns the pointers to the fields of the keyset columns in v.
+func keysetValues(v reflect.Value, keyset builder.Keyset) ([]any, error) {
+	taggedMap, err := getTaggedFieldValueMap(v)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]any, 0, len(keyset.Columns))
+	for _, column := range keyset.Columns {
+		name := column[strings.LastIndexByte(column, '.')+1:]
+		name = strings.Trim(name, "`\"")
+		key, ok := taggedMap[name]
+		if !ok {
+			return nil, fmt.Errorf("no db tag of keyset column %q", column)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
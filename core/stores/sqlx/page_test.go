@@ -0,0 +1,78 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/r27153733/fastgozero/core/stores/builder"
+	"github.com/r27153733/fastgozero/core/stores/dbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryPageCtx(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		conn := NewSqlConnFromDB(db)
+		keyset := builder.Keyset{Columns: []string{"`id`"}}
+		mock.ExpectQuery("select `id`, `name` from users order by `id` limit 3").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+				AddRow(1, "a").AddRow(2, "b").AddRow(3, "c"))
+		var users []iterUser
+		next, err := QueryPageCtx(context.Background(), conn, &users,
+			builder.Select("`id`", "`name`").From("users"), keyset, "", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []iterUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, users)
+		assert.NotEmpty(t, next)
+
+		mock.ExpectQuery("select `id`, `name` from users where `id` > \\? order by `id` limit 3").
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "c"))
+		var ptrs []*iterUser
+		next, err = QueryPageCtx(context.Background(), conn, &ptrs,
+			builder.Select("`id`", "`name`").From("users"), keyset, next, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []*iterUser{{ID: 3, Name: "c"}}, ptrs)
+		assert.Empty(t, next)
+	})
+}
+
+func TestQueryPageCtx_Desc(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		keyset := builder.Keyset{Columns: []string{"name", "u.id"}, Desc: true}
+		cursor, err := builder.EncodeCursor("c", 3)
+		assert.NoError(t, err)
+		mock.ExpectQuery("select \\* from users u where \\(name, u.id\\) < \\(\\$1, \\$2\\)"+
+			" order by name desc, u.id desc limit 2").
+			WithArgs("c", int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "b").AddRow(1, "a"))
+		var users []iterUser
+		next, err := QueryPageCtx(context.Background(), NewSqlConnFromDB(db), &users,
+			builder.Postgres.Select().From("users u"), keyset, cursor, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []iterUser{{ID: 2, Name: "b"}}, users)
+
+		var name string
+		var id int64
+		assert.NoError(t, builder.DecodeCursor(next, &name, &id))
+		assert.Equal(t, "b", name)
+		assert.Equal(t, int64(2), id)
+	})
+}
+
+func TestQueryPageCtx_Invalid(t *testing.T) {
+	sb := builder.Select().From("users")
+	keyset := builder.Keyset{Columns: []string{"id"}}
+	var users []iterUser
+	_, err := QueryPageCtx(context.Background(), nil, &users, sb, keyset, "", 0)
+	assert.Equal(t, errInvalidPageSize, err)
+	_, err = QueryPageCtx(context.Background(), nil, users, sb, keyset, "", 1)
+	assert.Error(t, err)
+	var ids []int64
+	_, err = QueryPageCtx(context.Background(), nil, &ids, sb, keyset, "", 1)
+	assert.Equal(t, ErrUnsupportedValueType, err)
+	_, err = QueryPageCtx(context.Background(), nil, &users, sb, keyset, "bad", 1)
+	assert.Equal(t, builder.ErrInvalidCursor, err)
+	_, err = QueryPageCtx(context.Background(), nil, &users, sb, builder.Keyset{Columns: []string{"age"}}, "bad", 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+)
+
+// A QueryBuilder builds a statement and its args, like the builders in core/stores/builder.
+type QueryBuilder interface {
+	Build() (string, []any, error)
+}
+
+// ExecBuilderCtx executes the statement built by b on session.
+func ExecBuilderCtx(ctx context.Context, session Session, b QueryBuilder) (sql.Result, error) {
+	query, args, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return session.ExecCtx(ctx, query, args...)
+}
+
+// QueryRowBuilderCtx unmarshals a row into v with the statement built by b on session.
+func QueryRowBuilderCtx(ctx context.Context, session Session, v any, b QueryBuilder) error {
+	query, args, err := b.Build()
+	if err != nil {
+		return err
+	}
+
+	return session.QueryRowCtx(ctx, v, query, args...)
+}
+
+// QueryRowsBuilderCtx unmarshals rows into v with the statement built by b on session.
+func QueryRowsBuilderCtx(ctx context.Context, session Session, v any, b QueryBuilder) error {
+	query, args, err := b.Build()
+	if err != nil {
+		return err
+	}
+
+	return session.QueryRowsCtx(ctx, v, query, args...)
+}
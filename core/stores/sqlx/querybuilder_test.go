@@ -0,0 +1,52 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/r27153733/fastgozero/core/stores/builder"
+	"github.com/r27153733/fastgozero/core/stores/dbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+type badBuilder struct{}
+
+func (b badBuilder) Build() (string, []any, error) {
+	return "", nil, errors.New("bad")
+}
+
+func TestQueryBuilder(t *testing.T) {
+	dbtest.RunTest(t, func(db *sql.DB, mock sqlmock.Sqlmock) {
+		conn := NewSqlConnFromDB(db)
+		mock.ExpectExec("update users set name = \\? where id = \\?").WithArgs("a", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		res, err := ExecBuilderCtx(context.Background(), conn,
+			builder.Update("users").Set("name", "a").Where(builder.Eq("id", 1)))
+		assert.NoError(t, err)
+		affected, err := res.RowsAffected()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+
+		mock.ExpectQuery("select name from users where id = \\$1").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a"))
+		var name string
+		assert.NoError(t, QueryRowBuilderCtx(context.Background(), conn, &name,
+			builder.Postgres.Select("name").From("users").Where(builder.Eq("id", 1))))
+		assert.Equal(t, "a", name)
+
+		mock.ExpectQuery("select name from users where id in \\(\\?, \\?\\)").WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a").AddRow("b"))
+		var names []string
+		assert.NoError(t, QueryRowsBuilderCtx(context.Background(), conn, &names,
+			builder.Select("name").From("users").Where(builder.In("id", 1, 2))))
+		assert.Equal(t, []string{"a", "b"}, names)
+
+		_, err = ExecBuilderCtx(context.Background(), conn, badBuilder{})
+		assert.Error(t, err)
+		assert.Error(t, QueryRowBuilderCtx(context.Background(), conn, &name, badBuilder{}))
+		assert.Error(t, QueryRowsBuilderCtx(context.Background(), conn, &names, badBuilder{}))
+	})
+}
@@ -0,0 +1,306 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/r27153733/fastgozero/core/breaker"
+	"github.com/r27153733/fastgozero/core/syncx"
+	"github.com/r27153733/fastgozero/core/timex"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const defaultReadPrimaryAfterWrite = time.Second
+
+type (
+	forcePrimaryKey struct{}
+	writeTrackerKey struct{}
+
+	// rwSqlConn sends the writes and the transactions to the primary,
+	// and balances the reads across the replicas that are not broken,
+	// the reads fall back to the primary if all the replicas are broken or unreachable.
+	// The reads after a write in the same context go to the primary.
+	rwSqlConn struct {
+		primary  *commonSqlConn
+		replicas []*replicaConn
+		pick     func(n int) int
+		writes   *writeTracker
+	}
+
+	// replicaConn is a replica with its own breaker and metrics.
+	replicaConn struct {
+		*commonSqlConn
+		name string
+	}
+
+	// writeTracker tracks the recent writes by the trace ids of the contexts,
+	// the writes on the contexts without trace ids are not tracked,
+	// use WithReadYourWrites on them instead.
+	writeTracker struct {
+		window  time.Duration
+		writes  map[oteltrace.TraceID]time.Duration
+		sweepAt time.Duration
+		lock    sync.RWMutex
+	}
+)
+
+// ForcePrimary returns a context that routes the reads on it to the primary.
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, struct{}{})
+}
+
+// WithReadYourWrites returns a context that routes the reads on it to the primary
+// once a write is done on it, to read the writes regardless of the replication lag.
+// Without it, the reads go to the primary within the ReadPrimaryAfterWrite window
+// after a write in the same trace, which is the same request in general.
+// The contexts without traces, like the ones of cron jobs, should use it to read their writes.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(writeTrackerKey{}).(*syncx.AtomicBool); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, writeTrackerKey{}, syncx.NewAtomicBool())
+}
+
+func newRwSqlConn(primary *commonSqlConn, replicas []*replicaConn, policy string,
+	readPrimaryAfterWrite time.Duration) *rwSqlConn {
+	conn := &rwSqlConn{
+		primary:  primary,
+		replicas: replicas,
+		writes:   newWriteTracker(readPrimaryAfterWrite),
+	}
+
+	if policy == RandomPolicy {
+		conn.pick = rand.Intn
+	} else {
+		var next atomic.Uint64
+		conn.pick = func(n int) int {
+			return int((next.Add(1) - 1) % uint64(n))
+		}
+	}
+
+	return conn
+}
+
+func (db *rwSqlConn) Exec(q string, args ...any) (sql.Result, error) {
+	return db.ExecCtx(context.Background(), q, args...)
+}
+
+func (db *rwSqlConn) ExecCtx(ctx context.Context, q string, args ...any) (sql.Result, error) {
+	db.markWritten(ctx)
+	return db.primary.ExecCtx(ctx, q, args...)
+}
+
+func (db *rwSqlConn) Prepare(query string) (StmtSession, error) {
+	return db.PrepareCtx(context.Background(), query)
+}
+
+// PrepareCtx prepares on the primary, because the statements might be used to write.
+func (db *rwSqlConn) PrepareCtx(ctx context.Context, query string) (StmtSession, error) {
+	return db.primary.PrepareCtx(ctx, query)
+}
+
+func (db *rwSqlConn) QueryRow(v any, q string, args ...any) error {
+	return db.QueryRowCtx(context.Background(), v, q, args...)
+}
+
+func (db *rwSqlConn) QueryRowCtx(ctx context.Context, v any, q string, args ...any) (err error) {
+	ctx, span := startSpan(ctx, "QueryRow")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	return db.queryRows(ctx, func(rows *sql.Rows) error {
+		return unmarshalRow(v, rows, true)
+	}, q, args...)
+}
+
+func (db *rwSqlConn) QueryRowPartial(v any, q string, args ...any) error {
+	return db.QueryRowPartialCtx(context.Background(), v, q, args...)
+}
+
+func (db *rwSqlConn) QueryRowPartialCtx(ctx context.Context, v any, q string,
+	args ...any) (err error) {
+	ctx, span := startSpan(ctx, "QueryRowPartial")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	return db.queryRows(ctx, func(rows *sql.Rows) error {
+		return unmarshalRow(v, rows, false)
+	}, q, args...)
+}
+
+func (db *rwSqlConn) QueryRows(v any, q string, args ...any) error {
+	return db.QueryRowsCtx(context.Background(), v, q, args...)
+}
+
+func (db *rwSqlConn) QueryRowsCtx(ctx context.Context, v any, q string, args ...any) (err error) {
+	ctx, span := startSpan(ctx, "QueryRows")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	return db.queryRows(ctx, func(rows *sql.Rows) error {
+		return unmarshalRows(v, rows, true)
+	}, q, args...)
+}
+
+func (db *rwSqlConn) QueryRowsPartial(v any, q string, args ...any) error {
+	return db.QueryRowsPartialCtx(context.Background(), v, q, args...)
+}
+
+func (db *rwSqlConn) QueryRowsPartialCtx(ctx context.Context, v any, q string,
+	args ...any) (err error) {
+	ctx, span := startSpan(ctx, "QueryRowsPartial")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	return db.queryRows(ctx, func(rows *sql.Rows) error {
+		return unmarshalRows(v, rows, false)
+	}, q, args...)
+}
+
+// RawDB returns the primary.
+func (db *rwSqlConn) RawDB() (*sql.DB, error) {
+	return db.primary.RawDB()
+}
+
+func (db *rwSqlConn) Transact(fn func(Session) error) error {
+	return db.TransactCtx(context.Background(), func(_ context.Context, session Session) error {
+		return fn(session)
+	})
+}
+
+func (db *rwSqlConn) TransactCtx(ctx context.Context, fn func(context.Context, Session) error) error {
+	db.markWritten(ctx)
+	return db.primary.TransactCtx(ctx, fn)
+}
+
+func (db *rwSqlConn) queryRows(ctx context.Context, scanner func(*sql.Rows) error,
+	q string, args ...any) error {
+	if readPrimary(ctx) || db.writes.written(ctx) {
+		return db.primary.queryRows(ctx, scanner, q, args...)
+	}
+
+	start := db.pick(len(db.replicas))
+	for i := range db.replicas {
+		replica := db.replicas[(start+i)%len(db.replicas)]
+		err := replica.queryRows(ctx, scanner, q, args...)
+		if !errors.Is(err, breaker.ErrServiceUnavailable) && (ctx.Err() != nil || !isConnError(err)) {
+			return err
+		}
+	}
+
+	metricReqErr.Inc("queryRows", "replica_unavailable")
+	return db.primary.queryRows(ctx, scanner, q, args...)
+}
+
+func (db *rwSqlConn) markWritten(ctx context.Context) {
+	markWritten(ctx)
+	db.writes.mark(ctx)
+}
+
+func (r *replicaConn) queryRows(ctx context.Context, scanner func(*sql.Rows) error,
+	q string, args ...any) error {
+	startTime := timex.Now()
+	err := r.commonSqlConn.queryRows(ctx, scanner, q, args...)
+	if errors.Is(err, breaker.ErrServiceUnavailable) {
+		metricReplicaReqErr.Inc(r.name, "breaker")
+		return err
+	}
+
+	metricReplicaReqDur.Observe(timex.Since(startTime).Milliseconds(), r.name)
+	if !r.acceptable(err) {
+		metricReplicaReqErr.Inc(r.name, "error")
+	}
+
+	return err
+}
+
+func newWriteTracker(window time.Duration) *writeTracker {
+	if window <= 0 {
+		window = defaultReadPrimaryAfterWrite
+	}
+
+	return &writeTracker{
+		window: window,
+		writes: make(map[oteltrace.TraceID]time.Duration),
+	}
+}
+
+func (t *writeTracker) mark(ctx context.Context) {
+	traceId := oteltrace.SpanContextFromContext(ctx).TraceID()
+	if !traceId.IsValid() {
+		return
+	}
+
+	now := timex.Now()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.writes[traceId] = now + t.window
+	if now < t.sweepAt {
+		return
+	}
+
+	for id, expire := range t.writes {
+		if expire <= now {
+			delete(t.writes, id)
+		}
+	}
+	t.sweepAt = now + t.window
+}
+
+// written returns true if a write is done in the same trace of ctx within the window.
+func (t *writeTracker) written(ctx context.Context) bool {
+	traceId := oteltrace.SpanContextFromContext(ctx).TraceID()
+	if !traceId.IsValid() {
+		return false
+	}
+
+	t.lock.RLock()
+	expire, ok := t.writes[traceId]
+	t.lock.RUnlock()
+
+	return ok && timex.Now() < expire
+}
+
+// isConnError returns true if err means the database is unreachable.
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func markWritten(ctx context.Context) {
+	if tracker, ok := ctx.Value(writeTrackerKey{}).(*syncx.AtomicBool); ok {
+		tracker.Set(true)
+	}
+}
+
+func readPrimary(ctx context.Context) bool {
+	if ctx.Value(forcePrimaryKey{}) != nil {
+		return true
+	}
+
+	tracker, ok := ctx.Value(writeTrackerKey{}).(*syncx.AtomicBool)
+	return ok && tracker.True()
+}
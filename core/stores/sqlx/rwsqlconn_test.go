@@ -0,0 +1,263 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/r27153733/fastgozero/core/breaker"
+	"github.com/r27153733/fastgozero/core/stores/dbtest"
+	"github.com/stretchr/testify/assert"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestRwSqlConn(t *testing.T) {
+	runRwTest(t, func(conn *rwSqlConn, primary sqlmock.Sqlmock, replicas []sqlmock.Sqlmock) {
+		replicas[0].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("r0"))
+		replicas[1].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("r1"))
+		replicas[0].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("r0"))
+		primary.ExpectExec("update").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		for _, expect := range []string{"r0", "r1", "r0"} {
+			var val string
+			assert.NoError(t, conn.QueryRow(&val, "select"))
+			assert.Equal(t, expect, val)
+		}
+		_, err := conn.Exec("update")
+		assert.NoError(t, err)
+	})
+}
+
+func TestRwSqlConn_QueryVariants(t *testing.T) {
+	runRwTest(t, func(conn *rwSqlConn, primary sqlmock.Sqlmock, replicas []sqlmock.Sqlmock) {
+		replicas[0].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("a"))
+		replicas[1].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("b"))
+		replicas[0].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("c").AddRow("d"))
+
+		var val string
+		assert.NoError(t, conn.QueryRowPartial(&val, "select"))
+		assert.Equal(t, "a", val)
+		var vals []string
+		assert.NoError(t, conn.QueryRows(&vals, "select"))
+		assert.Equal(t, []string{"b"}, vals)
+		vals = nil
+		assert.NoError(t, conn.QueryRowsPartial(&vals, "select"))
+		assert.Equal(t, []string{"c", "d"}, vals)
+	})
+}
+
+func TestRwSqlConn_ForcePrimary(t *testing.T) {
+	runRwTest(t, func(conn *rwSqlConn, primary sqlmock.Sqlmock, replicas []sqlmock.Sqlmock) {
+		primary.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("p"))
+
+		var val string
+		assert.NoError(t, conn.QueryRowCtx(ForcePrimary(context.Background()), &val, "select"))
+		assert.Equal(t, "p", val)
+	})
+}
+
+func TestRwSqlConn_ReadYourWrites(t *testing.T) {
+	runRwTest(t, func(conn *rwSqlConn, primary sqlmock.Sqlmock, replicas []sqlmock.Sqlmock) {
+		replicas[0].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("r0"))
+		primary.ExpectExec("update").WillReturnResult(sqlmock.NewResult(0, 1))
+		primary.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("p"))
+		replicas[1].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("r1"))
+
+		ctx := WithReadYourWrites(context.Background())
+		assert.Equal(t, ctx, WithReadYourWrites(ctx))
+		var val string
+		assert.NoError(t, conn.QueryRowCtx(ctx, &val, "select"))
+		assert.Equal(t, "r0", val)
+		_, err := conn.ExecCtx(ctx, "update")
+		assert.NoError(t, err)
+		assert.NoError(t, conn.QueryRowCtx(ctx, &val, "select"))
+		assert.Equal(t, "p", val)
+		// the other contexts are not affected
+		assert.NoError(t, conn.QueryRowCtx(traceContext(1), &val, "select"))
+		assert.Equal(t, "r1", val)
+	})
+}
+
+func TestRwSqlConn_ReadYourWritesByDefault(t *testing.T) {
+	runRwTest(t, func(conn *rwSqlConn, primary sqlmock.Sqlmock, replicas []sqlmock.Sqlmock) {
+		primary.ExpectExec("update").WillReturnResult(sqlmock.NewResult(0, 1))
+		primary.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("p"))
+		replicas[0].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("r0"))
+		primary.ExpectExec("update").WillReturnResult(sqlmock.NewResult(0, 1))
+		replicas[1].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("r1"))
+		replicas[0].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("r0"))
+
+		// the reads after a write in the same trace go to the primary.
+		ctx := traceContext(1)
+		_, err := conn.ExecCtx(ctx, "update")
+		assert.NoError(t, err)
+		var val string
+		assert.NoError(t, conn.QueryRowCtx(ctx, &val, "select"))
+		assert.Equal(t, "p", val)
+		assert.NoError(t, conn.QueryRowCtx(traceContext(2), &val, "select"))
+		assert.Equal(t, "r0", val)
+
+		// the writes without traces are not tracked,
+		// the unrelated reads without traces still go to the replicas.
+		_, err = conn.Exec("update")
+		assert.NoError(t, err)
+		assert.NoError(t, conn.QueryRow(&val, "select"))
+		assert.Equal(t, "r1", val)
+
+		// the reads go to the replicas again after the window.
+		time.Sleep(conn.writes.window * 2)
+		assert.NoError(t, conn.QueryRowCtx(ctx, &val, "select"))
+		assert.Equal(t, "r0", val)
+	})
+}
+
+func TestRwSqlConn_UnreachableReplicas(t *testing.T) {
+	runRwTest(t, func(conn *rwSqlConn, primary sqlmock.Sqlmock, replicas []sqlmock.Sqlmock) {
+		connErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")}
+		replicas[0].ExpectQuery("select").WillReturnError(connErr)
+		replicas[1].ExpectQuery("select").WillReturnError(connErr)
+		primary.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("p"))
+
+		var val string
+		assert.NoError(t, conn.QueryRow(&val, "select"))
+		assert.Equal(t, "p", val)
+
+		// the other errors are returned without falling back.
+		replicas[1].ExpectQuery("select").WillReturnError(errors.New("syntax error"))
+		assert.EqualError(t, conn.QueryRow(&val, "select"), "syntax error")
+	})
+}
+
+func TestRwSqlConn_Transact(t *testing.T) {
+	runRwTest(t, func(conn *rwSqlConn, primary sqlmock.Sqlmock, replicas []sqlmock.Sqlmock) {
+		primary.ExpectBegin()
+		primary.ExpectExec("update").WillReturnResult(sqlmock.NewResult(0, 1))
+		primary.ExpectCommit()
+		primary.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("p"))
+
+		ctx := WithReadYourWrites(context.Background())
+		assert.NoError(t, conn.TransactCtx(ctx, func(ctx context.Context, session Session) error {
+			_, err := session.ExecCtx(ctx, "update")
+			return err
+		}))
+		var val string
+		assert.NoError(t, conn.QueryRowCtx(ctx, &val, "select"))
+		assert.Equal(t, "p", val)
+
+		db, err := conn.RawDB()
+		assert.NoError(t, err)
+		primaryDB, err := conn.primary.RawDB()
+		assert.NoError(t, err)
+		assert.Equal(t, primaryDB, db)
+	})
+}
+
+func TestRwSqlConn_BrokenReplicas(t *testing.T) {
+	runRwTest(t, func(conn *rwSqlConn, primary sqlmock.Sqlmock, replicas []sqlmock.Sqlmock) {
+		conn.replicas[0].brk = rejectBreaker{Breaker: breaker.NopBreaker()}
+		replicas[1].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("r1"))
+		replicas[1].ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("r1"))
+
+		var val string
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, conn.QueryRow(&val, "select"))
+			assert.Equal(t, "r1", val)
+		}
+
+		conn.replicas[1].brk = rejectBreaker{Breaker: breaker.NopBreaker()}
+		primary.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("p"))
+		assert.NoError(t, conn.QueryRow(&val, "select"))
+		assert.Equal(t, "p", val)
+	})
+}
+
+func TestRwSqlConn_RandomPolicy(t *testing.T) {
+	runRwTest(t, func(conn *rwSqlConn, primary sqlmock.Sqlmock, replicas []sqlmock.Sqlmock) {
+		conn.pick = newRwSqlConn(conn.primary, conn.replicas, RandomPolicy, 0).pick
+		for i := 0; i < 100; i++ {
+			index := conn.pick(len(conn.replicas))
+			assert.True(t, index >= 0 && index < len(conn.replicas))
+		}
+	})
+}
+
+func TestIsConnError(t *testing.T) {
+	assert.False(t, isConnError(nil))
+	assert.False(t, isConnError(errors.New("any")))
+	assert.True(t, isConnError(driver.ErrBadConn))
+	assert.True(t, isConnError(fmt.Errorf("query: %w", mysql.ErrInvalidConn)))
+	assert.True(t, isConnError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("refused")}))
+}
+
+func TestWriteTracker(t *testing.T) {
+	tracker := newWriteTracker(0)
+	assert.Equal(t, defaultReadPrimaryAfterWrite, tracker.window)
+
+	tracker = newWriteTracker(time.Millisecond)
+	tracker.mark(traceContext(1))
+	assert.True(t, tracker.written(traceContext(1)))
+	assert.False(t, tracker.written(traceContext(2)))
+	time.Sleep(2 * time.Millisecond)
+	assert.False(t, tracker.written(traceContext(1)))
+	// the expired writes are swept on marking.
+	tracker.mark(traceContext(2))
+	assert.Len(t, tracker.writes, 1)
+
+	// the writes without traces are not tracked.
+	tracker.mark(context.Background())
+	assert.False(t, tracker.written(context.Background()))
+	assert.Len(t, tracker.writes, 1)
+}
+
+func TestRoutingContext(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, readPrimary(ctx))
+	markWritten(ctx)
+	assert.False(t, readPrimary(ctx))
+	assert.True(t, readPrimary(ForcePrimary(ctx)))
+
+	ctx = WithReadYourWrites(ctx)
+	assert.False(t, readPrimary(ctx))
+	markWritten(ForcePrimary(ctx))
+	assert.True(t, readPrimary(ctx))
+}
+
+func traceContext(id byte) context.Context {
+	return oteltrace.ContextWithSpanContext(context.Background(),
+		oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+			TraceID:    oteltrace.TraceID{id},
+			SpanID:     oteltrace.SpanID{id},
+			TraceFlags: oteltrace.FlagsSampled,
+		}))
+}
+
+type rejectBreaker struct {
+	breaker.Breaker
+}
+
+func (b rejectBreaker) DoWithAcceptableCtx(_ context.Context, _ func() error,
+	_ breaker.Acceptable) error {
+	return breaker.ErrServiceUnavailable
+}
+
+func runRwTest(t *testing.T, fn func(conn *rwSqlConn, primary sqlmock.Sqlmock,
+	replicas []sqlmock.Sqlmock)) {
+	dbtest.RunTest(t, func(primaryDB *sql.DB, primary sqlmock.Sqlmock) {
+		dbtest.RunTest(t, func(db0 *sql.DB, mock0 sqlmock.Sqlmock) {
+			dbtest.RunTest(t, func(db1 *sql.DB, mock1 sqlmock.Sqlmock) {
+				conn := newRwSqlConn(NewSqlConnFromDB(primaryDB).(*commonSqlConn), []*replicaConn{
+					{commonSqlConn: NewSqlConnFromDB(db0).(*commonSqlConn), name: "r0"},
+					{commonSqlConn: NewSqlConnFromDB(db1).(*commonSqlConn), name: "r1"},
+				}, RoundRobinPolicy, 50*time.Millisecond)
+				fn(conn, primary, []sqlmock.Sqlmock{mock0, mock1})
+			})
+		})
+	})
+}
@@ -66,6 +66,10 @@ type (
 
 // NewSqlConn returns a SqlConn with given driver name and datasource.
 func NewSqlConn(driverName, datasource string, opts ...SqlOption) SqlConn {
+	return newCommonSqlConn(driverName, datasource, opts...)
+}
+
+func newCommonSqlConn(driverName, datasource string, opts ...SqlOption) *commonSqlConn {
 	conn := &commonSqlConn{
 		connProv: func() (*sql.DB, error) {
 			return getSqlConn(driverName, datasource)
@@ -143,6 +143,11 @@ func (t txSession) QueryRowsPartialCtx(ctx context.Context, v any, q string,
 	}, q, args...)
 }
 
+func (t txSession) queryRows(ctx context.Context, scanner func(*sql.Rows) error,
+	q string, args ...any) error {
+	return query(ctx, t.Tx, scanner, q, args...)
+}
+
 func begin(db *sql.DB) (trans, error) {
 	tx, err := db.Begin()
 	if err != nil {
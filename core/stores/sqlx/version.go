@@ -0,0 +1,23 @@
+package sqlx
+
+import "database/sql"
+
+// CheckVersion checks the result of the update with the optimistic locking,
+// which is used like CheckVersion(conn.ExecCtx(ctx, query, args...)).
+// It returns ErrVersionConflict if no rows are affected.
+func CheckVersion(result sql.Result, err error) (sql.Result, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if affected == 0 {
+		return nil, ErrVersionConflict
+	}
+
+	return result, nil
+}
@@ -0,0 +1,26 @@
+package sqlx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckVersion(t *testing.T) {
+	result, err := CheckVersion(sqlmock.NewResult(0, 1), nil)
+	assert.NoError(t, err)
+	affected, err := result.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	_, err = CheckVersion(sqlmock.NewResult(0, 0), nil)
+	assert.Equal(t, ErrVersionConflict, err)
+
+	errDummy := errors.New("dummy")
+	_, err = CheckVersion(nil, errDummy)
+	assert.Equal(t, errDummy, err)
+	_, err = CheckVersion(sqlmock.NewErrorResult(errDummy), nil)
+	assert.Equal(t, errDummy, err)
+}
@@ -1,6 +1,8 @@
 package gateway
 
 import (
+	"time"
+
 	"github.com/r27153733/fastgozero/rest"
 	"github.com/r27153733/fastgozero/zrpc"
 )
@@ -20,6 +22,52 @@ type (
 		Path string
 		// RpcPath is the gRPC rpc method, with format of package.service/method
 		RpcPath string
+		// Policy is the route policy, the non-empty items override the ones of the Upstream.
+		Policy RoutePolicy `json:",optional"`
+	}
+
+	// RoutePolicy is the policy applied on the gateway routes,
+	// like the RouteOption of rest for hand-written servers.
+	RoutePolicy struct {
+		// Jwt enables jwt authentication if Secret is set.
+		Jwt JwtConf `json:",optional"`
+		// Signature enables signature verification if Strict or PrivateKeys is set.
+		Signature SignatureConf `json:",optional"`
+		// Timeout is the timeout of the route in milliseconds, applied as the route timeout of rest.
+		Timeout int64 `json:",optional"`
+		// MaxBytes is the max bytes of the request body.
+		MaxBytes int64 `json:",optional"`
+		// Cors enables CORS if Origins is set, use * to allow all origins.
+		Cors CorsConf `json:",optional"`
+		// RateLimit enables rate limiting on each route if Rate is set.
+		RateLimit RateLimitConf `json:",optional"`
+	}
+
+	// JwtConf is the jwt authentication configuration.
+	JwtConf struct {
+		Secret string `json:",optional"`
+		// PrevSecret is used for secret transition, old and new secrets work together for a period.
+		PrevSecret string `json:",optional"`
+	}
+
+	// SignatureConf is the signature verification configuration.
+	SignatureConf struct {
+		Strict      bool                  `json:",default=false"`
+		Expiry      time.Duration         `json:",default=1h"`
+		PrivateKeys []rest.PrivateKeyConf `json:",optional"`
+	}
+
+	// CorsConf is the CORS configuration.
+	CorsConf struct {
+		Origins []string `json:",optional"`
+	}
+
+	// RateLimitConf is the rate limit configuration, the limit is counted in each gateway instance.
+	RateLimitConf struct {
+		// Rate is the number of requests allowed per second.
+		Rate int `json:",optional"`
+		// Burst is the max burst of requests, defaults to Rate.
+		Burst int `json:",optional"`
 	}
 
 	// Upstream is the configuration for an upstream.
@@ -35,5 +83,7 @@ type (
 		// Mappings is the mapping between gateway routes and Upstream rpc methods.
 		// Keep it blank if annotations are added in rpc methods.
 		Mappings []RouteMapping `json:",optional"`
+		// Policy is the default route policy of the routes in this upstream.
+		Policy RoutePolicy `json:",optional"`
 	}
 )
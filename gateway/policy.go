@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/r27153733/fastgozero/rest"
+	"golang.org/x/time/rate"
+)
+
+// buildRouteOptions builds the rest.RouteOption list from the given policy.
+// The rate limiter is created on each call, so routes don't share the quota.
+func buildRouteOptions(policy RoutePolicy) []rest.RouteOption {
+	var opts []rest.RouteOption
+
+	if len(policy.Jwt.Secret) > 0 {
+		if len(policy.Jwt.PrevSecret) > 0 {
+			opts = append(opts, rest.WithJwtTransition(policy.Jwt.Secret, policy.Jwt.PrevSecret))
+		} else {
+			opts = append(opts, rest.WithJwt(policy.Jwt.Secret))
+		}
+	}
+	if policy.Signature.Strict || len(policy.Signature.PrivateKeys) > 0 {
+		opts = append(opts, rest.WithSignature(rest.SignatureConf{
+			Strict:      policy.Signature.Strict,
+			Expiry:      policy.Signature.Expiry,
+			PrivateKeys: policy.Signature.PrivateKeys,
+		}))
+	}
+	if policy.Timeout > 0 {
+		opts = append(opts, rest.WithTimeout(time.Duration(policy.Timeout)*time.Millisecond))
+	}
+	if policy.MaxBytes > 0 {
+		opts = append(opts, rest.WithMaxBytes(policy.MaxBytes))
+	}
+	if len(policy.Cors.Origins) > 0 {
+		opts = append(opts, rest.WithRouteCors(policy.Cors.Origins...))
+	}
+	if policy.RateLimit.Rate > 0 {
+		burst := policy.RateLimit.Burst
+		if burst <= 0 {
+			burst = policy.RateLimit.Rate
+		}
+		opts = append(opts, rest.WithRateLimiter(rate.NewLimiter(rate.Limit(policy.RateLimit.Rate), burst)))
+	}
+
+	return opts
+}
+
+// mergePolicy returns the policy of a route, the non-empty items of route override the ones of upstream.
+func mergePolicy(upstream, route RoutePolicy) RoutePolicy {
+	policy := upstream
+
+	if len(route.Jwt.Secret) > 0 {
+		policy.Jwt = route.Jwt
+	}
+	if route.Signature.Strict || len(route.Signature.PrivateKeys) > 0 {
+		policy.Signature = route.Signature
+	}
+	if route.Timeout > 0 {
+		policy.Timeout = route.Timeout
+	}
+	if route.MaxBytes > 0 {
+		policy.MaxBytes = route.MaxBytes
+	}
+	if len(route.Cors.Origins) > 0 {
+		policy.Cors = route.Cors
+	}
+	if route.RateLimit.Rate > 0 {
+		policy.RateLimit = route.RateLimit
+	}
+
+	return policy
+}
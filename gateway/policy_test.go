@@ -0,0 +1,108 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/r27153733/fastgozero/core/conf"
+	"github.com/r27153733/fastgozero/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildRouteOptions(t *testing.T) {
+	assert.Empty(t, buildRouteOptions(RoutePolicy{}))
+
+	opts := buildRouteOptions(RoutePolicy{
+		Jwt: JwtConf{
+			Secret:     "12345678",
+			PrevSecret: "87654321",
+		},
+		Signature: SignatureConf{
+			Strict: true,
+		},
+		Timeout:  1000,
+		MaxBytes: 1024,
+		Cors: CorsConf{
+			Origins: []string{"*"},
+		},
+		RateLimit: RateLimitConf{
+			Rate: 10,
+		},
+	})
+	assert.Len(t, opts, 6)
+
+	opts = buildRouteOptions(RoutePolicy{
+		Jwt: JwtConf{
+			Secret: "12345678",
+		},
+	})
+	assert.Len(t, opts, 1)
+}
+
+func TestMergePolicy(t *testing.T) {
+	upstream := RoutePolicy{
+		Jwt: JwtConf{
+			Secret: "12345678",
+		},
+		Timeout: 1000,
+		RateLimit: RateLimitConf{
+			Rate: 100,
+		},
+	}
+	route := RoutePolicy{
+		Signature: SignatureConf{
+			PrivateKeys: []rest.PrivateKeyConf{
+				{
+					Fingerprint: "foo",
+					KeyFile:     "bar",
+				},
+			},
+		},
+		Timeout:  2000,
+		MaxBytes: 1024,
+		Cors: CorsConf{
+			Origins: []string{"foo.com"},
+		},
+	}
+
+	policy := mergePolicy(upstream, route)
+	assert.Equal(t, "12345678", policy.Jwt.Secret)
+	assert.Equal(t, route.Signature, policy.Signature)
+	assert.Equal(t, int64(2000), policy.Timeout)
+	assert.Equal(t, int64(1024), policy.MaxBytes)
+	assert.Equal(t, []string{"foo.com"}, policy.Cors.Origins)
+	assert.Equal(t, 100, policy.RateLimit.Rate)
+}
+
+func TestRoutePolicyConfig(t *testing.T) {
+	const configYaml = `
+Name: gateway
+Port: 8888
+Upstreams:
+  - Grpc:
+      Endpoints:
+        - localhost:8081
+    Policy:
+      Jwt:
+        Secret: "12345678"
+    Mappings:
+      - Method: get
+        Path: /ping
+        RpcPath: hello.Hello/Ping
+        Policy:
+          Timeout: 500
+          RateLimit:
+            Rate: 10
+      - Method: post
+        Path: /pong
+        RpcPath: hello.Hello/Pong
+`
+
+	var c GatewayConf
+	assert.NoError(t, conf.LoadFromYamlBytes([]byte(configYaml), &c))
+	up := c.Upstreams[0]
+	assert.Equal(t, "12345678", up.Policy.Jwt.Secret)
+	assert.Equal(t, int64(500), up.Mappings[0].Policy.Timeout)
+	assert.Equal(t, 10, up.Mappings[0].Policy.RateLimit.Rate)
+	assert.Equal(t, RoutePolicy{}.Cors, up.Mappings[1].Policy.Cors)
+	assert.False(t, up.Mappings[1].Policy.Signature.Strict)
+}
@@ -29,6 +29,11 @@ type (
 
 	// Option defines the method to customize Server.
 	Option func(svr *Server)
+
+	featuredRoute struct {
+		route rest.Route
+		opts  []rest.RouteOption
+	}
 )
 
 // MustNewServer creates a new gateway server.
@@ -64,7 +69,7 @@ func (s *Server) build() error {
 		for _, up := range s.upstreams {
 			source <- up
 		}
-	}, func(up Upstream, writer mr.Writer[rest.Route], cancel func(error)) {
+	}, func(up Upstream, writer mr.Writer[featuredRoute], cancel func(error)) {
 		var cli zrpc.Client
 		if s.dialer != nil {
 			cli = s.dialer(up.Grpc)
@@ -87,10 +92,13 @@ func (s *Server) build() error {
 		resolver := grpcurl.AnyResolverFromDescriptorSource(source)
 		for _, m := range methods {
 			if len(m.HttpMethod) > 0 && len(m.HttpPath) > 0 {
-				writer.Write(rest.Route{
-					Method:  m.HttpMethod,
-					Path:    m.HttpPath,
-					Handler: s.buildHandler(source, resolver, cli, m.RpcPath),
+				writer.Write(featuredRoute{
+					route: rest.Route{
+						Method:  m.HttpMethod,
+						Path:    m.HttpPath,
+						Handler: s.buildHandler(source, resolver, cli, m.RpcPath),
+					},
+					opts: buildRouteOptions(up.Policy),
 				})
 			}
 		}
@@ -105,15 +113,19 @@ func (s *Server) build() error {
 				return
 			}
 
-			writer.Write(rest.Route{
-				Method:  strings.ToUpper(m.Method),
-				Path:    m.Path,
-				Handler: s.buildHandler(source, resolver, cli, m.RpcPath),
+			policy := mergePolicy(up.Policy, m.Policy)
+			writer.Write(featuredRoute{
+				route: rest.Route{
+					Method:  strings.ToUpper(m.Method),
+					Path:    m.Path,
+					Handler: s.buildHandler(source, resolver, cli, m.RpcPath),
+				},
+				opts: buildRouteOptions(policy),
 			})
 		}
-	}, func(pipe <-chan rest.Route, cancel func(error)) {
-		for route := range pipe {
-			s.Server.AddRoute(route)
+	}, func(pipe <-chan featuredRoute, cancel func(error)) {
+		for fr := range pipe {
+			s.Server.AddRoute(fr.route, fr.opts...)
 		}
 	})
 }
@@ -5,16 +5,25 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/pprof"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/r27153733/fastgozero/core/logx"
 	"github.com/r27153733/fastgozero/core/prometheus"
 	"github.com/r27153733/fastgozero/core/threading"
 	"github.com/r27153733/fastgozero/internal/health"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
-var once sync.Once
+const grpcContentType = "application/grpc"
+
+var (
+	once        sync.Once
+	grpcHandler atomic.Value
+)
 
 // Server is an inner http server, expose some useful observability information of app.
 // For example, health check, metrics and pprof.
@@ -58,6 +67,19 @@ func (s *Server) addRoutes(c Config) {
 	}
 }
 
+// HandleGrpc serves the grpc requests on the inner http server with h, like the reflection
+// and channelz services, which keeps them away from the public ports.
+// The inner http server is shared in the process, so only the first h is served,
+// returns false if another one is already registered.
+func HandleGrpc(h http.Handler) bool {
+	if !grpcHandler.CompareAndSwap(nil, h) {
+		logx.Error("devserver: grpc handler is already registered, only the first one is served")
+		return false
+	}
+
+	return true
+}
+
 func (s *Server) handleFunc(pattern string, handler http.HandlerFunc) {
 	s.server.HandleFunc(pattern, handler)
 	s.routes = append(s.routes, pattern)
@@ -69,7 +91,9 @@ func (s *Server) StartAsync(c Config) {
 	threading.GoSafe(func() {
 		addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
 		logx.Infof("Starting dev http server at %s", addr)
-		if err := http.ListenAndServe(addr, s.server); err != nil {
+		// h2c is required to serve the grpc requests without tls.
+		handler := h2c.NewHandler(http.HandlerFunc(s.serveHTTP), &http2.Server{})
+		if err := http.ListenAndServe(addr, handler); err != nil {
 			logx.Error(err)
 		}
 	})
@@ -86,3 +110,14 @@ func StartAgent(c Config) {
 		s.StartAsync(c)
 	})
 }
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), grpcContentType) {
+		if h, ok := grpcHandler.Load().(http.Handler); ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	s.server.ServeHTTP(w, r)
+}
@@ -20,6 +20,9 @@ type (
 		Metrics    bool `json:",default=true"`
 		MaxBytes   bool `json:",default=true"`
 		Gunzip     bool `json:",default=true"`
+		// RouteTag trusts the X-Route-Tags header of the requests, only enable it on the trusted edges,
+		// otherwise any caller can route the requests to the canary or internal endpoints.
+		RouteTag bool `json:",default=false"`
 	}
 
 	// A PrivateKeyConf is a private key config.
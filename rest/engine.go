@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/valyala/fasthttp"
+	"net/http"
 	"sort"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/r27153733/fastgozero/rest/handler"
 	"github.com/r27153733/fastgozero/rest/httpx"
 	"github.com/r27153733/fastgozero/rest/internal"
+	"github.com/r27153733/fastgozero/rest/internal/cors"
 )
 
 // use 1000m to represent 100%
@@ -100,6 +102,12 @@ func (ng *engine) bindRoute(fr featuredRoutes, router httpx.Router, metrics *sta
 		chn = ng.buildChainWithNativeMiddlewares(fr, route, metrics)
 	}
 
+	if fr.cors.enabled {
+		chn = chn.Prepend(cors.Middleware(nil, fr.cors.origins...))
+	}
+	if fr.limiter != nil {
+		chn = chn.Append(handler.RateLimitHandler(fr.limiter))
+	}
 	chn = ng.appendAuthHandler(fr, chn, verifier)
 
 	for _, middleware := range ng.middlewares {
@@ -119,6 +127,39 @@ func (ng *engine) bindRoutes(router httpx.Router) error {
 		}
 	}
 
+	return ng.bindPreflightRoutes(router)
+}
+
+// bindPreflightRoutes binds OPTIONS routes for the routes with cors enabled,
+// because the preflight requests are not dispatched to the handlers of other methods.
+func (ng *engine) bindPreflightRoutes(router httpx.Router) error {
+	bound := make(map[string]struct{})
+	for _, fr := range ng.routes {
+		for _, route := range fr.routes {
+			if route.Method == http.MethodOptions {
+				bound[route.Path] = struct{}{}
+			}
+		}
+	}
+
+	for _, fr := range ng.routes {
+		if !fr.cors.enabled {
+			continue
+		}
+
+		for _, route := range fr.routes {
+			if _, ok := bound[route.Path]; ok {
+				continue
+			}
+
+			bound[route.Path] = struct{}{}
+			handle := cors.Middleware(nil, fr.cors.origins...)(func(ctx *fasthttp.RequestCtx) {})
+			if err := router.Handle(http.MethodOptions, route.Path, handle); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -161,6 +202,9 @@ func (ng *engine) buildChainWithNativeMiddlewares(fr featuredRoutes, route Route
 	if ng.conf.Middlewares.Gunzip {
 		chn = chn.Append(handler.GunzipHandler)
 	}
+	if ng.conf.Middlewares.RouteTag {
+		chn = chn.Append(handler.RouteTagHandler)
+	}
 
 	return chn
 }
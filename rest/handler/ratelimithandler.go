@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/r27153733/fastgozero/fastext/bytesconv"
+	"github.com/r27153733/fastgozero/rest/internal"
+	"github.com/valyala/fasthttp"
+)
+
+// A Limiter is used to decide whether a request is allowed to pass.
+// Both *limit.TokenLimiter and *rate.Limiter satisfy this interface.
+type Limiter interface {
+	Allow() bool
+}
+
+// RateLimitHandler returns a middleware that rejects the requests exceeding the rate of limiter.
+func RateLimitHandler(limiter Limiter) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if limiter == nil {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return next
+		}
+	}
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			if !limiter.Allow() {
+				internal.Errorf(ctx, "rate limited, %s - %s, rejected with code %d",
+					bytesconv.BToS(ctx.Method()), bytesconv.BToS(ctx.RequestURI()),
+					fasthttp.StatusTooManyRequests)
+				ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
+				return
+			}
+
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+	handle := RateLimitHandler(limiter)(func(ctx *fasthttp.RequestCtx) {})
+
+	var ctx fasthttp.RequestCtx
+	handle(&ctx)
+	assert.Equal(t, http.StatusOK, ctx.Response.StatusCode())
+
+	ctx.Response.Reset()
+	handle(&ctx)
+	assert.Equal(t, http.StatusTooManyRequests, ctx.Response.StatusCode())
+}
+
+func TestRateLimitHandlerNoLimiter(t *testing.T) {
+	handle := RateLimitHandler(nil)(func(ctx *fasthttp.RequestCtx) {})
+
+	var ctx fasthttp.RequestCtx
+	handle(&ctx)
+	assert.Equal(t, http.StatusOK, ctx.Response.StatusCode())
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"github.com/r27153733/fastgozero/core/routetag"
+	"github.com/r27153733/fastgozero/fastext/fastctx"
+	"github.com/valyala/fasthttp"
+)
+
+// RouteTagHandler returns a middleware that puts the route tags in the X-Route-Tags header
+// into the request context, which are propagated to the zrpc calls to route the requests
+// to the endpoints with matching tags, like version=v2 for canary releases.
+func RouteTagHandler(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		tags := ctx.Request.Header.Peek(routetag.HeaderKey)
+		if len(tags) == 0 {
+			next(ctx)
+			return
+		}
+
+		free := fastctx.SetUserValueCtx(ctx, routetag.ContextKey, string(tags))
+		defer free()
+
+		next(ctx)
+	}
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/r27153733/fastgozero/core/routetag"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRouteTagHandler(t *testing.T) {
+	var tags string
+	handle := RouteTagHandler(func(ctx *fasthttp.RequestCtx) {
+		tags = routetag.FromContext(ctx)
+	})
+
+	var ctx fasthttp.RequestCtx
+	handle(&ctx)
+	assert.Empty(t, tags)
+
+	ctx.Request.Header.Set(routetag.HeaderKey, "version=v2")
+	handle(&ctx)
+	assert.Equal(t, "version=v2", tags)
+	assert.Nil(t, ctx.UserValue(routetag.ContextKey))
+}
@@ -286,6 +286,22 @@ func WithPriority() RouteOption {
 	}
 }
 
+// WithRateLimiter returns a RouteOption to limit the request rate of given routes with limiter.
+func WithRateLimiter(limiter handler.Limiter) RouteOption {
+	return func(r *featuredRoutes) {
+		r.limiter = limiter
+	}
+}
+
+// WithRouteCors returns a RouteOption to enable CORS for given routes with origins,
+// or default to all origins (*).
+func WithRouteCors(origins ...string) RouteOption {
+	return func(r *featuredRoutes) {
+		r.cors.enabled = true
+		r.cors.origins = origins
+	}
+}
+
 // WithRouter returns a RunOption that make server run with given router.
 func WithRouter(router httpx.Router) RunOption {
 	return func(server *Server) {
@@ -12,6 +12,7 @@ import (
 	"github.com/r27153733/fastgozero/rest/router"
 	"github.com/stretchr/testify/assert"
 	"github.com/valyala/fasthttp"
+	"golang.org/x/time/rate"
 	"io"
 	"io/fs"
 	"net/http"
@@ -341,6 +342,20 @@ func TestWithPriority(t *testing.T) {
 	assert.True(t, fr.priority)
 }
 
+func TestWithRateLimiter(t *testing.T) {
+	var fr featuredRoutes
+	limiter := rate.NewLimiter(rate.Every(time.Second), 1)
+	WithRateLimiter(limiter)(&fr)
+	assert.Equal(t, limiter, fr.limiter)
+}
+
+func TestWithRouteCors(t *testing.T) {
+	var fr featuredRoutes
+	WithRouteCors("foo.com")(&fr)
+	assert.True(t, fr.cors.enabled)
+	assert.EqualValues(t, []string{"foo.com"}, fr.cors.origins)
+}
+
 func TestWithTimeout(t *testing.T) {
 	var fr featuredRoutes
 	WithTimeout(time.Hour)(&fr)
@@ -678,6 +693,44 @@ func TestServer_WithCors(t *testing.T) {
 	assert.Equal(t, int32(0), atomic.LoadInt32(&called))
 }
 
+func TestServer_WithRouteCors(t *testing.T) {
+	server := MustNewServer(RestConf{})
+	server.AddRoutes([]Route{
+		{
+			Method:  http.MethodGet,
+			Path:    "/cors",
+			Handler: func(_ *fasthttp.RequestCtx) {},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/cors",
+			Handler: func(_ *fasthttp.RequestCtx) {},
+		},
+	}, WithRouteCors("foo.com"))
+	server.AddRoute(Route{
+		Method:  http.MethodGet,
+		Path:    "/plain",
+		Handler: func(_ *fasthttp.RequestCtx) {},
+	})
+	rt := router.NewRouter()
+	assert.Nil(t, server.ngin.bindRoutes(rt))
+
+	r := new(fasthttp.RequestCtx)
+	r.Request.Header.SetMethod(fasthttp.MethodOptions)
+	r.Request.Header.Set("Origin", "foo.com")
+	r.Request.SetRequestURI("/cors")
+	rt.ServeHTTP(r)
+	assert.Equal(t, http.StatusNoContent, r.Response.StatusCode())
+	assert.Equal(t, "foo.com", string(r.Response.Header.Peek("Access-Control-Allow-Origin")))
+
+	r = new(fasthttp.RequestCtx)
+	r.Request.Header.SetMethod(fasthttp.MethodGet)
+	r.Request.Header.Set("Origin", "foo.com")
+	r.Request.SetRequestURI("/plain")
+	rt.ServeHTTP(r)
+	assert.Empty(t, r.Response.Header.Peek("Access-Control-Allow-Origin"))
+}
+
 func TestServer_ServeHTTP(t *testing.T) {
 	const configYaml = `
 Name: foo
@@ -41,6 +41,12 @@ func NewTokenParser(opts ...ParseOption) *TokenParser {
 
 // ParseToken parses token from given r, with passed in secret and prevSecret.
 func (tp *TokenParser) ParseToken(r *fasthttp.Request, secret, prevSecret string) (*jwt.Token, error) {
+	return tp.ParseTokenString(string(r.Header.Peek(authorization)), secret, prevSecret)
+}
+
+// ParseTokenString parses the given token string, with passed in secret and prevSecret.
+// The token string can be prefixed with Bearer, like the value of Authorization header.
+func (tp *TokenParser) ParseTokenString(tokenStr, secret, prevSecret string) (*jwt.Token, error) {
 	var token *jwt.Token
 	var err error
 
@@ -57,9 +63,9 @@ func (tp *TokenParser) ParseToken(r *fasthttp.Request, secret, prevSecret string
 			second = secret
 		}
 
-		token, err = tp.doParseToken(r, first)
+		token, err = tp.doParseToken(tokenStr, first)
 		if err != nil {
-			token, err = tp.doParseToken(r, second)
+			token, err = tp.doParseToken(tokenStr, second)
 			if err != nil {
 				return nil, err
 			}
@@ -69,7 +75,7 @@ func (tp *TokenParser) ParseToken(r *fasthttp.Request, secret, prevSecret string
 			tp.incrementCount(first)
 		}
 	} else {
-		token, err = tp.doParseToken(r, secret)
+		token, err = tp.doParseToken(tokenStr, secret)
 		if err != nil {
 			return nil, err
 		}
@@ -80,8 +86,7 @@ func (tp *TokenParser) ParseToken(r *fasthttp.Request, secret, prevSecret string
 
 const authorization = "Authorization"
 
-func (tp *TokenParser) doParseToken(r *fasthttp.Request, secret string) (*jwt.Token, error) {
-	tokenStr := string(r.Header.Peek(authorization))
+func (tp *TokenParser) doParseToken(tokenStr, secret string) (*jwt.Token, error) {
 	if len(tokenStr) > 6 && strings.ToUpper(tokenStr[0:7]) == "BEARER " {
 		tokenStr = tokenStr[7:]
 	}
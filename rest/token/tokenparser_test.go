@@ -78,6 +78,25 @@ func TestTokenParser_Expired(t *testing.T) {
 	assert.Equal(t, "value", tok.Claims.(jwt.MapClaims)["key"])
 }
 
+func TestTokenParser_ParseTokenString(t *testing.T) {
+	const key = "14F17379-EB8F-411B-8F12-6929002DCA76"
+
+	token, err := buildToken(key, map[string]any{
+		"key": "value",
+	}, 3600)
+	assert.Nil(t, err)
+
+	parser := NewTokenParser()
+	for _, tokenStr := range []string{token, "Bearer " + token, "bearer " + token} {
+		tok, err := parser.ParseTokenString(tokenStr, key, "")
+		assert.Nil(t, err)
+		assert.Equal(t, "value", tok.Claims.(jwt.MapClaims)["key"])
+	}
+
+	_, err = parser.ParseTokenString(token, "B63F477D-BBA3-4E52-96D3-C0034C27694A", "")
+	assert.NotNil(t, err)
+}
+
 func buildToken(secretKey string, payloads map[string]any, seconds int64) (string, error) {
 	now := time.Now().Unix()
 	claims := make(jwt.MapClaims)
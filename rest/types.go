@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"github.com/r27153733/fastgozero/rest/handler"
 	"github.com/valyala/fasthttp"
 	"time"
 )
@@ -30,11 +31,18 @@ type (
 		enabled bool
 	}
 
+	corsSetting struct {
+		enabled bool
+		origins []string
+	}
+
 	featuredRoutes struct {
 		timeout   time.Duration
 		priority  bool
 		jwt       jwtSetting
 		signature signatureSetting
+		cors      corsSetting
+		limiter   handler.Limiter
 		routes    []Route
 		maxBytes  int64
 	}
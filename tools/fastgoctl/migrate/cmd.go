@@ -1,16 +1,35 @@
 package migrate
 
-import "github.com/r27153733/fastgozero/tools/fastgoctl/internal/cobrax"
+import (
+	"github.com/r27153733/fastgozero/core/stores/sqlx"
+	"github.com/r27153733/fastgozero/tools/fastgoctl/internal/cobrax"
+)
 
 var (
 	boolVarVerbose   bool
 	stringVarVersion string
 	// Cmd describes a migrate command.
 	Cmd = cobrax.NewCommand("migrate", cobrax.WithRunE(migrate))
+
+	sqlCmd       = cobrax.NewCommand("sql")
+	sqlUpCmd     = cobrax.NewCommand("up", cobrax.WithRunE(migrateSqlUp))
+	sqlDownCmd   = cobrax.NewCommand("down", cobrax.WithRunE(migrateSqlDown))
+	sqlStatusCmd = cobrax.NewCommand("status", cobrax.WithRunE(migrateSqlStatus))
 )
 
 func init() {
 	migrateCmdFlags := Cmd.Flags()
 	migrateCmdFlags.BoolVarP(&boolVarVerbose, "verbose", "v")
 	migrateCmdFlags.StringVarWithDefaultValue(&stringVarVersion, "version", defaultMigrateVersion)
+
+	sqlCmdFlags := sqlCmd.PersistentFlags()
+	sqlCmdFlags.StringVar(&stringVarSqlURL, "url")
+	sqlCmdFlags.StringVarPWithDefaultValue(&stringVarSqlDir, "dir", "d", defaultMigrationDir)
+	sqlCmdFlags.StringVarWithDefaultValue(&stringVarSqlDialect, "dialect", sqlx.MysqlDialect)
+	sqlCmdFlags.StringVar(&stringVarSqlTable, "table")
+	sqlCmdFlags.BoolVar(&boolVarSqlDryRun, "dry-run")
+	sqlDownCmd.Flags().IntVarWithDefaultValue(&intVarSqlSteps, "steps", 1)
+
+	sqlCmd.AddCommand(sqlUpCmd, sqlDownCmd, sqlStatusCmd)
+	Cmd.AddCommand(sqlCmd)
 }
@@ -0,0 +1,137 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/stores/postgres"
+	"github.com/r27153733/fastgozero/core/stores/sqlx"
+	"github.com/r27153733/fastgozero/tools/fastgoctl/util/console"
+	"github.com/spf13/cobra"
+)
+
+const defaultMigrationDir = "migrations"
+
+var (
+	// stringVarSqlURL describes the data source of the database.
+	stringVarSqlURL string
+	// stringVarSqlDir describes the directory of the migration files.
+	stringVarSqlDir string
+	// stringVarSqlDialect describes the database dialect, mysql or postgres.
+	stringVarSqlDialect string
+	// stringVarSqlTable describes the migration history table.
+	stringVarSqlTable string
+	// boolVarSqlDryRun describes whether to only print the migrations to apply or revert.
+	boolVarSqlDryRun bool
+	// intVarSqlSteps describes the number of the migrations to revert.
+	intVarSqlSteps int
+
+	errEmptySqlURL = errors.New("missing --url")
+)
+
+func migrateSqlDown(_ *cobra.Command, _ []string) error {
+	m, err := newMigrator()
+	if err != nil {
+		return err
+	}
+
+	reverted, err := m.Down(context.Background(), intVarSqlSteps)
+	for _, migration := range reverted {
+		printMigration("revert", migration)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(reverted) == 0 {
+		console.Info("no migrations to revert")
+	}
+	return nil
+}
+
+func migrateSqlStatus(_ *cobra.Command, _ []string) error {
+	m, err := newMigrator()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	for _, status := range statuses {
+		state := "pending"
+		if status.Applied {
+			state = "applied at " + status.AppliedAt.Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, state)
+	}
+
+	return w.Flush()
+}
+
+func migrateSqlUp(_ *cobra.Command, _ []string) error {
+	m, err := newMigrator()
+	if err != nil {
+		return err
+	}
+
+	applied, err := m.Up(context.Background())
+	for _, migration := range applied {
+		printMigration("apply", migration)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		console.Info("no migrations to apply")
+	}
+	return nil
+}
+
+func newMigrator() (*sqlx.Migrator, error) {
+	if len(stringVarSqlURL) == 0 {
+		return nil, errEmptySqlURL
+	}
+
+	var conn sqlx.SqlConn
+	switch stringVarSqlDialect {
+	case sqlx.MysqlDialect:
+		conn = sqlx.NewMysql(stringVarSqlURL)
+	case sqlx.PostgresDialect:
+		conn = postgres.New(stringVarSqlURL)
+	default:
+		return nil, sqlx.ErrUnknownDialect
+	}
+
+	opts := []sqlx.MigrateOption{sqlx.WithMigrationDialect(stringVarSqlDialect)}
+	if len(stringVarSqlTable) > 0 {
+		opts = append(opts, sqlx.WithMigrationTable(stringVarSqlTable))
+	}
+	if boolVarSqlDryRun {
+		opts = append(opts, sqlx.WithMigrationDryRun())
+	}
+
+	return sqlx.NewMigrator(conn, os.DirFS(stringVarSqlDir), opts...)
+}
+
+func printMigration(action string, migration sqlx.Migration) {
+	if !boolVarSqlDryRun {
+		console.Success("[OK] %s %d_%s", action, migration.Version, migration.Name)
+		return
+	}
+
+	script := migration.Up
+	if action == "revert" {
+		script = migration.Down
+	}
+	console.Info("[DRY RUN] %s %d_%s:\n%s", action, migration.Version, migration.Name, script)
+}
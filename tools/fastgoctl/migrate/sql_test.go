@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/r27153733/fastgozero/core/stores/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMigrator(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0001_create_users.up.sql"),
+		[]byte("create table users (id bigint);"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0001_create_users.down.sql"),
+		[]byte("drop table users;"), 0o644))
+	resetSqlVars(t)
+
+	_, err := newMigrator()
+	assert.Equal(t, errEmptySqlURL, err)
+	assert.Equal(t, errEmptySqlURL, migrateSqlUp(nil, nil))
+	assert.Equal(t, errEmptySqlURL, migrateSqlDown(nil, nil))
+	assert.Equal(t, errEmptySqlURL, migrateSqlStatus(nil, nil))
+
+	stringVarSqlURL = "root:pass@tcp(localhost:3306)/test"
+	stringVarSqlDir = dir
+	stringVarSqlDialect = "oracle"
+	_, err = newMigrator()
+	assert.Equal(t, sqlx.ErrUnknownDialect, err)
+
+	for _, dialect := range []string{sqlx.MysqlDialect, sqlx.PostgresDialect} {
+		stringVarSqlDialect = dialect
+		stringVarSqlTable = "migrations"
+		boolVarSqlDryRun = true
+		m, err := newMigrator()
+		assert.NoError(t, err)
+		assert.NotNil(t, m)
+	}
+
+	stringVarSqlDir = filepath.Join(dir, "not-exists")
+	_, err = newMigrator()
+	assert.Error(t, err)
+}
+
+func TestPrintMigration(t *testing.T) {
+	resetSqlVars(t)
+	migration := sqlx.Migration{
+		Version: 1,
+		Name:    "create_users",
+		Up:      "create table users (id bigint);",
+		Down:    "drop table users;",
+	}
+	printMigration("apply", migration)
+	boolVarSqlDryRun = true
+	printMigration("apply", migration)
+	printMigration("revert", migration)
+}
+
+func resetSqlVars(t *testing.T) {
+	url, dir, dialect, table, dryRun := stringVarSqlURL, stringVarSqlDir, stringVarSqlDialect,
+		stringVarSqlTable, boolVarSqlDryRun
+	t.Cleanup(func() {
+		stringVarSqlURL, stringVarSqlDir, stringVarSqlDialect = url, dir, dialect
+		stringVarSqlTable, boolVarSqlDryRun = table, dryRun
+	})
+
+	stringVarSqlURL = ""
+	stringVarSqlDir = defaultMigrationDir
+	stringVarSqlDialect = sqlx.MysqlDialect
+	stringVarSqlTable = ""
+	boolVarSqlDryRun = false
+}
@@ -58,6 +58,11 @@ func init() {
 	pgDatasourceCmdFlags.StringVar(&command.VarStringBranch, "branch")
 	pgCmd.PersistentFlags().StringSliceVarPWithDefaultValue(&command.VarStringSliceIgnoreColumns,
 		"ignore-columns", "i", []string{"create_at", "created_at", "create_time", "update_at", "updated_at", "update_time"})
+	pgCmd.PersistentFlags().StringVarWithDefaultValue(&command.VarStringVersionColumn, "version-column", "version")
+	pgCmd.PersistentFlags().StringVarWithDefaultValue(&command.VarStringSoftDeleteColumn,
+		"soft-delete-column", "deleted_at")
+	pgCmd.PersistentFlags().StringVar(&command.VarStringCreatedColumn, "created-column")
+	pgCmd.PersistentFlags().StringVar(&command.VarStringUpdatedColumn, "updated-column")
 
 	mongoCmdFlags.StringSliceVarP(&mongo.VarStringSliceType, "type", "t")
 	mongoCmdFlags.BoolVarP(&mongo.VarBoolCache, "cache", "c")
@@ -71,6 +76,11 @@ func init() {
 	mysqlCmd.PersistentFlags().BoolVar(&command.VarBoolStrict, "strict")
 	mysqlCmd.PersistentFlags().StringSliceVarPWithDefaultValue(&command.VarStringSliceIgnoreColumns,
 		"ignore-columns", "i", []string{"create_at", "created_at", "create_time", "update_at", "updated_at", "update_time"})
+	mysqlCmd.PersistentFlags().StringVarWithDefaultValue(&command.VarStringVersionColumn, "version-column", "version")
+	mysqlCmd.PersistentFlags().StringVarWithDefaultValue(&command.VarStringSoftDeleteColumn,
+		"soft-delete-column", "deleted_at")
+	mysqlCmd.PersistentFlags().StringVar(&command.VarStringCreatedColumn, "created-column")
+	mysqlCmd.PersistentFlags().StringVar(&command.VarStringUpdatedColumn, "updated-column")
 
 	mysqlCmd.AddCommand(datasourceCmd, ddlCmd)
 	pgCmd.AddCommand(pgDatasourceCmd)
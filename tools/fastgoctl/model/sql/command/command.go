@@ -50,6 +50,14 @@ var (
 	VarBoolStrict bool
 	// VarStringSliceIgnoreColumns represents the columns which are ignored.
 	VarStringSliceIgnoreColumns []string
+	// VarStringVersionColumn describes the column for the optimistic locking.
+	VarStringVersionColumn string
+	// VarStringSoftDeleteColumn describes the column to mark the rows deleted.
+	VarStringSoftDeleteColumn string
+	// VarStringCreatedColumn describes the column set to the current time on insert.
+	VarStringCreatedColumn string
+	// VarStringUpdatedColumn describes the column set to the current time on insert and update.
+	VarStringUpdatedColumn string
 )
 
 var errNotMatched = errors.New("sql not matched")
@@ -89,6 +97,7 @@ func MysqlDDL(_ *cobra.Command, _ []string) error {
 		database:      database,
 		strict:        VarBoolStrict,
 		ignoreColumns: mergeColumns(VarStringSliceIgnoreColumns),
+		columns:       conventionColumns(),
 	}
 	return fromDDL(arg)
 }
@@ -130,10 +139,20 @@ func MySqlDataSource(_ *cobra.Command, _ []string) error {
 		idea:          idea,
 		strict:        VarBoolStrict,
 		ignoreColumns: mergeColumns(VarStringSliceIgnoreColumns),
+		columns:       conventionColumns(),
 	}
 	return fromMysqlDataSource(arg)
 }
 
+func conventionColumns() gen.ConventionColumns {
+	return gen.ConventionColumns{
+		Version:    strings.TrimSpace(VarStringVersionColumn),
+		SoftDelete: strings.TrimSpace(VarStringSoftDeleteColumn),
+		Created:    strings.TrimSpace(VarStringCreatedColumn),
+		Updated:    strings.TrimSpace(VarStringUpdatedColumn),
+	}
+}
+
 func mergeColumns(columns []string) []string {
 	set := collection.NewSet()
 	for _, v := range columns {
@@ -215,7 +234,8 @@ func PostgreSqlDataSource(_ *cobra.Command, _ []string) error {
 	}
 	ignoreColumns := mergeColumns(VarStringSliceIgnoreColumns)
 
-	return fromPostgreSqlDataSource(url, patterns, dir, schema, cfg, cache, idea, VarBoolStrict, ignoreColumns)
+	return fromPostgreSqlDataSource(url, patterns, dir, schema, cfg, cache, idea, VarBoolStrict,
+		ignoreColumns, conventionColumns())
 }
 
 type ddlArg struct {
@@ -225,6 +245,7 @@ type ddlArg struct {
 	database      string
 	strict        bool
 	ignoreColumns []string
+	columns       gen.ConventionColumns
 }
 
 func fromDDL(arg ddlArg) error {
@@ -244,7 +265,8 @@ func fromDDL(arg ddlArg) error {
 	}
 
 	generator, err := gen.NewDefaultGenerator(arg.dir, arg.cfg,
-		gen.WithConsoleOption(log), gen.WithIgnoreColumns(arg.ignoreColumns))
+		gen.WithConsoleOption(log), gen.WithIgnoreColumns(arg.ignoreColumns),
+		gen.WithConventionColumns(arg.columns))
 	if err != nil {
 		return err
 	}
@@ -266,6 +288,7 @@ type dataSourceArg struct {
 	cache, idea   bool
 	strict        bool
 	ignoreColumns []string
+	columns       gen.ConventionColumns
 }
 
 func fromMysqlDataSource(arg dataSourceArg) error {
@@ -319,7 +342,8 @@ func fromMysqlDataSource(arg dataSourceArg) error {
 	}
 
 	generator, err := gen.NewDefaultGenerator(arg.dir, arg.cfg,
-		gen.WithConsoleOption(log), gen.WithIgnoreColumns(arg.ignoreColumns))
+		gen.WithConsoleOption(log), gen.WithIgnoreColumns(arg.ignoreColumns),
+		gen.WithConventionColumns(arg.columns))
 	if err != nil {
 		return err
 	}
@@ -327,7 +351,8 @@ func fromMysqlDataSource(arg dataSourceArg) error {
 	return generator.StartFromInformationSchema(matchTables, arg.cache, arg.strict)
 }
 
-func fromPostgreSqlDataSource(url string, pattern pattern, dir, schema string, cfg *config.Config, cache, idea, strict bool, ignoreColumns []string) error {
+func fromPostgreSqlDataSource(url string, pattern pattern, dir, schema string, cfg *config.Config, cache, idea, strict bool,
+	ignoreColumns []string, columns gen.ConventionColumns) error {
 	log := console.NewConsole(idea)
 	if len(url) == 0 {
 		log.Error("%v", "expected data source of postgresql, but nothing found")
@@ -369,7 +394,8 @@ func fromPostgreSqlDataSource(url string, pattern pattern, dir, schema string, c
 		return errors.New("no tables matched")
 	}
 
-	generator, err := gen.NewDefaultGenerator(dir, cfg, gen.WithConsoleOption(log), gen.WithPostgreSql(), gen.WithIgnoreColumns(ignoreColumns))
+	generator, err := gen.NewDefaultGenerator(dir, cfg, gen.WithConsoleOption(log), gen.WithPostgreSql(),
+		gen.WithIgnoreColumns(ignoreColumns), gen.WithConventionColumns(columns))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,190 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/r27153733/fastgozero/tools/fastgoctl/model/sql/parser"
+	"github.com/r27153733/fastgozero/tools/fastgoctl/util"
+)
+
+const nowVariable = "now"
+
+var integerTypes = map[string]struct{}{
+	"int":    {},
+	"int32":  {},
+	"int64":  {},
+	"uint":   {},
+	"uint32": {},
+	"uint64": {},
+}
+
+// ConventionColumns is the columns managed by the generated models, empty to disable.
+type ConventionColumns struct {
+	// Version is the integer column for the optimistic locking,
+	// Update only updates the row with the same version, and increases it.
+	Version string
+	// SoftDelete is the nullable time or integer column to mark the rows deleted,
+	// Delete sets it instead of deleting the rows, and the finds ignore the deleted rows.
+	SoftDelete string
+	// Created is the column set to the current time on insert.
+	Created string
+	// Updated is the column set to the current time on insert and update.
+	Updated string
+}
+
+// WithConventionColumns sets the columns managed by the generated models.
+func WithConventionColumns(columns ConventionColumns) Option {
+	return func(generator *defaultGenerator) {
+		generator.conventionColumns = columns
+	}
+}
+
+// resolveConventionColumns finds the convention columns in table,
+// the columns with unsupported types are ignored with warnings.
+func (g *defaultGenerator) resolveConventionColumns(table *Table) {
+	table.versionField = g.findConventionField(table, g.conventionColumns.Version, "version",
+		func(field *parser.Field) bool {
+			return isIntegerType(field.DataType)
+		})
+	table.softDeleteField = g.findConventionField(table, g.conventionColumns.SoftDelete, "soft delete",
+		func(field *parser.Field) bool {
+			return field.DataType == "sql.NullTime" || isIntegerType(field.DataType)
+		})
+	table.createdField = g.findConventionField(table, g.conventionColumns.Created, "created time",
+		func(field *parser.Field) bool {
+			return len(timeValue(field)) > 0
+		})
+	table.updatedField = g.findConventionField(table, g.conventionColumns.Updated, "updated time",
+		func(field *parser.Field) bool {
+			return len(timeValue(field)) > 0
+		})
+}
+
+func (g *defaultGenerator) findConventionField(table *Table, column, usage string,
+	supported func(field *parser.Field) bool) *parser.Field {
+	if len(column) == 0 {
+		return nil
+	}
+
+	for _, field := range table.Fields {
+		if field.Name.Source() != column {
+			continue
+		}
+
+		if field.Name.Source() == table.PrimaryKey.Name.Source() {
+			return nil
+		}
+
+		if !supported(field) {
+			g.Warning("[%s]: the %s column %q of type %s is not supported, ignored",
+				table.Name.Source(), usage, column, field.DataType)
+			return nil
+		}
+
+		return field
+	}
+
+	return nil
+}
+
+// deletedValue returns the value to mark the rows deleted.
+func (t Table) deletedValue() string {
+	if t.softDeleteField == nil {
+		return ""
+	}
+
+	if isIntegerType(t.softDeleteField.DataType) {
+		return "time.Now().Unix()"
+	}
+
+	return "time.Now()"
+}
+
+// isInsertIgnored returns true if the column is not set on insert.
+func (t Table) isInsertIgnored(column string) bool {
+	if isField(t.createdField, column) || isField(t.updatedField, column) {
+		return false
+	}
+
+	return t.isIgnoreColumns(column)
+}
+
+// isUpdateIgnored returns true if the column is not set by the values on update.
+func (t Table) isUpdateIgnored(column string) bool {
+	if isField(t.updatedField, column) {
+		return false
+	}
+
+	if isField(t.createdField, column) || isField(t.versionField, column) ||
+		isField(t.softDeleteField, column) {
+		return true
+	}
+
+	return t.isIgnoreColumns(column)
+}
+
+// notDeletedCond returns the condition of the rows not deleted, empty if no soft delete.
+func (t Table) notDeletedCond(postgreSql bool) string {
+	if t.softDeleteField == nil {
+		return ""
+	}
+
+	column := wrapWithRawString(t.softDeleteField.NameOriginal, postgreSql)
+	if isIntegerType(t.softDeleteField.DataType) {
+		return column + " = 0"
+	}
+
+	return column + " is null"
+}
+
+// timeAssigns returns the statements to set the time columns of data,
+// with the now variable declared if any.
+func (t Table) timeAssigns(data string, fields ...*parser.Field) string {
+	var assigns []string
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+
+		assigns = append(assigns, fmt.Sprintf("%s.%s = %s", data,
+			util.SafeString(field.Name.ToCamel()), timeValue(field)))
+	}
+	if len(assigns) == 0 {
+		return ""
+	}
+
+	return strings.Join(append([]string{nowVariable + " := time.Now()"}, assigns...), "\n")
+}
+
+// usesTime returns true if the generated code uses the time package for the convention columns.
+func (t Table) usesTime() bool {
+	return t.softDeleteField != nil || t.createdField != nil || t.updatedField != nil
+}
+
+func isField(field *parser.Field, column string) bool {
+	return field != nil && field.Name.Source() == column
+}
+
+func isIntegerType(dataType string) bool {
+	_, ok := integerTypes[dataType]
+	return ok
+}
+
+// timeValue returns the expression of the current time in the type of field, empty if not supported.
+func timeValue(field *parser.Field) string {
+	switch {
+	case field.DataType == "time.Time":
+		return nowVariable
+	case field.DataType == "sql.NullTime":
+		return fmt.Sprintf("sql.NullTime{Time: %s, Valid: true}", nowVariable)
+	case field.DataType == "sql.NullInt64":
+		return fmt.Sprintf("sql.NullInt64{Int64: %s.Unix(), Valid: true}", nowVariable)
+	case field.DataType == "int64":
+		return nowVariable + ".Unix()"
+	case isIntegerType(field.DataType):
+		return fmt.Sprintf("%s(%s.Unix())", field.DataType, nowVariable)
+	default:
+		return ""
+	}
+}
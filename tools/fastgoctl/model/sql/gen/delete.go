@@ -25,6 +25,11 @@ func genDelete(table Table, withCache, postgreSql bool) (string, string, error)
 	keyVars := keyVariableSet.KeysStr()
 	sort.Strings(keyVars)
 
+	var softDeleteColumn string
+	if table.softDeleteField != nil {
+		softDeleteColumn = wrapWithRawString(table.softDeleteField.NameOriginal, postgreSql)
+	}
+
 	camel := table.Name.ToCamel()
 	text, err := pathx.LoadTemplate(category, deleteTemplateFile, template.Delete)
 	if err != nil {
@@ -43,6 +48,9 @@ func genDelete(table Table, withCache, postgreSql bool) (string, string, error)
 			"originalPrimaryKey":        wrapWithRawString(table.PrimaryKey.Name.Source(), postgreSql),
 			"keyValues":                 strings.Join(keyVars, ", "),
 			"postgreSql":                postgreSql,
+			"softDeleteColumn":          softDeleteColumn,
+			"deletedValue":              table.deletedValue(),
+			"notDeleted":                table.notDeletedCond(postgreSql),
 			"data":                      table,
 		})
 	if err != nil {
@@ -0,0 +1,48 @@
+package gen
+
+import (
+	"github.com/r27153733/fastgozero/tools/fastgoctl/model/sql/template"
+	"github.com/r27153733/fastgozero/tools/fastgoctl/util"
+	"github.com/r27153733/fastgozero/tools/fastgoctl/util/pathx"
+	"github.com/r27153733/fastgozero/tools/fastgoctl/util/stringx"
+)
+
+func genFindPage(table Table, withCache, postgreSql bool) (string, string, error) {
+	camel := table.Name.ToCamel()
+	text, err := pathx.LoadTemplate(category, findPageTemplateFile, template.FindPage)
+	if err != nil {
+		return "", "", err
+	}
+
+	output, err := util.With("findPage").
+		Parse(text).
+		Execute(map[string]any{
+			"withCache":             withCache,
+			"upperStartCamelObject": camel,
+			"lowerStartCamelObject": stringx.From(camel).Untitle(),
+			"originalPrimaryKey":    wrapWithRawString(table.PrimaryKey.Name.Source(), postgreSql),
+			"postgreSql":            postgreSql,
+			"notDeleted":            table.notDeletedCond(postgreSql),
+			"data":                  table,
+		})
+	if err != nil {
+		return "", "", err
+	}
+
+	text, err = pathx.LoadTemplate(category, findPageMethodTemplateFile, template.FindPageMethod)
+	if err != nil {
+		return "", "", err
+	}
+
+	findPageMethod, err := util.With("findPageMethod").
+		Parse(text).
+		Execute(map[string]any{
+			"upperStartCamelObject": camel,
+			"data":                  table,
+		})
+	if err != nil {
+		return "", "", err
+	}
+
+	return output.String(), findPageMethod.String(), nil
+}
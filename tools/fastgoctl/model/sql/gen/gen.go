@@ -30,6 +30,8 @@ type (
 		cfg           *config.Config
 		isPostgreSql  bool
 		ignoreColumns []string
+		// the columns managed by the generated models.
+		conventionColumns ConventionColumns
 	}
 
 	// Option defines a function with argument defaultGenerator
@@ -244,6 +246,11 @@ type Table struct {
 	UniqueCacheKey         []Key
 	ContainsUniqueCacheKey bool
 	ignoreColumns          []string
+	// the convention columns found in the table, nil if not found.
+	versionField    *parser.Field
+	softDeleteField *parser.Field
+	createdField    *parser.Field
+	updatedField    *parser.Field
 }
 
 func (t Table) isIgnoreColumns(columnName string) bool {
@@ -268,8 +275,9 @@ func (g *defaultGenerator) genModel(in parser.Table, withCache bool) (string, er
 	table.UniqueCacheKey = uniqueKey
 	table.ContainsUniqueCacheKey = len(uniqueKey) > 0
 	table.ignoreColumns = g.ignoreColumns
+	g.resolveConventionColumns(&table)
 
-	importsCode, err := genImports(table, withCache, in.ContainsTime())
+	importsCode, err := genImports(table, withCache, in.ContainsTime() || table.usesTime())
 	if err != nil {
 		return "", err
 	}
@@ -295,7 +303,12 @@ func (g *defaultGenerator) genModel(in parser.Table, withCache bool) (string, er
 		return "", err
 	}
 
-	findCode = append(findCode, findOneCode, ret.findOneMethod)
+	findPageCode, findPageCodeMethod, err := genFindPage(table, withCache, g.isPostgreSql)
+	if err != nil {
+		return "", err
+	}
+
+	findCode = append(findCode, findOneCode, ret.findOneMethod, findPageCode)
 	updateCode, updateCodeMethod, err := genUpdate(table, withCache, g.isPostgreSql)
 	if err != nil {
 		return "", err
@@ -308,7 +321,7 @@ func (g *defaultGenerator) genModel(in parser.Table, withCache bool) (string, er
 
 	var list []string
 	list = append(list, insertCodeMethod, findOneCodeMethod, ret.findOneInterfaceMethod,
-		updateCodeMethod, deleteCodeMethod)
+		findPageCodeMethod, updateCodeMethod, deleteCodeMethod)
 	typesCode, err := genTypes(table, strings.Join(modelutil.TrimStringSlice(list), pathx.NL), withCache)
 	if err != nil {
 		return "", err
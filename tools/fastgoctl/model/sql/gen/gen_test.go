@@ -198,3 +198,105 @@ func Test_genPublicModel(t *testing.T) {
 	assert.True(t, strings.Contains(code, "customTestUserModel struct {\n\t\t*defaultTestUserModel\n\t}\n"))
 	assert.True(t, strings.Contains(code, "func NewTestUserModel(conn sqlx.SqlConn) TestUserModel {"))
 }
+
+func TestGenVarsColumns(t *testing.T) {
+	dir := pathx.MustTempDir()
+	defer os.RemoveAll(dir)
+
+	sqlFile := filepath.Join(dir, "user.sql")
+	require.NoError(t, os.WriteFile(sqlFile, []byte(source), 0o777))
+	tables, err := parser.Parse(sqlFile, "", false)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(tables))
+
+	var table Table
+	table.Table = *tables[0]
+	code, err := genVars(table, false, false)
+	assert.NoError(t, err)
+	assert.Contains(t, code, "testUserColumnId         = \"`id`\"")
+	assert.Contains(t, code, "testUserColumnCreateTime = \"`create_time`\"")
+
+	code, err = genVars(table, false, true)
+	assert.NoError(t, err)
+	assert.Contains(t, code, "testUserColumnMobile     = \"mobile\"")
+}
+
+func TestGenFindPage(t *testing.T) {
+	dir := pathx.MustTempDir()
+	defer os.RemoveAll(dir)
+
+	sqlFile := filepath.Join(dir, "user.sql")
+	require.NoError(t, os.WriteFile(sqlFile, []byte(source), 0o777))
+	tables, err := parser.Parse(sqlFile, "", false)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(tables))
+
+	g, err := NewDefaultGenerator(dir, &config.Config{
+		NamingFormat: config.DefaultFormat,
+	})
+	require.NoError(t, err)
+
+	code, err := g.genModel(*tables[0], true)
+	assert.NoError(t, err)
+	assert.Contains(t, code, "FindPage(ctx context.Context, cursor string, size int64) ([]*TestUser, string, error)")
+	assert.Contains(t, code, "keyset := builder.Keyset{Columns: []string{\"`id`\"}}")
+	assert.Contains(t, code, "m.QueryPageNoCacheCtx(ctx, &resp, sb, keyset, cursor, size)")
+
+	code, err = g.genModel(*tables[0], false)
+	assert.NoError(t, err)
+	assert.Contains(t, code, "sqlx.QueryPageCtx(ctx, m.conn, &resp, sb, keyset, cursor, size)")
+}
+
+func TestGenConventionColumns(t *testing.T) {
+	dir := pathx.MustTempDir()
+	defer os.RemoveAll(dir)
+
+	sqlFile := filepath.Join(dir, "account.sql")
+	require.NoError(t, os.WriteFile(sqlFile, []byte(`CREATE TABLE account (
+  id bigint NOT NULL AUTO_INCREMENT,
+  name varchar(255) NOT NULL DEFAULT '',
+  version bigint NOT NULL DEFAULT 0,
+  created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at datetime NULL DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY name_unique (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;`), 0o777))
+	tables, err := parser.Parse(sqlFile, "", false)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(tables))
+
+	columns := ConventionColumns{
+		Version:    "version",
+		SoftDelete: "deleted_at",
+		Created:    "created_at",
+		Updated:    "updated_at",
+	}
+	g, err := NewDefaultGenerator(dir, &config.Config{
+		NamingFormat: config.DefaultFormat,
+	}, WithIgnoreColumns([]string{"created_at", "updated_at"}), WithConventionColumns(columns))
+	require.NoError(t, err)
+
+	for _, withCache := range []bool{true, false} {
+		code, err := g.genModel(*tables[0], withCache)
+		assert.NoError(t, err)
+		assert.Contains(t, code, "sqlx.CheckVersion(")
+		assert.Contains(t, code, "set %s, `version` = `version` + 1 where `id` = ? and `version` = ? and `deleted_at` is null")
+		assert.Contains(t, code, "newData.Version++")
+		assert.Contains(t, code, "set `deleted_at` = ? where `id` = ? and `deleted_at` is null")
+		assert.Contains(t, code, "where `id` = ? and `deleted_at` is null limit 1")
+		assert.Contains(t, code, "where `name` = ? and `deleted_at` is null limit 1")
+		assert.Contains(t, code, "now := time.Now()\n\tdata.CreatedAt = now\n\tdata.UpdatedAt = now")
+		assert.Contains(t, code, "`created_at`")
+	}
+
+	g, err = NewDefaultGenerator(dir, &config.Config{
+		NamingFormat: config.DefaultFormat,
+	}, WithPostgreSql(), WithConventionColumns(ConventionColumns{Version: "version"}))
+	require.NoError(t, err)
+
+	code, err := g.genModel(*tables[0], false)
+	assert.NoError(t, err)
+	assert.Contains(t, code, "set %s, version = version + 1 where id = $1 and version = $6")
+	assert.NotContains(t, code, "deleted_at is null")
+}
@@ -31,7 +31,7 @@ func genInsert(table Table, withCache, postgreSql bool) (string, string, error)
 	var count int
 	for _, field := range table.Fields {
 		camel := util.SafeString(field.Name.ToCamel())
-		if table.isIgnoreColumns(field.Name.Source()) {
+		if table.isInsertIgnored(field.Name.Source()) {
 			continue
 		}
 
@@ -66,6 +66,7 @@ func genInsert(table Table, withCache, postgreSql bool) (string, string, error)
 			"expressionValues":      strings.Join(expressionValues, ", "),
 			"keys":                  strings.Join(keys, "\n"),
 			"keyValues":             strings.Join(keyVars, ", "),
+			"timeAssigns":           table.timeAssigns("data", table.createdField, table.updatedField),
 			"data":                  table,
 		})
 	if err != nil {
@@ -18,6 +18,8 @@ const (
 	findOneByFieldTemplateFile            = "find-one-by-field.tpl"
 	findOneByFieldMethodTemplateFile      = "interface-find-one-by-field.tpl"
 	findOneByFieldExtraMethodTemplateFile = "find-one-by-field-extra-method.tpl"
+	findPageTemplateFile                  = "find-page.tpl"
+	findPageMethodTemplateFile            = "interface-find-page.tpl"
 	importsTemplateFile                   = "import.tpl"
 	importsWithNoCacheTemplateFile        = "import-no-cache.tpl"
 	insertTemplateFile                    = "insert.tpl"
@@ -44,6 +46,8 @@ var templates = map[string]string{
 	findOneByFieldTemplateFile:            template.FindOneByField,
 	findOneByFieldMethodTemplateFile:      template.FindOneByFieldMethod,
 	findOneByFieldExtraMethodTemplateFile: template.FindOneByFieldExtraMethod,
+	findPageTemplateFile:                  template.FindPage,
+	findPageMethodTemplateFile:            template.FindPageMethod,
 	importsTemplateFile:                   template.Imports,
 	importsWithNoCacheTemplateFile:        template.ImportsNoCache,
 	insertTemplateFile:                    template.Insert,
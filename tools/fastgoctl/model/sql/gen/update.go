@@ -1,6 +1,7 @@
 package gen
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 
@@ -21,7 +22,7 @@ func genUpdate(table Table, withCache, postgreSql bool) (
 	}
 	for _, field := range table.Fields {
 		camel := util.SafeString(field.Name.ToCamel())
-		if table.isIgnoreColumns(field.Name.Source()) {
+		if table.isUpdateIgnored(field.Name.Source()) {
 			continue
 		}
 
@@ -45,6 +46,22 @@ func genUpdate(table Table, withCache, postgreSql bool) (
 	keyVars := keyVariableSet.KeysStr()
 	sort.Strings(keyVars)
 
+	var versionSet, versionField, updateCond string
+	if table.versionField != nil {
+		column := wrapWithRawString(table.versionField.NameOriginal, postgreSql)
+		versionSet = fmt.Sprintf(", %s = %s + 1", column, column)
+		versionField = pkg + util.SafeString(table.versionField.Name.ToCamel())
+		if postgreSql {
+			// $1 is the primary key, followed by the set values.
+			updateCond = fmt.Sprintf(" and %s = $%d", column, len(expressionValues)+2)
+		} else {
+			updateCond = fmt.Sprintf(" and %s = ?", column)
+		}
+	}
+	if cond := table.notDeletedCond(postgreSql); len(cond) > 0 {
+		updateCond += " and " + cond
+	}
+
 	if postgreSql {
 		expressionValues = append(
 			[]string{pkg + table.PrimaryKey.Name.ToCamel()},
@@ -55,6 +72,9 @@ func genUpdate(table Table, withCache, postgreSql bool) (
 			expressionValues, pkg+table.PrimaryKey.Name.ToCamel(),
 		)
 	}
+	if len(versionField) > 0 {
+		expressionValues = append(expressionValues, versionField)
+	}
 	camelTableName := table.Name.ToCamel()
 	text, err := pathx.LoadTemplate(category, updateTemplateFile, template.Update)
 	if err != nil {
@@ -80,8 +100,13 @@ func genUpdate(table Table, withCache, postgreSql bool) (
 			"expressionValues": strings.Join(
 				expressionValues, ", ",
 			),
-			"postgreSql": postgreSql,
-			"data":       table,
+			"postgreSql":   postgreSql,
+			"withVersion":  len(versionField) > 0,
+			"versionField": versionField,
+			"versionSet":   versionSet,
+			"updateCond":   updateCond,
+			"timeAssigns":  table.timeAssigns(strings.TrimSuffix(pkg, "."), table.updatedField),
+			"data":         table,
 		},
 	)
 	if err != nil {
@@ -20,6 +20,13 @@ func genVars(table Table, withCache, postgreSql bool) (string, error) {
 	}
 
 	camel := table.Name.ToCamel()
+	lowerCamel := stringx.From(camel).Untitle()
+	columns := make([]string, 0, len(table.Fields))
+	for _, field := range table.Fields {
+		columns = append(columns, fmt.Sprintf("%sColumn%s = %q", lowerCamel,
+			util.SafeString(field.Name.ToCamel()), wrapWithRawString(field.NameOriginal, postgreSql)))
+	}
+
 	text, err := pathx.LoadTemplate(category, varTemplateFile, template.Vars)
 	if err != nil {
 		return "", err
@@ -27,27 +34,18 @@ func genVars(table Table, withCache, postgreSql bool) (string, error) {
 
 	output, err := util.With("var").Parse(text).
 		GoFmt(true).Execute(map[string]any{
-		"lowerStartCamelObject": stringx.From(camel).Untitle(),
+		"lowerStartCamelObject": lowerCamel,
 		"upperStartCamelObject": camel,
 		"cacheKeys":             strings.Join(keys, "\n"),
+		"columns":               strings.Join(columns, "\n"),
 		"autoIncrement":         table.PrimaryKey.AutoIncrement,
 		"originalPrimaryKey":    wrapWithRawString(table.PrimaryKey.Name.Source(), postgreSql),
 		"withCache":             withCache,
 		"postgreSql":            postgreSql,
 		"data":                  table,
-		"ignoreColumns": func() string {
-			var set = collection.NewSet()
-			for _, c := range table.ignoreColumns {
-				if postgreSql {
-					set.AddStr(fmt.Sprintf(`"%s"`, c))
-				} else {
-					set.AddStr(fmt.Sprintf("\"`%s`\"", c))
-				}
-			}
-			list := set.KeysStr()
-			sort.Strings(list)
-			return strings.Join(list, ", ")
-		}(),
+		"ignoreColumns":         joinColumns(table.ignoreColumns, postgreSql),
+		"insertIgnoreColumns":   joinColumns(table.filterColumns(table.isInsertIgnored), postgreSql),
+		"updateIgnoreColumns":   joinColumns(table.filterColumns(table.isUpdateIgnored), postgreSql),
 	})
 	if err != nil {
 		return "", err
@@ -55,3 +53,34 @@ func genVars(table Table, withCache, postgreSql bool) (string, error) {
 
 	return output.String(), nil
 }
+
+// filterColumns returns the columns of the ignore list and the table that ignored returns true.
+func (t Table) filterColumns(ignored func(column string) bool) []string {
+	var columns []string
+	for _, column := range t.ignoreColumns {
+		if ignored(column) {
+			columns = append(columns, column)
+		}
+	}
+	for _, field := range t.Fields {
+		if ignored(field.Name.Source()) {
+			columns = append(columns, field.Name.Source())
+		}
+	}
+
+	return columns
+}
+
+func joinColumns(columns []string, postgreSql bool) string {
+	set := collection.NewSet()
+	for _, c := range columns {
+		if postgreSql {
+			set.AddStr(fmt.Sprintf(`"%s"`, c))
+		} else {
+			set.AddStr(fmt.Sprintf("\"`%s`\"", c))
+		}
+	}
+	list := set.KeysStr()
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
@@ -107,6 +107,11 @@ var FindOneByField string
 //go:embed tpl/find-one-by-field-extra-method.tpl
 var FindOneByFieldExtraMethod string
 
+// FindPage defines find rows by the keyset pagination.
+//
+//go:embed tpl/find-page.tpl
+var FindPage string
+
 // FindOneMethod defines find row method.
 //
 //go:embed tpl/interface-find-one.tpl
@@ -117,6 +122,11 @@ var FindOneMethod string
 //go:embed tpl/interface-find-one-by-field.tpl
 var FindOneByFieldMethod string
 
+// FindPageMethod defines find rows by the keyset pagination method.
+//
+//go:embed tpl/interface-find-page.tpl
+var FindPageMethod string
+
 // Field defines a filed template for types
 //
 //go:embed tpl/field.tpl
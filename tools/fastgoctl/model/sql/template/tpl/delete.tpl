@@ -6,9 +6,11 @@ func (m *default{{.upperStartCamelObject}}Model) Delete(ctx context.Context, {{.
 
 {{end}}	{{.keys}}
     _, err {{if .containsIndexCache}}={{else}}:={{end}} m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
-		query := fmt.Sprintf("delete from %s where {{.originalPrimaryKey}} = {{if .postgreSql}}$1{{else}}?{{end}}", m.table)
-		return conn.ExecCtx(ctx, query, {{.lowerStartCamelPrimaryKey}})
-	}, {{.keyValues}}){{else}}query := fmt.Sprintf("delete from %s where {{.originalPrimaryKey}} = {{if .postgreSql}}$1{{else}}?{{end}}", m.table)
-		_,err:=m.conn.ExecCtx(ctx, query, {{.lowerStartCamelPrimaryKey}}){{end}}
+		{{if .softDeleteColumn}}query := fmt.Sprintf("update %s set {{.softDeleteColumn}} = {{if .postgreSql}}$1{{else}}?{{end}} where {{.originalPrimaryKey}} = {{if .postgreSql}}$2{{else}}?{{end}} and {{.notDeleted}}", m.table)
+		return conn.ExecCtx(ctx, query, {{.deletedValue}}, {{.lowerStartCamelPrimaryKey}}){{else}}query := fmt.Sprintf("delete from %s where {{.originalPrimaryKey}} = {{if .postgreSql}}$1{{else}}?{{end}}", m.table)
+		return conn.ExecCtx(ctx, query, {{.lowerStartCamelPrimaryKey}}){{end}}
+	}, {{.keyValues}}){{else}}{{if .softDeleteColumn}}query := fmt.Sprintf("update %s set {{.softDeleteColumn}} = {{if .postgreSql}}$1{{else}}?{{end}} where {{.originalPrimaryKey}} = {{if .postgreSql}}$2{{else}}?{{end}} and {{.notDeleted}}", m.table)
+		_,err:=m.conn.ExecCtx(ctx, query, {{.deletedValue}}, {{.lowerStartCamelPrimaryKey}}){{else}}query := fmt.Sprintf("delete from %s where {{.originalPrimaryKey}} = {{if .postgreSql}}$1{{else}}?{{end}}", m.table)
+		_,err:=m.conn.ExecCtx(ctx, query, {{.lowerStartCamelPrimaryKey}}){{end}}{{end}}
 	return err
 }
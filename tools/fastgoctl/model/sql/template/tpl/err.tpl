@@ -2,4 +2,7 @@ package {{.pkg}}
 
 import "github.com/r27153733/fastgozero/core/stores/sqlx"
 
-var ErrNotFound = sqlx.ErrNotFound
+var (
+	ErrNotFound        = sqlx.ErrNotFound
+	ErrVersionConflict = sqlx.ErrVersionConflict
+)
@@ -3,6 +3,6 @@ func (m *default{{.upperStartCamelObject}}Model) formatPrimary(primary any) stri
 }
 
 func (m *default{{.upperStartCamelObject}}Model) queryPrimary(ctx context.Context, conn sqlx.SqlConn, v, primary any) error {
-	query := fmt.Sprintf("select %s from %s where {{.originalPrimaryField}} = {{if .postgreSql}}$1{{else}}?{{end}} limit 1", {{.lowerStartCamelObject}}Rows, m.table )
+	query := fmt.Sprintf("select %s from %s where {{.originalPrimaryField}} = {{if .postgreSql}}$1{{else}}?{{end}}{{if .notDeleted}} and {{.notDeleted}}{{end}} limit 1", {{.lowerStartCamelObject}}Rows, m.table )
 	return conn.QueryRowCtx(ctx, v, query, primary)
 }
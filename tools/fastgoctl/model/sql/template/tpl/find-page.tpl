@@ -0,0 +1,11 @@
+func (m *default{{.upperStartCamelObject}}Model) FindPage(ctx context.Context, cursor string, size int64) ([]*{{.upperStartCamelObject}}, string, error) {
+	sb := builder.{{if .postgreSql}}Postgres{{else}}MySQL{{end}}.Select({{.lowerStartCamelObject}}Rows).From(m.table){{if .notDeleted}}.Where(builder.Expr("{{.notDeleted}}")){{end}}
+	keyset := builder.Keyset{Columns: []string{"{{.originalPrimaryKey}}"}}
+	var resp []*{{.upperStartCamelObject}}
+	{{if .withCache}}next, err := m.QueryPageNoCacheCtx(ctx, &resp, sb, keyset, cursor, size){{else}}next, err := sqlx.QueryPageCtx(ctx, m.conn, &resp, sb, keyset, cursor, size){{end}}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resp, next, nil
+}
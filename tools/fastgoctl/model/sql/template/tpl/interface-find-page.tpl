@@ -0,0 +1 @@
+FindPage(ctx context.Context, cursor string, size int64) ([]*{{.upperStartCamelObject}}, string, error)
\ No newline at end of file
@@ -1,14 +1,21 @@
 func (m *default{{.upperStartCamelObject}}Model) Update(ctx context.Context, {{if .containsIndexCache}}newData{{else}}data{{end}} *{{.upperStartCamelObject}}) error {
-	{{if .withCache}}{{if .containsIndexCache}}data, err:=m.FindOne(ctx, newData.{{.upperStartCamelPrimaryKey}})
+	{{if .timeAssigns}}{{.timeAssigns}}
+
+	{{end}}{{if .withCache}}{{if .containsIndexCache}}data, err:=m.FindOne(ctx, newData.{{.upperStartCamelPrimaryKey}})
 	if err!=nil{
 		return err
 	}
 
 {{end}}	{{.keys}}
     _, {{if .containsIndexCache}}err{{else}}err:{{end}}= m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
-		query := fmt.Sprintf("update %s set %s where {{.originalPrimaryKey}} = {{if .postgreSql}}$1{{else}}?{{end}}", m.table, {{.lowerStartCamelObject}}RowsWithPlaceHolder)
-		return conn.ExecCtx(ctx, query, {{.expressionValues}})
-	}, {{.keyValues}}){{else}}query := fmt.Sprintf("update %s set %s where {{.originalPrimaryKey}} = {{if .postgreSql}}$1{{else}}?{{end}}", m.table, {{.lowerStartCamelObject}}RowsWithPlaceHolder)
-    _,err:=m.conn.ExecCtx(ctx, query, {{.expressionValues}}){{end}}
-	return err
+		query := fmt.Sprintf("update %s set %s{{.versionSet}} where {{.originalPrimaryKey}} = {{if .postgreSql}}$1{{else}}?{{end}}{{.updateCond}}", m.table, {{.lowerStartCamelObject}}RowsWithPlaceHolder)
+		return {{if .withVersion}}sqlx.CheckVersion(conn.ExecCtx(ctx, query, {{.expressionValues}})){{else}}conn.ExecCtx(ctx, query, {{.expressionValues}}){{end}}
+	}, {{.keyValues}}){{else}}query := fmt.Sprintf("update %s set %s{{.versionSet}} where {{.originalPrimaryKey}} = {{if .postgreSql}}$1{{else}}?{{end}}{{.updateCond}}", m.table, {{.lowerStartCamelObject}}RowsWithPlaceHolder)
+    _,err:={{if .withVersion}}sqlx.CheckVersion(m.conn.ExecCtx(ctx, query, {{.expressionValues}})){{else}}m.conn.ExecCtx(ctx, query, {{.expressionValues}}){{end}}{{end}}
+	{{if .withVersion}}if err != nil {
+		return err
+	}
+
+	{{.versionField}}++
+	return nil{{else}}return err{{end}}
 }
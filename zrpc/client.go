@@ -8,15 +8,22 @@ import (
 	"github.com/r27153733/fastgozero/zrpc/internal"
 	"github.com/r27153733/fastgozero/zrpc/internal/auth"
 	"github.com/r27153733/fastgozero/zrpc/internal/clientinterceptors"
+	"github.com/r27153733/fastgozero/zrpc/internal/tlsx"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 )
 
 var (
+	// WithBalancer is an alias of internal.WithBalancer.
+	WithBalancer = internal.WithBalancer
 	// WithDialOption is an alias of internal.WithDialOption.
 	WithDialOption = internal.WithDialOption
+	// WithMethods is an alias of internal.WithMethods.
+	WithMethods = internal.WithMethods
 	// WithNonBlock sets the dialing to be nonblock.
 	WithNonBlock = internal.WithNonBlock
+	// WithRetry is an alias of internal.WithRetry.
+	WithRetry = internal.WithRetry
 	// WithStreamClientInterceptor is an alias of internal.WithStreamClientInterceptor.
 	WithStreamClientInterceptor = internal.WithStreamClientInterceptor
 	// WithTimeout is an alias of internal.WithTimeout.
@@ -61,6 +68,42 @@ func NewClient(c RpcClientConf, options ...ClientOption) (Client, error) {
 	if c.Timeout > 0 {
 		opts = append(opts, WithTimeout(time.Duration(c.Timeout)*time.Millisecond))
 	}
+	// the retry policies of Methods take precedence over the ones of Retry.
+	c.Retry.Methods = append(clientinterceptors.RetryMethods(c.Methods), c.Retry.Methods...)
+	if c.Retry.Enabled() {
+		opts = append(opts, WithRetry(c.Retry))
+	}
+	if len(c.Balancer) > 0 {
+		opts = append(opts, WithBalancer(c.Balancer, internal.BalancerConfig{
+			Zone:     c.Zone,
+			HashKey:  c.HashKey,
+			Tags:     c.Tags,
+			Fallback: c.TagFallback,
+			OutlierDetection: internal.OutlierConfig{
+				ConsecutiveErrors:  c.OutlierDetection.ConsecutiveErrors,
+				ErrorRate:          c.OutlierDetection.ErrorRate,
+				MinRequests:        c.OutlierDetection.MinRequests,
+				Interval:           c.OutlierDetection.Interval,
+				BaseEjectionTime:   c.OutlierDetection.BaseEjectionTime,
+				MaxEjectionTime:    c.OutlierDetection.MaxEjectionTime,
+				MaxEjectionPercent: c.OutlierDetection.MaxEjectionPercent,
+			},
+		}))
+	}
+	if callOpts := c.callOptions(); len(callOpts) > 0 {
+		opts = append(opts, WithDialOption(grpc.WithDefaultCallOptions(callOpts...)))
+	}
+	if len(c.Methods) > 0 {
+		opts = append(opts, WithMethods(c.Methods))
+	}
+	if c.Tls.Enabled() {
+		creds, err := tlsx.NewClientCredentials(c.Tls)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, WithTransportCredentials(creds))
+	}
 	if c.KeepaliveTime > 0 {
 		opts = append(opts, WithDialOption(grpc.WithKeepaliveParams(keepalive.ClientParameters{
 			Time: c.KeepaliveTime,
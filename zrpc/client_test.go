@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/r27153733/fastgozero/core/discov"
 	"github.com/r27153733/fastgozero/core/logx"
 	"github.com/r27153733/fastgozero/internal/mock"
+	"github.com/r27153733/fastgozero/zrpc/resolver"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -232,6 +234,31 @@ func TestNewClientWithError(t *testing.T) {
 		}),
 	)
 	assert.NotNil(t, err)
+
+	_, err = NewClient(
+		RpcClientConf{
+			Endpoints: []string{"localhost:8080"},
+			Retry: RetryConf{
+				MaxAttempts: 3,
+				Codes:       []string{"not_a_code"},
+			},
+		},
+	)
+	assert.NotNil(t, err)
+
+	_, err = NewClient(
+		RpcClientConf{
+			Endpoints: []string{"localhost:8080"},
+			Methods: []MethodConf{
+				{
+					FullMethod:  "/foo.Foo/*",
+					MaxAttempts: 3,
+					RetryCodes:  []string{"not_a_code"},
+				},
+			},
+		},
+	)
+	assert.NotNil(t, err)
 }
 
 func TestNewClientWithTarget(t *testing.T) {
@@ -245,3 +272,66 @@ func TestNewClientWithTarget(t *testing.T) {
 
 	assert.NotNil(t, err)
 }
+
+func TestNewClientWithSplit(t *testing.T) {
+	var hits [2]atomic.Int32
+	var endpoints [2]string
+	for i := range endpoints {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		server := grpc.NewServer()
+		mock.RegisterDepositServiceServer(server, &countingDepositServer{hits: &hits[i]})
+		go server.Serve(listener)
+		t.Cleanup(server.Stop)
+		endpoints[i] = "direct:///" + listener.Addr().String()
+	}
+
+	const name = "client-split"
+	cli, err := NewClient(RpcClientConf{
+		Split: SplitConf{
+			Name: name,
+			Targets: []WeightedTarget{
+				{Target: endpoints[0], Weight: 100},
+				{Target: endpoints[1]},
+			},
+		},
+		Timeout:  1000,
+		Balancer: "p2c_ewma",
+	})
+	assert.NoError(t, err)
+	defer cli.Conn().Close()
+
+	deposit := mock.NewDepositServiceClient(cli.Conn())
+	for i := 0; i < 10; i++ {
+		_, err = deposit.Deposit(context.Background(), &mock.DepositRequest{})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(10), hits[0].Load())
+	assert.Equal(t, int32(0), hits[1].Load())
+
+	assert.NoError(t, resolver.UpdateSplitTargets(name, []WeightedTarget{
+		{Target: endpoints[0]},
+		{Target: endpoints[1], Weight: 100},
+	}))
+	assert.Eventually(t, func() bool {
+		_, err := deposit.Deposit(context.Background(), &mock.DepositRequest{})
+		return err == nil && hits[1].Load() > 0
+	}, time.Second*5, time.Millisecond*10)
+	before := hits[0].Load()
+	for i := 0; i < 10; i++ {
+		_, err = deposit.Deposit(context.Background(), &mock.DepositRequest{})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, before, hits[0].Load())
+}
+
+type countingDepositServer struct {
+	mock.DepositServer
+	hits *atomic.Int32
+}
+
+func (s *countingDepositServer) Deposit(ctx context.Context, req *mock.DepositRequest) (
+	*mock.DepositResponse, error) {
+	s.hits.Add(1)
+	return s.DepositServer.Deposit(ctx, req)
+}
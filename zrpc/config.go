@@ -1,6 +1,7 @@
 package zrpc
 
 import (
+	"errors"
 	"time"
 
 	"github.com/r27153733/fastgozero/core/discov"
@@ -8,8 +9,11 @@ import (
 	"github.com/r27153733/fastgozero/core/stores/redis"
 	"github.com/r27153733/fastgozero/zrpc/internal"
 	"github.com/r27153733/fastgozero/zrpc/resolver"
+	"google.golang.org/grpc"
 )
 
+var errIntrospectionWithoutDevServer = errors.New("devserver must be enabled to serve the introspection services")
+
 type (
 	// ClientMiddlewaresConf defines whether to use client middlewares.
 	ClientMiddlewaresConf = internal.ClientMiddlewaresConf
@@ -19,6 +23,32 @@ type (
 	StatConf = internal.StatConf
 	// MethodTimeoutConf defines specified timeout for gRPC method.
 	MethodTimeoutConf = internal.MethodTimeoutConf
+	// MetadataConf defines the metadata of the server published in service discovery.
+	MetadataConf = internal.MetadataConf
+	// MethodConf defines the call options of the specified gRPC methods on client side.
+	MethodConf = internal.MethodConf
+	// RetryConf defines the retry policy of client calls.
+	RetryConf = internal.RetryConf
+	// MethodRetryConf defines the retry policy of specified gRPC method.
+	MethodRetryConf = internal.MethodRetryConf
+	// TlsConf defines the TLS config of the rpc servers and clients.
+	TlsConf = internal.TlsConf
+	// AuthPolicyConf defines the authentication of the methods.
+	AuthPolicyConf = internal.AuthPolicyConf
+	// AuthRuleConf defines the authentication of the matched methods.
+	AuthRuleConf = internal.AuthRuleConf
+	// JwtAuthConf defines the jwt authenticator.
+	JwtAuthConf = internal.JwtAuthConf
+	// IntrospectionConf defines the reflection, channelz and admin services of the server.
+	IntrospectionConf = internal.IntrospectionConf
+	// SplitConf defines the traffic splitting across the targets by weight.
+	SplitConf = resolver.SplitConf
+	// WeightedTarget defines a target with the weight of the traffic.
+	WeightedTarget = resolver.WeightedTarget
+	// RegistryConf defines the service registry, the backend is selected by the scheme.
+	RegistryConf = discov.RegistryConf
+	// OutlierDetectionConf defines the ejection of the failing endpoints on client side.
+	OutlierDetectionConf = internal.OutlierDetectionConf
 
 	// A RpcClientConf is a rpc client config.
 	RpcClientConf struct {
@@ -30,7 +60,42 @@ type (
 		NonBlock      bool            `json:",optional"`
 		Timeout       int64           `json:",default=2000"`
 		KeepaliveTime time.Duration   `json:",optional"`
-		Middlewares   ClientMiddlewaresConf
+		// Split splits the traffic across the targets by weight if Targets are set,
+		// which takes precedence over Endpoints, Target, Registry and Etcd.
+		Split SplitConf `json:",optional"`
+		// Registry discovers the servers from the registry selected by the scheme of Target,
+		// like dns:///, file:///path/to/registry.json or k8s:///, used if no Endpoints and Target.
+		Registry RegistryConf `json:",optional"`
+		// Retry is the retry policy, retries are disabled if MaxAttempts less than 2.
+		Retry RetryConf `json:",optional"`
+		// Balancer is the load balancing policy.
+		Balancer string `json:",default=p2c_ewma,options=p2c_ewma|consistent_hash|wrr"`
+		// Zone is the zone of the client, the endpoints in the same zone are preferred if set.
+		Zone string `json:",optional"`
+		// HashKey is the outgoing metadata key to hash on, used by consistent_hash balancer.
+		HashKey string `json:",optional"`
+		// Tags are the route tags to select the endpoints by, like version=v2,lane=canary,
+		// the route tags propagated with the requests override the ones with the same keys.
+		Tags string `json:",optional"`
+		// TagFallback is the rule if no endpoints match the route tags,
+		// all means all endpoints, untagged means the endpoints without version and tags,
+		// none means failing the requests.
+		TagFallback string `json:",default=all,options=all|untagged|none"`
+		// OutlierDetection ejects the failing endpoints temporarily, works with all balancers.
+		OutlierDetection OutlierDetectionConf `json:",optional"`
+		// Tls enables TLS if CertFile, CaFile or ServerName is set.
+		Tls TlsConf `json:",optional"`
+		// Compressor compresses the requests, the servers respond with the same one.
+		Compressor string `json:",optional,options=gzip|zstd"`
+		// MaxRecvMsgSize and MaxSendMsgSize are the max message sizes in bytes,
+		// 0 means the grpc defaults, 4MB to receive and unlimited to send.
+		MaxRecvMsgSize int `json:",optional"`
+		MaxSendMsgSize int `json:",optional"`
+		// Methods are the call options of the specified methods, like timeout, retry,
+		// breaker, wait-for-ready and compression, the exact matched one is preferred,
+		// then the first matched wildcard one, like /pkg.Service/*.
+		Methods     []MethodConf `json:",optional"`
+		Middlewares ClientMiddlewaresConf
 	}
 
 	// A RpcServerConf is a rpc server config.
@@ -49,6 +114,26 @@ type (
 		Middlewares ServerMiddlewaresConf
 		// setting specified timeout for gRPC method
 		MethodTimeouts []MethodTimeoutConf `json:",optional"`
+		// Metadata is published along with the address in etcd, which requires the clients
+		// to be upgraded to recognize it, keep it empty to publish the address only.
+		Metadata MetadataConf `json:",optional"`
+		// Registry registers the server to the registry selected by the scheme of Target,
+		// like etcd://127.0.0.1:2379 or file:///path/to/registry.json, used if no Etcd.
+		Registry RegistryConf `json:",optional"`
+		// Tls enables TLS if CertFile and KeyFile are set, and the clients are authenticated
+		// by the verified certificates if Auth is true without Redis settings.
+		Tls TlsConf `json:",optional"`
+		// AuthPolicy authenticates the requests with the authenticators chosen by methods,
+		// which works along with Auth, the built-in authenticators are jwt, apikey, mtls
+		// and apptoken if Redis is set.
+		AuthPolicy AuthPolicyConf `json:",optional"`
+		// Introspection registers the reflection, channelz and admin services,
+		// reflection is enabled in dev and test mode by default.
+		Introspection IntrospectionConf `json:",optional"`
+		// MaxRecvMsgSize and MaxSendMsgSize are the max message sizes in bytes,
+		// 0 means the grpc defaults, 4MB to receive and unlimited to send.
+		MaxRecvMsgSize int `json:",optional"`
+		MaxSendMsgSize int `json:",optional"`
 	}
 )
 
@@ -78,21 +163,62 @@ func (sc RpcServerConf) HasEtcd() bool {
 	return len(sc.Etcd.Hosts) > 0 && len(sc.Etcd.Key) > 0
 }
 
+// HasRegistry checks if there is registry settings in config.
+func (sc RpcServerConf) HasRegistry() bool {
+	return len(sc.Registry.Target) > 0
+}
+
 // Validate validates the config.
 func (sc RpcServerConf) Validate() error {
-	if !sc.Auth {
+	if err := sc.Tls.Validate(); err != nil {
+		return err
+	}
+	if sc.HasRegistry() {
+		if err := sc.Registry.Validate(); err != nil {
+			return err
+		}
+	}
+	if sc.Introspection.OnDevServer && !sc.DevServer.Enabled {
+		return errIntrospectionWithoutDevServer
+	}
+
+	if !sc.Auth || sc.authByPeer() {
 		return nil
 	}
 
 	return sc.Redis.Validate()
 }
 
+func (sc RpcServerConf) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if sc.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(sc.MaxRecvMsgSize))
+	}
+	if sc.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(sc.MaxSendMsgSize))
+	}
+
+	return opts
+}
+
+func (sc RpcServerConf) authByPeer() bool {
+	return len(sc.Redis.Host) == 0 && sc.Tls.VerifyClients()
+}
+
 // BuildTarget builds the rpc target from the given config.
 func (cc RpcClientConf) BuildTarget() (string, error) {
-	if len(cc.Endpoints) > 0 {
+	if len(cc.Split.Targets) > 0 {
+		return resolver.BuildSplitTarget(cc.Split)
+	} else if len(cc.Endpoints) > 0 {
 		return resolver.BuildDirectTarget(cc.Endpoints), nil
 	} else if len(cc.Target) > 0 {
 		return cc.Target, nil
+	} else if len(cc.Registry.Target) > 0 {
+		if err := cc.Registry.Validate(); err != nil {
+			return "", err
+		}
+
+		return resolver.BuildRegistryTarget(cc.Registry), nil
 	}
 
 	if err := cc.Etcd.Validate(); err != nil {
@@ -112,6 +238,21 @@ func (cc RpcClientConf) BuildTarget() (string, error) {
 	return resolver.BuildDiscovTarget(cc.Etcd.Hosts, cc.Etcd.Key), nil
 }
 
+func (cc RpcClientConf) callOptions() []grpc.CallOption {
+	var opts []grpc.CallOption
+	if len(cc.Compressor) > 0 {
+		opts = append(opts, grpc.UseCompressor(cc.Compressor))
+	}
+	if cc.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxCallRecvMsgSize(cc.MaxRecvMsgSize))
+	}
+	if cc.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxCallSendMsgSize(cc.MaxSendMsgSize))
+	}
+
+	return opts
+}
+
 // HasCredential checks if there is a credential in config.
 func (cc RpcClientConf) HasCredential() bool {
 	return len(cc.App) > 0 && len(cc.Token) > 0
@@ -39,6 +39,40 @@ func TestRpcClientConf(t *testing.T) {
 		_, err := conf.BuildTarget()
 		assert.Error(t, err)
 	})
+
+	t.Run("registry", func(t *testing.T) {
+		var conf RpcClientConf
+		conf.Registry = RegistryConf{
+			Target: "dns:///",
+		}
+		_, err := conf.BuildTarget()
+		assert.Error(t, err)
+
+		conf.Registry.Service = "svc.example.com:8080"
+		target, err := conf.BuildTarget()
+		assert.NoError(t, err)
+		assert.Equal(t, "registry:///svc.example.com:8080?target=dns%3A%2F%2F%2F", target)
+	})
+
+	t.Run("split", func(t *testing.T) {
+		conf := NewDirectClientConf([]string{"localhost:1234"}, "foo", "bar")
+		conf.Split = SplitConf{
+			Name: "config-split",
+			Targets: []WeightedTarget{
+				{Target: "direct:///localhost:1234", Weight: 90},
+				{Target: "direct:///localhost:5678", Weight: 10},
+			},
+		}
+		target, err := conf.BuildTarget()
+		assert.NoError(t, err)
+		assert.Equal(t, "split:///config-split", target)
+
+		conf.Split.Targets = []WeightedTarget{
+			{Target: "localhost", Weight: 1},
+		}
+		_, err = conf.BuildTarget()
+		assert.Error(t, err)
+	})
 }
 
 func TestRpcServerConf(t *testing.T) {
@@ -65,3 +99,35 @@ func TestRpcServerConf(t *testing.T) {
 	conf.Redis.Host = "localhost:5678"
 	assert.Nil(t, conf.Validate())
 }
+
+func TestRpcServerConf_Tls(t *testing.T) {
+	conf := RpcServerConf{
+		Auth: true,
+		Tls: TlsConf{
+			CertFile: "cert.pem",
+		},
+	}
+	assert.Error(t, conf.Validate())
+
+	conf.Tls.KeyFile = "key.pem"
+	assert.Error(t, conf.Validate())
+
+	// authenticated by the verified client certificates
+	conf.Tls.CaFile = "ca.pem"
+	assert.NoError(t, conf.Validate())
+
+	conf.Tls.ClientAuth = "request"
+	assert.Error(t, conf.Validate())
+}
+
+func TestRpcServerConf_Introspection(t *testing.T) {
+	conf := RpcServerConf{
+		Introspection: IntrospectionConf{
+			OnDevServer: true,
+		},
+	}
+	assert.Equal(t, errIntrospectionWithoutDevServer, conf.Validate())
+
+	conf.DevServer.Enabled = true
+	assert.NoError(t, conf.Validate())
+}
@@ -0,0 +1,38 @@
+package zrpc
+
+import (
+	"github.com/r27153733/fastgozero/core/discov"
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/zrpc/internal"
+	"github.com/r27153733/fastgozero/zrpc/internal/inproc"
+)
+
+// BuildInProcTarget returns the in-process target with the given name, like inproc://name,
+// which can be used as RpcClientConf.Target to connect to the in-process servers.
+func BuildInProcTarget(name string) string {
+	return inproc.Target(name)
+}
+
+// MustNewInProcServer returns a RpcServer serving in process, exits on any error.
+func MustNewInProcServer(name string, c RpcServerConf, register internal.RegisterFn) *RpcServer {
+	server, err := NewInProcServer(name, c, register)
+	logx.Must(err)
+	return server
+}
+
+// NewInProcServer returns a RpcServer serving on an in-memory listener with the given name
+// instead of ListenOn, the interceptors are the same as the ones of NewServer,
+// and the server is not published to etcd.
+func NewInProcServer(name string, c RpcServerConf, register internal.RegisterFn) (*RpcServer, error) {
+	c.ListenOn = inproc.Target(name)
+	c.Etcd = discov.EtcdConf{}
+	return NewServer(c, register)
+}
+
+// NewInProcClient returns a Client connecting to the in-process server with the given name,
+// the interceptors are the same as the ones of NewClient.
+func NewInProcClient(name string, c RpcClientConf, options ...ClientOption) (Client, error) {
+	c.Endpoints = nil
+	c.Target = inproc.Target(name)
+	return NewClient(c, options...)
+}
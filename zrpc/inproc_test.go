@@ -0,0 +1,140 @@
+package zrpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/conf"
+	"github.com/r27153733/fastgozero/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInProcServerAndClient(t *testing.T) {
+	const name = "deposit"
+
+	var sc RpcServerConf
+	assert.NoError(t, conf.FillDefault(&sc))
+	sc.Name = name
+	sc.ListenOn = "localhost:0"
+	sc.Etcd.Hosts = []string{"localhost:2379"}
+	sc.Etcd.Key = name
+	svr, err := NewInProcServer(name, sc, func(server *grpc.Server) {
+		mock.RegisterDepositServiceServer(server, &mock.DepositServer{})
+	})
+	assert.NoError(t, err)
+	go svr.Start()
+
+	var cc RpcClientConf
+	assert.NoError(t, conf.FillDefault(&cc))
+	cc.Endpoints = []string{"localhost:0"}
+	cli, err := NewInProcClient(name, cc)
+	assert.NoError(t, err)
+
+	client := mock.NewDepositServiceClient(cli.Conn())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := client.Deposit(ctx, &mock.DepositRequest{Amount: 1})
+	assert.NoError(t, err)
+	assert.True(t, resp.GetOk())
+
+	// the errors go through the interceptors as the network ones.
+	_, err = client.Deposit(ctx, &mock.DepositRequest{Amount: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	cli, err = NewClientWithTarget(BuildInProcTarget(name))
+	assert.NoError(t, err)
+	_, err = mock.NewDepositServiceClient(cli.Conn()).Deposit(ctx, &mock.DepositRequest{Amount: 1})
+	assert.NoError(t, err)
+}
+
+func TestInProcServer_messages(t *testing.T) {
+	tests := []struct {
+		name   string
+		server func(c *RpcServerConf)
+		client func(c *RpcClientConf)
+		code   codes.Code
+	}{
+		{
+			name: "gzip",
+			client: func(c *RpcClientConf) {
+				c.Compressor = "gzip"
+			},
+		},
+		{
+			name: "zstd",
+			client: func(c *RpcClientConf) {
+				c.Methods = []MethodConf{
+					{
+						FullMethod: "/mock.DepositService/*",
+						Compressor: "zstd",
+					},
+				}
+			},
+		},
+		{
+			name: "server receive oversize",
+			server: func(c *RpcServerConf) {
+				c.MaxRecvMsgSize = 1
+			},
+			code: codes.ResourceExhausted,
+		},
+		{
+			name: "server send oversize",
+			server: func(c *RpcServerConf) {
+				c.MaxSendMsgSize = 1
+			},
+			code: codes.ResourceExhausted,
+		},
+		{
+			name: "client receive oversize",
+			client: func(c *RpcClientConf) {
+				c.MaxRecvMsgSize = 1
+			},
+			code: codes.ResourceExhausted,
+		},
+		{
+			name: "client send oversize",
+			client: func(c *RpcClientConf) {
+				c.MaxSendMsgSize = 1
+			},
+			code: codes.ResourceExhausted,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			name := "messages-" + strings.ReplaceAll(test.name, " ", "-")
+			var sc RpcServerConf
+			assert.NoError(t, conf.FillDefault(&sc))
+			sc.Name = name
+			if test.server != nil {
+				test.server(&sc)
+			}
+			svr, err := NewInProcServer(name, sc, func(server *grpc.Server) {
+				mock.RegisterDepositServiceServer(server, &mock.DepositServer{})
+			})
+			assert.NoError(t, err)
+			go svr.Start()
+
+			var cc RpcClientConf
+			assert.NoError(t, conf.FillDefault(&cc))
+			if test.client != nil {
+				test.client(&cc)
+			}
+			cli, err := NewInProcClient(name, cc)
+			assert.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			_, err = mock.NewDepositServiceClient(cli.Conn()).Deposit(ctx,
+				&mock.DepositRequest{Amount: 1})
+			assert.Equal(t, test.code, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// An ApiKeyAuthenticator authenticates the rpc requests by the static api keys
+// in x-api-key metadata.
+type ApiKeyAuthenticator struct {
+	keys [][]byte
+}
+
+// NewApiKeyAuthenticator returns an ApiKeyAuthenticator with the given keys.
+func NewApiKeyAuthenticator(keys []string) *ApiKeyAuthenticator {
+	a := &ApiKeyAuthenticator{
+		keys: make([][]byte, 0, len(keys)),
+	}
+	for _, key := range keys {
+		a.keys = append(a.keys, []byte(key))
+	}
+
+	return a
+}
+
+// Authenticate authenticates the given ctx.
+func (a *ApiKeyAuthenticator) Authenticate(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, missingApiKey)
+	}
+
+	vals := md.Get(apiKeyKey)
+	if len(vals) == 0 || len(vals[0]) == 0 {
+		return nil, status.Error(codes.Unauthenticated, missingApiKey)
+	}
+
+	// compare all the keys in constant time to avoid leaking the keys by timing
+	key := []byte(vals[0])
+	var matched int
+	for _, k := range a.keys {
+		matched |= subtle.ConstantTimeCompare(k, key)
+	}
+	if matched == 0 {
+		return nil, status.Error(codes.Unauthenticated, accessDenied)
+	}
+
+	return ctx, nil
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestApiKeyAuthenticator(t *testing.T) {
+	authenticator := NewApiKeyAuthenticator([]string{"foo", "bar"})
+
+	_, err := authenticator.Authenticate(context.Background())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(apiKeyKey, ""))
+	_, err = authenticator.Authenticate(ctx)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(apiKeyKey, "baz"))
+	_, err = authenticator.Authenticate(ctx)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(apiKeyKey, "bar"))
+	authed, err := authenticator.Authenticate(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, ctx, authed)
+}
@@ -13,22 +13,23 @@ import (
 
 const defaultExpiration = 5 * time.Minute
 
-// An Authenticator is used to authenticate the rpc requests.
-type Authenticator struct {
+// A RedisAuthenticator authenticates the rpc requests by the app and token in metadata,
+// the tokens of the apps are stored in a redis hash.
+type RedisAuthenticator struct {
 	store  *redis.Redis
 	key    string
 	cache  *collection.Cache
 	strict bool
 }
 
-// NewAuthenticator returns an Authenticator.
-func NewAuthenticator(store *redis.Redis, key string, strict bool) (*Authenticator, error) {
+// NewRedisAuthenticator returns a RedisAuthenticator.
+func NewRedisAuthenticator(store *redis.Redis, key string, strict bool) (*RedisAuthenticator, error) {
 	cache, err := collection.NewCache(defaultExpiration)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Authenticator{
+	return &RedisAuthenticator{
 		store:  store,
 		key:    key,
 		cache:  cache,
@@ -37,26 +38,30 @@ func NewAuthenticator(store *redis.Redis, key string, strict bool) (*Authenticat
 }
 
 // Authenticate authenticates the given ctx.
-func (a *Authenticator) Authenticate(ctx context.Context) error {
+func (a *RedisAuthenticator) Authenticate(ctx context.Context) (context.Context, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return status.Error(codes.Unauthenticated, missingMetadata)
+		return nil, status.Error(codes.Unauthenticated, missingMetadata)
 	}
 
 	apps, tokens := md[appKey], md[tokenKey]
 	if len(apps) == 0 || len(tokens) == 0 {
-		return status.Error(codes.Unauthenticated, missingMetadata)
+		return nil, status.Error(codes.Unauthenticated, missingMetadata)
 	}
 
 	app, token := apps[0], tokens[0]
 	if len(app) == 0 || len(token) == 0 {
-		return status.Error(codes.Unauthenticated, missingMetadata)
+		return nil, status.Error(codes.Unauthenticated, missingMetadata)
 	}
 
-	return a.validate(app, token)
+	if err := a.validate(app, token); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
 }
 
-func (a *Authenticator) validate(app, token string) error {
+func (a *RedisAuthenticator) validate(app, token string) error {
 	expect, err := a.cache.Take(app, func() (any, error) {
 		return a.store.Hget(a.key, app)
 	})
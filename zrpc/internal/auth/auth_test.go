@@ -52,24 +52,27 @@ func TestAuthenticator(t *testing.T) {
 				defer store.Hdel("apps", test.app)
 			}
 
-			authenticator, err := NewAuthenticator(store, "apps", test.strict)
+			authenticator, err := NewRedisAuthenticator(store, "apps", test.strict)
 			assert.Nil(t, err)
-			assert.NotNil(t, authenticator.Authenticate(context.Background()))
+			_, err = authenticator.Authenticate(context.Background())
+			assert.NotNil(t, err)
 			md := metadata.New(map[string]string{})
 			ctx := metadata.NewIncomingContext(context.Background(), md)
-			assert.NotNil(t, authenticator.Authenticate(ctx))
+			_, err = authenticator.Authenticate(ctx)
+			assert.NotNil(t, err)
 			md = metadata.New(map[string]string{
 				"app":   "",
 				"token": "",
 			})
 			ctx = metadata.NewIncomingContext(context.Background(), md)
-			assert.NotNil(t, authenticator.Authenticate(ctx))
+			_, err = authenticator.Authenticate(ctx)
+			assert.NotNil(t, err)
 			md = metadata.New(map[string]string{
 				"app":   "foo",
 				"token": "bar",
 			})
 			ctx = metadata.NewIncomingContext(context.Background(), md)
-			err = authenticator.Authenticate(ctx)
+			_, err = authenticator.Authenticate(ctx)
 			if test.hasError {
 				assert.NotNil(t, err)
 			} else {
@@ -0,0 +1,20 @@
+package auth
+
+import "context"
+
+const (
+	// AppTokenName is the name of the authenticator with app/token stored in redis.
+	AppTokenName = "apptoken"
+	// JwtName is the name of the authenticator with jwt bearer tokens.
+	JwtName = "jwt"
+	// MtlsName is the name of the authenticator with verified peer certificates.
+	MtlsName = "mtls"
+	// ApiKeyName is the name of the authenticator with static api keys.
+	ApiKeyName = "apikey"
+)
+
+// An Authenticator authenticates the rpc requests, the returned context is passed
+// to the handlers, which can carry the authenticated identity, like the jwt claims.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (context.Context, error)
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/r27153733/fastgozero/rest/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	jwtAudience  = "aud"
+	jwtExpire    = "exp"
+	jwtId        = "jti"
+	jwtIssueAt   = "iat"
+	jwtIssuer    = "iss"
+	jwtNotBefore = "nbf"
+	jwtSubject   = "sub"
+)
+
+// A JwtAuthenticator authenticates the rpc requests by the jwt bearer tokens in
+// authorization metadata, the same as the rest jwt middleware. The non-standard
+// claims are put into the context, like the rest jwt middleware does.
+type JwtAuthenticator struct {
+	secret     string
+	prevSecret string
+	parser     *token.TokenParser
+}
+
+// NewJwtAuthenticator returns a JwtAuthenticator, prevSecret is used on secret rotation.
+func NewJwtAuthenticator(secret, prevSecret string) *JwtAuthenticator {
+	return &JwtAuthenticator{
+		secret:     secret,
+		prevSecret: prevSecret,
+		parser:     token.NewTokenParser(),
+	}
+}
+
+// Authenticate authenticates the given ctx.
+func (a *JwtAuthenticator) Authenticate(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, missingToken)
+	}
+
+	vals := md.Get(authorizationKey)
+	if len(vals) == 0 || len(vals[0]) == 0 {
+		return nil, status.Error(codes.Unauthenticated, missingToken)
+	}
+
+	tok, err := a.parser.ParseTokenString(vals[0], a.secret, a.prevSecret)
+	if err != nil || !tok.Valid {
+		return nil, status.Error(codes.Unauthenticated, accessDenied)
+	}
+
+	claims, ok := tok.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, accessDenied)
+	}
+
+	for k, v := range claims {
+		switch k {
+		case jwtAudience, jwtExpire, jwtId, jwtIssueAt, jwtIssuer, jwtNotBefore, jwtSubject:
+			// ignore the standard claims
+		default:
+			//nolint:staticcheck
+			ctx = context.WithValue(ctx, k, v)
+		}
+	}
+
+	return ctx, nil
+}
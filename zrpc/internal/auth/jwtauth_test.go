@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestJwtAuthenticator(t *testing.T) {
+	const (
+		secret     = "14F17379-EB8F-411B-8F12-6929002DCA76"
+		prevSecret = "B63F477D-BBA3-4E52-96D3-C0034C27694A"
+	)
+	authenticator := NewJwtAuthenticator(secret, prevSecret)
+
+	_, err := authenticator.Authenticate(context.Background())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationKey, ""))
+	_, err = authenticator.Authenticate(ctx)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(authorizationKey, "Bearer "+buildToken(t, "bad-secret", time.Hour)))
+	_, err = authenticator.Authenticate(ctx)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(authorizationKey, "Bearer "+buildToken(t, secret, -time.Hour)))
+	_, err = authenticator.Authenticate(ctx)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	for _, key := range []string{secret, prevSecret} {
+		ctx = metadata.NewIncomingContext(context.Background(),
+			metadata.Pairs(authorizationKey, "Bearer "+buildToken(t, key, time.Hour)))
+		ctx, err = authenticator.Authenticate(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "1234", ctx.Value("userId"))
+		assert.Nil(t, ctx.Value(jwtExpire))
+	}
+}
+
+func buildToken(t *testing.T, secret string, expire time.Duration) string {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		jwtExpire:  now.Add(expire).Unix(),
+		jwtIssueAt: now.Unix(),
+		"userId":   "1234",
+	})
+	tokenStr, err := token.SignedString([]byte(secret))
+	assert.NoError(t, err)
+
+	return tokenStr
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/r27153733/fastgozero/zrpc/internal/tlsx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// A PeerAuthenticator authenticates the rpc requests by the verified TLS certificates
+// of the peers, the allowed SANs are checked on handshakes.
+type PeerAuthenticator struct{}
+
+// NewPeerAuthenticator returns a PeerAuthenticator.
+func NewPeerAuthenticator() PeerAuthenticator {
+	return PeerAuthenticator{}
+}
+
+// Authenticate authenticates the given ctx.
+func (a PeerAuthenticator) Authenticate(ctx context.Context) (context.Context, error) {
+	if _, ok := tlsx.PeerIdentityFromContext(ctx); !ok {
+		return nil, status.Error(codes.Unauthenticated, missingPeerCert)
+	}
+
+	return ctx, nil
+}
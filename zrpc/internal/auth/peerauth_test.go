@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestPeerAuthenticator(t *testing.T) {
+	authenticator := NewPeerAuthenticator()
+	_, err := authenticator.Authenticate(context.Background())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{new(x509.Certificate)}},
+			},
+		},
+	})
+	_, err = authenticator.Authenticate(ctx)
+	assert.NoError(t, err)
+}
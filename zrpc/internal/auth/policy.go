@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/r27153733/fastgozero/core/logx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const anyMethodSuffix = "*"
+
+type (
+	// A Rule defines how to authenticate the requests to the matched methods.
+	Rule struct {
+		// Method is the full method name, like /pkg.Service/Method, the trailing *
+		// matches any suffix, like /pkg.Service/* or * for all methods.
+		Method string
+		// Authenticators are the names of the authenticators, like jwt, mtls, apikey,
+		// apptoken or the custom ones, any of them passes the requests,
+		// empty means no authentication required.
+		Authenticators []string `json:",optional"`
+		// Deny denies all the requests to the matched methods.
+		Deny bool `json:",optional"`
+	}
+
+	// A Policy authenticates the rpc requests with the authenticators chosen by methods.
+	Policy struct {
+		defaults       []string
+		rules          []Rule
+		authenticators map[string]Authenticator
+		lock           sync.RWMutex
+	}
+)
+
+// NewPolicy returns a Policy, the rules are checked in order, and the first matched
+// one is applied, defaults are used if no rules matched.
+func NewPolicy(defaults []string, rules []Rule) *Policy {
+	return &Policy{
+		defaults:       defaults,
+		rules:          rules,
+		authenticators: make(map[string]Authenticator),
+	}
+}
+
+// AddAuthenticator adds the authenticator with the given name, which can be used in rules.
+func (p *Policy) AddAuthenticator(name string, authenticator Authenticator) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.authenticators[name] = authenticator
+}
+
+// Authenticate authenticates the requests to the given method.
+func (p *Policy) Authenticate(ctx context.Context, method string) (context.Context, error) {
+	names := p.defaults
+	if rule, ok := p.match(method); ok {
+		if rule.Deny {
+			return nil, status.Error(codes.PermissionDenied, methodDenied)
+		}
+
+		names = rule.Authenticators
+	}
+
+	if len(names) == 0 {
+		return ctx, nil
+	}
+
+	var err error
+	for _, name := range names {
+		authenticator, ok := p.getAuthenticator(name)
+		if !ok {
+			logx.WithContext(ctx).Errorf("unknown authenticator %q for method %s", name, method)
+			err = status.Error(codes.Internal, unknownAuth)
+			continue
+		}
+
+		var authed context.Context
+		authed, err = authenticator.Authenticate(ctx)
+		if err == nil {
+			return authed, nil
+		}
+	}
+
+	return nil, err
+}
+
+func (p *Policy) getAuthenticator(name string) (Authenticator, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	authenticator, ok := p.authenticators[name]
+	return authenticator, ok
+}
+
+func (p *Policy) match(method string) (Rule, bool) {
+	for _, rule := range p.rules {
+		if matchMethod(rule.Method, method) {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
+
+func matchMethod(pattern, method string) bool {
+	if strings.HasSuffix(pattern, anyMethodSuffix) {
+		return strings.HasPrefix(method, strings.TrimSuffix(pattern, anyMethodSuffix))
+	}
+
+	return pattern == method
+}
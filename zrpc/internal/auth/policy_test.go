@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestPolicy(t *testing.T) {
+	policy := NewPolicy([]string{ApiKeyName}, []Rule{
+		{
+			Method: "/foo.Public/Ping",
+		},
+		{
+			Method: "/foo.Public/Internal",
+			Deny:   true,
+		},
+		{
+			Method:         "/foo.Admin/*",
+			Authenticators: []string{MtlsName},
+		},
+		{
+			Method:         "/foo.Other/*",
+			Authenticators: []string{"unknown", ApiKeyName},
+		},
+	})
+	policy.AddAuthenticator(ApiKeyName, NewApiKeyAuthenticator([]string{"key"}))
+	policy.AddAuthenticator(MtlsName, NewPeerAuthenticator())
+
+	withKey := metadata.NewIncomingContext(context.Background(), metadata.Pairs(apiKeyKey, "key"))
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		code   codes.Code
+	}{
+		{
+			name:   "anonymous",
+			ctx:    context.Background(),
+			method: "/foo.Public/Ping",
+			code:   codes.OK,
+		},
+		{
+			name:   "denied",
+			ctx:    withKey,
+			method: "/foo.Public/Internal",
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "default",
+			ctx:    withKey,
+			method: "/foo.Public/Hello",
+			code:   codes.OK,
+		},
+		{
+			name:   "default without key",
+			ctx:    context.Background(),
+			method: "/foo.Public/Hello",
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "stronger auth",
+			ctx:    withKey,
+			method: "/foo.Admin/Delete",
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "any of authenticators",
+			ctx:    withKey,
+			method: "/foo.Other/Hello",
+			code:   codes.OK,
+		},
+		{
+			name:   "unknown authenticator",
+			ctx:    context.Background(),
+			method: "/foo.Other/Hello",
+			code:   codes.Unauthenticated,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := policy.Authenticate(test.ctx, test.method)
+			assert.Equal(t, test.code, status.Code(err))
+		})
+	}
+
+	policy = NewPolicy([]string{"unknown"}, nil)
+	_, err := policy.Authenticate(context.Background(), "/foo.Public/Hello")
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestMatchMethod(t *testing.T) {
+	assert.True(t, matchMethod("*", "/foo.Bar/Baz"))
+	assert.True(t, matchMethod("/foo.Bar/*", "/foo.Bar/Baz"))
+	assert.True(t, matchMethod("/foo.Bar/Baz", "/foo.Bar/Baz"))
+	assert.False(t, matchMethod("/foo.Bar/Baz", "/foo.Bar/Qux"))
+	assert.False(t, matchMethod("/foo.Bar/*", "/foo.Baz/Qux"))
+}
@@ -1,9 +1,16 @@
 package auth
 
 const (
-	appKey   = "app"
-	tokenKey = "token"
+	appKey           = "app"
+	tokenKey         = "token"
+	apiKeyKey        = "x-api-key"
+	authorizationKey = "authorization"
 
 	accessDenied    = "access denied"
 	missingMetadata = "app/token required"
+	missingPeerCert = "verified peer certificate required"
+	missingApiKey   = "api key required"
+	missingToken    = "bearer token required"
+	unknownAuth     = "unknown authenticator"
+	methodDenied    = "method denied"
 )
@@ -0,0 +1,67 @@
+package attempt
+
+import (
+	"context"
+	"sync"
+)
+
+type trackerKey struct{}
+
+// A Tracker tracks the addresses picked by the balancers during the attempts of a call,
+// so that the retried or hedged attempts can be sent to different sub-conns.
+type Tracker struct {
+	lock  sync.Mutex
+	addrs []string
+}
+
+// NewContext returns a new context that carries a Tracker, and the Tracker.
+func NewContext(ctx context.Context) (context.Context, *Tracker) {
+	t := new(Tracker)
+	return context.WithValue(ctx, trackerKey{}, t), t
+}
+
+// FromContext returns the Tracker in ctx, nil if not exists.
+func FromContext(ctx context.Context) *Tracker {
+	if ctx == nil {
+		return nil
+	}
+
+	t, _ := ctx.Value(trackerKey{}).(*Tracker)
+	return t
+}
+
+// Len returns the number of picked addresses.
+func (t *Tracker) Len() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return len(t.addrs)
+}
+
+// Picked reports whether addr has been picked by the previous attempts.
+func (t *Tracker) Picked(addr string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, a := range t.addrs {
+		if a == addr {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Record records addr as picked.
+func (t *Tracker) Record(addr string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, a := range t.addrs {
+		if a == addr {
+			return
+		}
+	}
+
+	t.addrs = append(t.addrs, addr)
+}
@@ -0,0 +1,25 @@
+package attempt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	assert.Nil(t, FromContext(context.Background()))
+
+	ctx, tracker := NewContext(context.Background())
+	assert.Equal(t, tracker, FromContext(ctx))
+	assert.Equal(t, 0, tracker.Len())
+	assert.False(t, tracker.Picked("foo"))
+
+	tracker.Record("foo")
+	tracker.Record("foo")
+	tracker.Record("bar")
+	assert.Equal(t, 2, tracker.Len())
+	assert.True(t, tracker.Picked("foo"))
+	assert.True(t, tracker.Picked("bar"))
+	assert.False(t, tracker.Picked("baz"))
+}
@@ -0,0 +1,133 @@
+package consistenthash
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/hash"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/attempt"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/lbconfig"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/metadata"
+)
+
+// Name is the name of consistent hash balancer.
+const Name = "consistent_hash"
+
+func init() {
+	balancer.Register(newBuilder())
+}
+
+type hashPickerBuilder struct {
+	key string
+}
+
+func (b *hashPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	ring := hash.NewConsistentHash()
+	conns := make(map[string]balancer.SubConn, len(info.ReadySCs))
+	addrs := make([]string, 0, len(info.ReadySCs))
+	for conn, connInfo := range info.ReadySCs {
+		addr := connInfo.Address.Addr
+		ring.Add(addr)
+		conns[addr] = conn
+		addrs = append(addrs, addr)
+	}
+
+	return &hashPicker{
+		key:   b.key,
+		ring:  ring,
+		conns: conns,
+		addrs: addrs,
+		r:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func newBuilder() balancer.Builder {
+	return lbconfig.NewBuilder(Name, func(c lbconfig.Config) base.PickerBuilder {
+		return &hashPickerBuilder{
+			key: c.HashKey,
+		}
+	})
+}
+
+// hashPicker picks the sub-conn by the hash of the outgoing metadata value with key,
+// the requests without the metadata value are picked randomly.
+type hashPicker struct {
+	key   string
+	ring  *hash.ConsistentHash
+	conns map[string]balancer.SubConn
+	addrs []string
+	r     *rand.Rand
+	lock  sync.Mutex
+}
+
+func (p *hashPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	tracker := attempt.FromContext(info.Ctx)
+
+	if val, ok := p.hashValue(info); ok {
+		if node, ok := p.ring.Get(val); ok {
+			addr := node.(string)
+			// the retried attempts go to other sub-conns
+			if tracker == nil || !tracker.Picked(addr) {
+				return p.buildResult(addr, tracker), nil
+			}
+		}
+	}
+
+	return p.buildResult(p.random(tracker), tracker), nil
+}
+
+func (p *hashPicker) buildResult(addr string, tracker *attempt.Tracker) balancer.PickResult {
+	if tracker != nil {
+		tracker.Record(addr)
+	}
+
+	return balancer.PickResult{
+		SubConn: p.conns[addr],
+	}
+}
+
+func (p *hashPicker) hashValue(info balancer.PickInfo) (string, bool) {
+	if len(p.key) == 0 || info.Ctx == nil {
+		return "", false
+	}
+
+	md, ok := metadata.FromOutgoingContext(info.Ctx)
+	if !ok {
+		return "", false
+	}
+
+	vals := md.Get(p.key)
+	if len(vals) == 0 {
+		return "", false
+	}
+
+	return vals[0], true
+}
+
+func (p *hashPicker) random(tracker *attempt.Tracker) string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if tracker == nil || tracker.Len() == 0 {
+		return p.addrs[p.r.Intn(len(p.addrs))]
+	}
+
+	candidates := make([]string, 0, len(p.addrs))
+	for _, addr := range p.addrs {
+		if !tracker.Picked(addr) {
+			candidates = append(candidates, addr)
+		}
+	}
+	if len(candidates) == 0 {
+		return p.addrs[p.r.Intn(len(p.addrs))]
+	}
+
+	return candidates[p.r.Intn(len(candidates))]
+}
@@ -0,0 +1,99 @@
+package consistenthash
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/attempt"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestHashPicker_PickNil(t *testing.T) {
+	builder := &hashPickerBuilder{key: "user"}
+	picker := builder.Build(base.PickerBuildInfo{})
+	_, err := picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            context.Background(),
+	})
+	assert.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
+}
+
+func TestHashPicker_Pick(t *testing.T) {
+	builder := &hashPickerBuilder{key: "user"}
+	picker := builder.Build(base.PickerBuildInfo{
+		ReadySCs: buildReadySCs(10),
+	})
+
+	for i := 0; i < 100; i++ {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "user", strconv.Itoa(i))
+		first, err := picker.Pick(balancer.PickInfo{
+			FullMethodName: "/",
+			Ctx:            ctx,
+		})
+		assert.NoError(t, err)
+		second, err := picker.Pick(balancer.PickInfo{
+			FullMethodName: "/",
+			Ctx:            ctx,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, first.SubConn, second.SubConn)
+	}
+
+	result, err := picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            context.Background(),
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, result.SubConn)
+}
+
+func TestHashPicker_PickWithTracker(t *testing.T) {
+	builder := &hashPickerBuilder{key: "user"}
+	picker := builder.Build(base.PickerBuildInfo{
+		ReadySCs: buildReadySCs(2),
+	})
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "user", "foo")
+	ctx, tracker := attempt.NewContext(ctx)
+	first, err := picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            ctx,
+	})
+	assert.NoError(t, err)
+	second, err := picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            ctx,
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.SubConn, second.SubConn)
+	assert.Equal(t, 2, tracker.Len())
+
+	_, err = picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            ctx,
+	})
+	assert.NoError(t, err)
+}
+
+func buildReadySCs(n int) map[balancer.SubConn]base.SubConnInfo {
+	ready := make(map[balancer.SubConn]base.SubConnInfo)
+	for i := 0; i < n; i++ {
+		ready[&mockSubConn{id: i}] = base.SubConnInfo{
+			Address: resolver.Address{
+				Addr: strconv.Itoa(i),
+			},
+		}
+	}
+
+	return ready
+}
+
+type mockSubConn struct {
+	balancer.SubConn
+	id int
+}
@@ -0,0 +1,102 @@
+package endpoint
+
+import (
+	"maps"
+
+	"google.golang.org/grpc/resolver"
+)
+
+type metadataKey struct{}
+
+// Metadata is the metadata of an endpoint, which is published in service discovery.
+type Metadata struct {
+	// Weight is the weight of the endpoint, used by weighted balancers.
+	Weight int
+	// Zone is the zone that the endpoint deployed in.
+	Zone string
+	// Version is the version of the endpoint.
+	Version string
+	// Tags are the custom tags of the endpoint.
+	Tags map[string]string
+}
+
+// Equal reports whether md equals to o, required by attributes.Attributes.
+func (md Metadata) Equal(o any) bool {
+	v, ok := o.(Metadata)
+	if !ok || md.Weight != v.Weight || md.Zone != v.Zone || md.Version != v.Version ||
+		len(md.Tags) != len(v.Tags) {
+		return false
+	}
+
+	for key, val := range md.Tags {
+		if tag, ok := v.Tags[key]; !ok || tag != val {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FromAddress returns the Metadata of addr.
+func FromAddress(addr resolver.Address) (Metadata, bool) {
+	if addr.BalancerAttributes == nil {
+		return Metadata{}, false
+	}
+
+	md, ok := addr.BalancerAttributes.Value(metadataKey{}).(Metadata)
+	return md, ok
+}
+
+// WithMetadata returns a copy of addr with md attached.
+func WithMetadata(addr resolver.Address, md Metadata) resolver.Address {
+	addr.BalancerAttributes = addr.BalancerAttributes.WithValue(metadataKey{}, md)
+	return addr
+}
+
+type (
+	groupKey        struct{}
+	groupWeightsKey struct{}
+
+	// GroupWeights is the traffic weights of the endpoint groups, keyed by the group names.
+	GroupWeights map[string]int
+)
+
+// Equal reports whether w equals to o, required by attributes.Attributes.
+func (w GroupWeights) Equal(o any) bool {
+	v, ok := o.(GroupWeights)
+	return ok && maps.Equal(w, v)
+}
+
+// GroupFromAddress returns the traffic splitting group of addr.
+func GroupFromAddress(addr resolver.Address) (string, bool) {
+	if addr.BalancerAttributes == nil {
+		return "", false
+	}
+
+	group, ok := addr.BalancerAttributes.Value(groupKey{}).(string)
+	return group, ok
+}
+
+// GroupWeightsFromState returns the GroupWeights of state.
+func GroupWeightsFromState(state resolver.State) (GroupWeights, bool) {
+	if state.Attributes == nil {
+		return nil, false
+	}
+
+	weights, ok := state.Attributes.Value(groupWeightsKey{}).(GroupWeights)
+	return weights, ok
+}
+
+// WithGroup returns a copy of addr with the traffic splitting group attached.
+func WithGroup(addr resolver.Address, group string) resolver.Address {
+	addr.BalancerAttributes = addr.BalancerAttributes.WithValue(groupKey{}, group)
+	return addr
+}
+
+// WithGroupWeights returns a copy of state with weights attached.
+// The weights are attached to the state instead of the addresses,
+// because the sub-conns are not updated with the changed balancer attributes.
+func WithGroupWeights(state resolver.State, weights GroupWeights) resolver.State {
+	state.Attributes = state.Attributes.WithValue(groupWeightsKey{}, weights)
+	return state
+}
@@ -0,0 +1,76 @@
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestMetadata(t *testing.T) {
+	addr := resolver.Address{
+		Addr: "localhost:8080",
+	}
+	_, ok := FromAddress(addr)
+	assert.False(t, ok)
+
+	md := Metadata{
+		Weight:  10,
+		Zone:    "zone-a",
+		Version: "v2",
+		Tags: map[string]string{
+			"lane": "canary",
+		},
+	}
+	addr = WithMetadata(addr, md)
+	val, ok := FromAddress(addr)
+	assert.True(t, ok)
+	assert.Equal(t, md, val)
+	assert.True(t, addr.Equal(WithMetadata(resolver.Address{
+		Addr: "localhost:8080",
+	}, md)))
+	assert.False(t, md.Equal(Metadata{}))
+	assert.False(t, md.Equal(Metadata{
+		Weight:  10,
+		Zone:    "zone-a",
+		Version: "v2",
+		Tags: map[string]string{
+			"lane": "stable",
+		},
+	}))
+	assert.False(t, md.Equal("foo"))
+}
+
+func TestGroup(t *testing.T) {
+	addr := resolver.Address{
+		Addr: "localhost:8080",
+	}
+	_, ok := GroupFromAddress(addr)
+	assert.False(t, ok)
+
+	addr = WithGroup(addr, "etcd://127.0.0.1:2379/svc-v1")
+	group, ok := GroupFromAddress(addr)
+	assert.True(t, ok)
+	assert.Equal(t, "etcd://127.0.0.1:2379/svc-v1", group)
+	assert.True(t, addr.Equal(WithGroup(resolver.Address{
+		Addr: "localhost:8080",
+	}, "etcd://127.0.0.1:2379/svc-v1")))
+}
+
+func TestGroupWeights(t *testing.T) {
+	var state resolver.State
+	_, ok := GroupWeightsFromState(state)
+	assert.False(t, ok)
+
+	weights := GroupWeights{
+		"v1": 90,
+		"v2": 10,
+	}
+	state = WithGroupWeights(state, weights)
+	val, ok := GroupWeightsFromState(state)
+	assert.True(t, ok)
+	assert.Equal(t, weights, val)
+	assert.True(t, weights.Equal(GroupWeights{"v1": 90, "v2": 10}))
+	assert.False(t, weights.Equal(GroupWeights{"v1": 10, "v2": 90}))
+	assert.False(t, weights.Equal("foo"))
+}
@@ -0,0 +1,180 @@
+package lbconfig
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+type (
+	// Config is the load balancing config shared by the zrpc balancers.
+	Config struct {
+		serviceconfig.LoadBalancingConfig `json:"-"`
+		// Zone is the zone of the client, the endpoints in the same zone are preferred.
+		Zone string `json:"zone,omitempty"`
+		// HashKey is the metadata key used by the consistent hash balancer.
+		HashKey string `json:"hashKey,omitempty"`
+		// Tags are the route tags that the endpoints are selected by, like version=v2,
+		// the route tags of the requests override the ones with the same keys.
+		Tags string `json:"tags,omitempty"`
+		// Fallback is the rule if no endpoints match the tags, all, untagged or none.
+		Fallback string `json:"fallback,omitempty"`
+		// OutlierDetection ejects the failing endpoints temporarily if enabled.
+		OutlierDetection OutlierConfig `json:"outlierDetection,omitempty"`
+	}
+
+	// PickerBuilderFunc creates a base.PickerBuilder with the given config.
+	PickerBuilderFunc func(c Config) base.PickerBuilder
+
+	builder struct {
+		name string
+		fn   PickerBuilderFunc
+	}
+
+	configurableBalancer struct {
+		balancer.Balancer
+		pb *configurablePickerBuilder
+	}
+
+	configurablePickerBuilder struct {
+		fn       PickerBuilderFunc
+		config   Config
+		pb       base.PickerBuilder
+		weights  endpoint.GroupWeights
+		detector *outlierDetector
+		lock     sync.Mutex
+	}
+)
+
+// NewBuilder returns a balancer.Builder with the given name, which parses the Config
+// from the service config, ejects the failing endpoints if outlier detection enabled,
+// splits the traffic across the endpoint groups by weight, selects the ready endpoints
+// by the route tags, and prefers the ones in the same zone.
+func NewBuilder(name string, fn PickerBuilderFunc) balancer.Builder {
+	return &builder{
+		name: name,
+		fn:   fn,
+	}
+}
+
+func (b *builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	pb := &configurablePickerBuilder{
+		fn: b.fn,
+		pb: b.fn(Config{}),
+	}
+
+	return &configurableBalancer{
+		Balancer: base.NewBalancerBuilder(b.name, pb, base.Config{HealthCheck: true}).Build(cc, opts),
+		pb:       pb,
+	}
+}
+
+func (b *builder) Name() string {
+	return b.name
+}
+
+func (b *builder) ParseConfig(js json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	var c Config
+	if len(js) > 0 {
+		if err := json.Unmarshal(js, &c); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateFallback(c.Fallback); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (b *configurableBalancer) ExitIdle() {
+	if ei, ok := b.Balancer.(balancer.ExitIdler); ok {
+		ei.ExitIdle()
+	}
+}
+
+func (b *configurableBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	if c, ok := s.BalancerConfig.(*Config); ok {
+		b.pb.setConfig(*c)
+	}
+	weights, _ := endpoint.GroupWeightsFromState(s.ResolverState)
+	b.pb.setWeights(weights)
+
+	return b.Balancer.UpdateClientConnState(s)
+}
+
+func (p *configurablePickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	p.lock.Lock()
+	config := p.config
+	pb := p.pb
+	weights := p.weights
+	detector := p.detector
+	p.lock.Unlock()
+
+	build := func(info base.PickerBuildInfo) balancer.Picker {
+		picker := newSplitPicker(info, weights, func(info base.PickerBuildInfo) balancer.Picker {
+			return newTagPicker(info, pb, config)
+		})
+		if picker != nil {
+			return picker
+		}
+
+		return newTagPicker(info, pb, config)
+	}
+	if detector != nil {
+		return newOutlierPicker(info, detector, build)
+	}
+
+	return build(info)
+}
+
+func (p *configurablePickerBuilder) setConfig(c Config) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.config == c {
+		return
+	}
+
+	if p.config.OutlierDetection != c.OutlierDetection {
+		if c.OutlierDetection.Enabled() {
+			p.detector = newOutlierDetector(c.OutlierDetection)
+		} else {
+			p.detector = nil
+		}
+	}
+	p.config = c
+	p.pb = p.fn(c)
+}
+
+func (p *configurablePickerBuilder) setWeights(weights endpoint.GroupWeights) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.weights = weights
+}
+
+// PreferZone returns the ready sub-conns in the given zone,
+// or all the sub-conns if zone is empty or no sub-conns in the zone.
+func PreferZone(readySCs map[balancer.SubConn]base.SubConnInfo,
+	zone string) map[balancer.SubConn]base.SubConnInfo {
+	if len(zone) == 0 {
+		return readySCs
+	}
+
+	zoned := make(map[balancer.SubConn]base.SubConnInfo)
+	for conn, info := range readySCs {
+		if md, ok := endpoint.FromAddress(info.Address); ok && md.Zone == zone {
+			zoned[conn] = info
+		}
+	}
+	if len(zoned) == 0 {
+		return readySCs
+	}
+
+	return zoned
+}
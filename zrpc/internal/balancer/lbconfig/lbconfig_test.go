@@ -0,0 +1,84 @@
+package lbconfig
+
+import (
+	"testing"
+
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestBuilder_ParseConfig(t *testing.T) {
+	b := NewBuilder("foo", func(_ Config) base.PickerBuilder {
+		return nil
+	})
+	assert.Equal(t, "foo", b.Name())
+
+	parser := b.(balancer.ConfigParser)
+	c, err := parser.ParseConfig([]byte(`{"zone":"a","hashKey":"user"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "a", c.(*Config).Zone)
+	assert.Equal(t, "user", c.(*Config).HashKey)
+
+	c, err = parser.ParseConfig(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{}, c)
+
+	_, err = parser.ParseConfig([]byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestConfigurablePickerBuilder(t *testing.T) {
+	var zones []string
+	pb := &configurablePickerBuilder{
+		fn: func(c Config) base.PickerBuilder {
+			return pickerBuilderFunc(func(info base.PickerBuildInfo) balancer.Picker {
+				zones = append(zones, c.Zone)
+				return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+			})
+		},
+	}
+	pb.pb = pb.fn(Config{})
+	pb.Build(base.PickerBuildInfo{})
+	pb.setConfig(Config{Zone: "a"})
+	pb.Build(base.PickerBuildInfo{})
+	pb.setConfig(Config{Zone: "a"})
+	pb.Build(base.PickerBuildInfo{})
+	assert.Equal(t, []string{"", "a", "a"}, zones)
+}
+
+func TestPreferZone(t *testing.T) {
+	ready := map[balancer.SubConn]base.SubConnInfo{
+		new(mockSubConn): {
+			Address: endpoint.WithMetadata(resolver.Address{Addr: "a"}, endpoint.Metadata{Zone: "zone-a"}),
+		},
+		new(mockSubConn): {
+			Address: endpoint.WithMetadata(resolver.Address{Addr: "b"}, endpoint.Metadata{Zone: "zone-b"}),
+		},
+		new(mockSubConn): {
+			Address: resolver.Address{Addr: "c"},
+		},
+	}
+
+	assert.Len(t, PreferZone(ready, ""), 3)
+	assert.Len(t, PreferZone(ready, "zone-c"), 3)
+	zoned := PreferZone(ready, "zone-a")
+	assert.Len(t, zoned, 1)
+	for _, info := range zoned {
+		assert.Equal(t, "a", info.Address.Addr)
+	}
+}
+
+type pickerBuilderFunc func(info base.PickerBuildInfo) balancer.Picker
+
+func (f pickerBuilderFunc) Build(info base.PickerBuildInfo) balancer.Picker {
+	return f(info)
+}
+
+type mockSubConn struct {
+	balancer.SubConn
+	// avoid zero size struct, which makes the pointers equal
+	_ int
+}
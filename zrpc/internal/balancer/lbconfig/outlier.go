@@ -0,0 +1,325 @@
+package lbconfig
+
+import (
+	"sync"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/metric"
+	"github.com/r27153733/fastgozero/core/timex"
+	"github.com/r27153733/fastgozero/zrpc/internal/codes"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+const (
+	clientNamespace = "rpc_client"
+
+	reasonConsecutiveErrors = "consecutive_errors"
+	reasonErrorRate         = "error_rate"
+
+	defaultMinRequests        = 20
+	defaultInterval           = 10 * time.Second
+	defaultBaseEjectionTime   = 30 * time.Second
+	defaultMaxEjectionTime    = 5 * time.Minute
+	defaultMaxEjectionPercent = 50
+)
+
+var (
+	metricOutlierEjections = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: clientNamespace,
+		Subsystem: "outlier",
+		Name:      "ejections_total",
+		Help:      "rpc client outlier ejections count.",
+		Labels:    []string{"addr", "reason"},
+	})
+
+	metricOutlierEjected = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: clientNamespace,
+		Subsystem: "outlier",
+		Name:      "ejected",
+		Help:      "rpc client outlier ejected endpoints, 1 means ejected.",
+		Labels:    []string{"addr"},
+	})
+)
+
+type (
+	// OutlierConfig is the config of the outlier detection, which ejects the failing
+	// endpoints temporarily, the failures are the same as the ones of the breakers.
+	OutlierConfig struct {
+		// ConsecutiveErrors ejects the endpoint on the consecutive errors, 0 means disabled.
+		ConsecutiveErrors int `json:"consecutiveErrors,omitempty"`
+		// ErrorRate ejects the endpoint if the error percentage in Interval reaches it,
+		// with at least MinRequests requests, 0 means disabled.
+		ErrorRate   int           `json:"errorRate,omitempty"`
+		MinRequests int           `json:"minRequests,omitempty"`
+		Interval    time.Duration `json:"interval,omitempty"`
+		// BaseEjectionTime is the first ejection time of an endpoint, which is doubled
+		// on each ejection in a row, up to MaxEjectionTime.
+		BaseEjectionTime time.Duration `json:"baseEjectionTime,omitempty"`
+		MaxEjectionTime  time.Duration `json:"maxEjectionTime,omitempty"`
+		// MaxEjectionPercent is the max percentage of the endpoints to eject.
+		MaxEjectionPercent int `json:"maxEjectionPercent,omitempty"`
+	}
+
+	outlierDetector struct {
+		c         OutlierConfig
+		now       func() time.Duration
+		stats     map[string]*outlierStat
+		endpoints int
+		// generation changes on ejections, to let the pickers rebuild.
+		generation uint64
+		lock       sync.Mutex
+	}
+
+	outlierStat struct {
+		consecutive  int
+		requests     int
+		errors       int
+		windowStart  time.Duration
+		ejectedUntil time.Duration
+		ejections    int
+		ejected      bool
+	}
+
+	// outlierPicker picks from the endpoints that are not ejected,
+	// and reports the results of the calls to the detector.
+	outlierPicker struct {
+		info       base.PickerBuildInfo
+		detector   *outlierDetector
+		build      func(base.PickerBuildInfo) balancer.Picker
+		picker     balancer.Picker
+		generation uint64
+		nextCheck  time.Duration
+		lock       sync.Mutex
+	}
+)
+
+// Enabled returns if the outlier detection is enabled.
+func (c OutlierConfig) Enabled() bool {
+	return c.ConsecutiveErrors > 0 || c.ErrorRate > 0
+}
+
+func (c OutlierConfig) withDefaults() OutlierConfig {
+	if c.MinRequests <= 0 {
+		c.MinRequests = defaultMinRequests
+	}
+	if c.Interval <= 0 {
+		c.Interval = defaultInterval
+	}
+	if c.BaseEjectionTime <= 0 {
+		c.BaseEjectionTime = defaultBaseEjectionTime
+	}
+	if c.MaxEjectionTime < c.BaseEjectionTime {
+		c.MaxEjectionTime = max(defaultMaxEjectionTime, c.BaseEjectionTime)
+	}
+	if c.MaxEjectionPercent <= 0 {
+		c.MaxEjectionPercent = defaultMaxEjectionPercent
+	}
+
+	return c
+}
+
+func newOutlierDetector(c OutlierConfig) *outlierDetector {
+	return &outlierDetector{
+		c:     c.withDefaults(),
+		now:   timex.Now,
+		stats: make(map[string]*outlierStat),
+	}
+}
+
+// refresh updates the endpoints, restores the ones whose ejection time passed,
+// and returns the ejected ones and the time to check again.
+func (d *outlierDetector) refresh(addrs map[string]struct{}) (map[string]struct{}, uint64, time.Duration) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.now()
+	d.endpoints = len(addrs)
+	ejected := make(map[string]struct{})
+	var nextCheck time.Duration
+	for addr, stat := range d.stats {
+		if _, ok := addrs[addr]; !ok {
+			if stat.ejected {
+				metricOutlierEjected.Set(0, addr)
+			}
+			delete(d.stats, addr)
+			continue
+		}
+		if !stat.ejected {
+			continue
+		}
+
+		if now >= stat.ejectedUntil {
+			stat.ejected = false
+			stat.reset(now)
+			metricOutlierEjected.Set(0, addr)
+			logx.Infof("[RPC] outlier detection restored endpoint %s", addr)
+			continue
+		}
+
+		ejected[addr] = struct{}{}
+		if nextCheck == 0 || stat.ejectedUntil < nextCheck {
+			nextCheck = stat.ejectedUntil
+		}
+	}
+
+	return ejected, d.generation, nextCheck
+}
+
+func (d *outlierDetector) report(addr string, err error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.now()
+	stat, ok := d.stats[addr]
+	if !ok {
+		stat = &outlierStat{windowStart: now}
+		d.stats[addr] = stat
+	}
+	// the calls that were in flight on ejection are ignored.
+	if stat.ejected {
+		return
+	}
+
+	if now-stat.windowStart >= d.c.Interval {
+		// the ejection time backs off after a healthy interval.
+		if stat.ejections > 0 {
+			stat.ejections--
+		}
+		stat.reset(now)
+	}
+
+	stat.requests++
+	if codes.Acceptable(err) {
+		stat.consecutive = 0
+	} else {
+		stat.consecutive++
+		stat.errors++
+	}
+
+	if d.c.ConsecutiveErrors > 0 && stat.consecutive >= d.c.ConsecutiveErrors {
+		d.eject(addr, stat, reasonConsecutiveErrors)
+	} else if d.c.ErrorRate > 0 && stat.requests >= d.c.MinRequests &&
+		stat.errors*100 >= d.c.ErrorRate*stat.requests {
+		d.eject(addr, stat, reasonErrorRate)
+	}
+}
+
+func (d *outlierDetector) eject(addr string, stat *outlierStat, reason string) {
+	var ejected int
+	for _, each := range d.stats {
+		if each.ejected {
+			ejected++
+		}
+	}
+	if (ejected+1)*100 > d.endpoints*d.c.MaxEjectionPercent {
+		logx.Errorf("[RPC] outlier detection skipped ejecting endpoint %s, reason: %s, "+
+			"ejected: %d, endpoints: %d", addr, reason, ejected, d.endpoints)
+		stat.reset(d.now())
+		return
+	}
+
+	ejectionTime := d.c.BaseEjectionTime << min(stat.ejections, 31)
+	if ejectionTime <= 0 || ejectionTime > d.c.MaxEjectionTime {
+		ejectionTime = d.c.MaxEjectionTime
+	}
+
+	now := d.now()
+	stat.ejections++
+	stat.ejected = true
+	stat.ejectedUntil = now + ejectionTime
+	stat.reset(now)
+	d.generation++
+	metricOutlierEjections.Inc(addr, reason)
+	metricOutlierEjected.Set(1, addr)
+	logx.Errorf("[RPC] outlier detection ejected endpoint %s for %s, reason: %s",
+		addr, ejectionTime, reason)
+}
+
+func (s *outlierStat) reset(now time.Duration) {
+	s.consecutive = 0
+	s.requests = 0
+	s.errors = 0
+	s.windowStart = now
+}
+
+func newOutlierPicker(info base.PickerBuildInfo, detector *outlierDetector,
+	build func(base.PickerBuildInfo) balancer.Picker) *outlierPicker {
+	p := &outlierPicker{
+		info:     info,
+		detector: detector,
+		build:    build,
+	}
+	p.rebuild()
+
+	return p
+}
+
+func (p *outlierPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	picker := p.getPicker()
+	result, err := picker.Pick(info)
+	if err != nil {
+		return result, err
+	}
+
+	connInfo, ok := p.info.ReadySCs[result.SubConn]
+	if !ok {
+		return result, nil
+	}
+
+	addr := connInfo.Address.Addr
+	done := result.Done
+	result.Done = func(di balancer.DoneInfo) {
+		p.detector.report(addr, di.Err)
+		if done != nil {
+			done(di)
+		}
+	}
+
+	return result, nil
+}
+
+func (p *outlierPicker) getPicker() balancer.Picker {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.detector.lock.Lock()
+	stale := p.generation != p.detector.generation ||
+		(p.nextCheck > 0 && p.detector.now() >= p.nextCheck)
+	p.detector.lock.Unlock()
+	if stale {
+		p.rebuild()
+	}
+
+	return p.picker
+}
+
+// rebuild builds the picker with the endpoints that are not ejected,
+// or all the endpoints if all of them are ejected.
+func (p *outlierPicker) rebuild() {
+	addrs := make(map[string]struct{}, len(p.info.ReadySCs))
+	for _, connInfo := range p.info.ReadySCs {
+		addrs[connInfo.Address.Addr] = struct{}{}
+	}
+
+	ejected, generation, nextCheck := p.detector.refresh(addrs)
+	p.generation = generation
+	p.nextCheck = nextCheck
+	if len(ejected) == 0 {
+		p.picker = p.build(p.info)
+		return
+	}
+
+	readySCs := make(map[balancer.SubConn]base.SubConnInfo, len(p.info.ReadySCs))
+	for conn, connInfo := range p.info.ReadySCs {
+		if _, ok := ejected[connInfo.Address.Addr]; !ok {
+			readySCs[conn] = connInfo
+		}
+	}
+	if len(readySCs) == 0 {
+		readySCs = p.info.ReadySCs
+	}
+
+	p.picker = p.build(base.PickerBuildInfo{ReadySCs: readySCs})
+}
@@ -0,0 +1,268 @@
+package lbconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+var errUnavailable = status.Error(codes.Unavailable, "unavailable")
+
+func TestOutlierConfig(t *testing.T) {
+	assert.False(t, OutlierConfig{}.Enabled())
+	assert.True(t, OutlierConfig{ConsecutiveErrors: 5}.Enabled())
+	assert.True(t, OutlierConfig{ErrorRate: 50}.Enabled())
+
+	assert.Equal(t, OutlierConfig{
+		ConsecutiveErrors:  5,
+		MinRequests:        defaultMinRequests,
+		Interval:           defaultInterval,
+		BaseEjectionTime:   defaultBaseEjectionTime,
+		MaxEjectionTime:    defaultMaxEjectionTime,
+		MaxEjectionPercent: defaultMaxEjectionPercent,
+	}, OutlierConfig{ConsecutiveErrors: 5}.withDefaults())
+	assert.Equal(t, 10*time.Minute, OutlierConfig{
+		BaseEjectionTime: 10 * time.Minute,
+	}.withDefaults().MaxEjectionTime)
+}
+
+func TestOutlierDetector_consecutiveErrors(t *testing.T) {
+	d, now := newTestDetector(OutlierConfig{
+		ConsecutiveErrors:  3,
+		BaseEjectionTime:   time.Second,
+		MaxEjectionTime:    3 * time.Second,
+		MaxEjectionPercent: 100,
+	})
+	refresh := func() map[string]struct{} {
+		ejected, _, _ := d.refresh(map[string]struct{}{"a": {}, "b": {}})
+		return ejected
+	}
+	refresh()
+
+	d.report("a", errUnavailable)
+	d.report("a", errUnavailable)
+	// acceptable errors reset the consecutive errors
+	d.report("a", status.Error(codes.NotFound, "not found"))
+	d.report("a", errUnavailable)
+	d.report("a", errUnavailable)
+	assert.Empty(t, refresh())
+	d.report("a", errUnavailable)
+	assert.Equal(t, map[string]struct{}{"a": {}}, refresh())
+	_, generation, nextCheck := d.refresh(map[string]struct{}{"a": {}, "b": {}})
+	assert.Equal(t, uint64(1), generation)
+	assert.Equal(t, *now+time.Second, nextCheck)
+
+	// the results of the ejected ones are ignored
+	d.report("a", nil)
+	assert.Equal(t, 0, d.stats["a"].requests)
+
+	// the ejection time doubles on each ejection in a row, up to the max
+	expects := []time.Duration{2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for _, expect := range expects {
+		*now += time.Hour
+		assert.Empty(t, refresh())
+		for i := 0; i < 3; i++ {
+			d.report("a", errUnavailable)
+		}
+		assert.Equal(t, *now+expect, d.stats["a"].ejectedUntil)
+	}
+}
+
+func TestOutlierDetector_errorRate(t *testing.T) {
+	d, now := newTestDetector(OutlierConfig{
+		ErrorRate:          50,
+		MinRequests:        4,
+		Interval:           time.Second,
+		MaxEjectionPercent: 100,
+	})
+	addrs := map[string]struct{}{"a": {}, "b": {}}
+	d.refresh(addrs)
+
+	d.report("a", errUnavailable)
+	d.report("a", errUnavailable)
+	d.report("a", nil)
+	ejected, _, _ := d.refresh(addrs)
+	assert.Empty(t, ejected)
+
+	// the window is reset after the interval
+	*now += time.Second
+	d.report("a", nil)
+	d.report("a", errUnavailable)
+	d.report("a", nil)
+	ejected, _, _ = d.refresh(addrs)
+	assert.Empty(t, ejected)
+	d.report("a", errUnavailable)
+	ejected, _, _ = d.refresh(addrs)
+	assert.Equal(t, map[string]struct{}{"a": {}}, ejected)
+
+	// the ejections back off after the healthy intervals
+	*now += time.Hour
+	d.refresh(addrs)
+	assert.Equal(t, 1, d.stats["a"].ejections)
+	d.report("a", nil)
+	*now += time.Second
+	d.report("a", nil)
+	assert.Equal(t, 0, d.stats["a"].ejections)
+
+	// the removed endpoints are cleaned up
+	d.refresh(map[string]struct{}{"b": {}})
+	assert.NotContains(t, d.stats, "a")
+}
+
+func TestOutlierDetector_maxEjectionPercent(t *testing.T) {
+	d, _ := newTestDetector(OutlierConfig{
+		ConsecutiveErrors:  1,
+		MaxEjectionPercent: 50,
+	})
+	addrs := map[string]struct{}{"a": {}, "b": {}, "c": {}}
+	d.refresh(addrs)
+
+	d.report("a", errUnavailable)
+	d.report("b", errUnavailable)
+	d.report("c", errUnavailable)
+	ejected, _, _ := d.refresh(addrs)
+	assert.Equal(t, map[string]struct{}{"a": {}}, ejected)
+}
+
+func TestOutlierPicker(t *testing.T) {
+	d, now := newTestDetector(OutlierConfig{
+		ConsecutiveErrors:  1,
+		BaseEjectionTime:   time.Second,
+		MaxEjectionPercent: 50,
+	})
+	connA, connB := new(mockSubConn), new(mockSubConn)
+	info := base.PickerBuildInfo{
+		ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+			connA: {Address: resolver.Address{Addr: "a"}},
+			connB: {Address: resolver.Address{Addr: "b"}},
+		},
+	}
+	var builds [][]string
+	var doneErr error
+	picker := newOutlierPicker(info, d, func(info base.PickerBuildInfo) balancer.Picker {
+		builds = append(builds, addrsOf(info.ReadySCs))
+		return &firstPicker{
+			info: info,
+			done: func(di balancer.DoneInfo) {
+				doneErr = di.Err
+			},
+		}
+	})
+	assert.Equal(t, [][]string{{"a", "b"}}, builds)
+
+	result, err := picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+	assert.NoError(t, err)
+	assert.Equal(t, connA, result.SubConn)
+	result.Done(balancer.DoneInfo{Err: errUnavailable})
+	assert.Equal(t, errUnavailable, doneErr)
+
+	result, err = picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+	assert.NoError(t, err)
+	assert.Equal(t, connB, result.SubConn)
+	assert.Equal(t, [][]string{{"a", "b"}, {"b"}}, builds)
+	// not rebuilt without changes
+	result.Done(balancer.DoneInfo{})
+	_, err = picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+	assert.NoError(t, err)
+	assert.Len(t, builds, 2)
+
+	// restored after the ejection time
+	*now += time.Second
+	result, err = picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+	assert.NoError(t, err)
+	assert.Equal(t, connA, result.SubConn)
+	assert.Equal(t, []string{"a", "b"}, builds[len(builds)-1])
+
+	picker = newOutlierPicker(info, d, func(info base.PickerBuildInfo) balancer.Picker {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	})
+	_, err = picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+	assert.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
+}
+
+func TestOutlierPicker_allEjected(t *testing.T) {
+	d, _ := newTestDetector(OutlierConfig{
+		ConsecutiveErrors:  1,
+		MaxEjectionPercent: 100,
+	})
+	info := base.PickerBuildInfo{
+		ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+			new(mockSubConn): {Address: resolver.Address{Addr: "a"}},
+		},
+	}
+	d.refresh(map[string]struct{}{"a": {}})
+	d.report("a", errors.New("foo"))
+
+	var builds [][]string
+	newOutlierPicker(info, d, func(info base.PickerBuildInfo) balancer.Picker {
+		builds = append(builds, addrsOf(info.ReadySCs))
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	})
+	assert.Equal(t, [][]string{{"a"}}, builds)
+}
+
+func TestConfigurablePickerBuilder_outlier(t *testing.T) {
+	pb := &configurablePickerBuilder{
+		fn: func(c Config) base.PickerBuilder {
+			return pickerBuilderFunc(func(info base.PickerBuildInfo) balancer.Picker {
+				return addrsPicker(addrsOf(info.ReadySCs))
+			})
+		},
+	}
+	pb.pb = pb.fn(Config{})
+	assert.IsType(t, &tagPicker{}, pb.Build(base.PickerBuildInfo{}))
+
+	pb.setConfig(Config{
+		OutlierDetection: OutlierConfig{ConsecutiveErrors: 5},
+	})
+	detector := pb.detector
+	assert.NotNil(t, detector)
+	assert.IsType(t, &outlierPicker{}, pb.Build(base.PickerBuildInfo{}))
+	pb.setConfig(Config{
+		Zone:             "a",
+		OutlierDetection: OutlierConfig{ConsecutiveErrors: 5},
+	})
+	assert.Equal(t, detector, pb.detector)
+	pb.setConfig(Config{})
+	assert.Nil(t, pb.detector)
+}
+
+// firstPicker picks the first ready sub-conn by address.
+type firstPicker struct {
+	info base.PickerBuildInfo
+	done func(balancer.DoneInfo)
+}
+
+func (p *firstPicker) Pick(_ balancer.PickInfo) (balancer.PickResult, error) {
+	var first balancer.SubConn
+	var addr string
+	for conn, info := range p.info.ReadySCs {
+		if first == nil || info.Address.Addr < addr {
+			first = conn
+			addr = info.Address.Addr
+		}
+	}
+
+	return balancer.PickResult{
+		SubConn: first,
+		Done:    p.done,
+	}, nil
+}
+
+func newTestDetector(c OutlierConfig) (*outlierDetector, *time.Duration) {
+	now := time.Hour
+	d := newOutlierDetector(c)
+	d.now = func() time.Duration {
+		return now
+	}
+
+	return d, &now
+}
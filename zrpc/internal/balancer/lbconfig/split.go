@@ -0,0 +1,89 @@
+package lbconfig
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+type (
+	splitGroup struct {
+		weight int
+		picker balancer.Picker
+	}
+
+	// splitPicker splits the traffic across the endpoint groups by weight,
+	// the endpoints in each group are picked by the underlying balancer.
+	splitPicker struct {
+		groups []splitGroup
+		total  int
+		r      *rand.Rand
+		lock   sync.Mutex
+	}
+)
+
+// newSplitPicker returns a picker that splits the traffic across the endpoint groups,
+// or nil if the endpoints are not grouped. The groups without ready endpoints
+// are skipped, so that their traffic goes to the other groups by weight.
+func newSplitPicker(info base.PickerBuildInfo, weights endpoint.GroupWeights,
+	build func(base.PickerBuildInfo) balancer.Picker) balancer.Picker {
+	var grouped bool
+	groups := make(map[string]map[balancer.SubConn]base.SubConnInfo)
+	for conn, connInfo := range info.ReadySCs {
+		g, ok := endpoint.GroupFromAddress(connInfo.Address)
+		if !ok {
+			continue
+		}
+
+		grouped = true
+		if weights[g] <= 0 {
+			continue
+		}
+
+		scs, ok := groups[g]
+		if !ok {
+			scs = make(map[balancer.SubConn]base.SubConnInfo)
+			groups[g] = scs
+		}
+		scs[conn] = connInfo
+	}
+	if !grouped {
+		return nil
+	}
+	if len(groups) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	p := &splitPicker{
+		r: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for g, scs := range groups {
+		p.groups = append(p.groups, splitGroup{
+			weight: weights[g],
+			picker: build(base.PickerBuildInfo{ReadySCs: scs}),
+		})
+		p.total += weights[g]
+	}
+
+	return p
+}
+
+func (p *splitPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	p.lock.Lock()
+	n := p.r.Intn(p.total)
+	p.lock.Unlock()
+
+	for _, g := range p.groups {
+		if n < g.weight {
+			return g.picker.Pick(info)
+		}
+		n -= g.weight
+	}
+
+	// unreachable, the groups are picked by the total weight
+	return p.groups[len(p.groups)-1].picker.Pick(info)
+}
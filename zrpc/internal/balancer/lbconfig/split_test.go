@@ -0,0 +1,88 @@
+package lbconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestNewSplitPicker(t *testing.T) {
+	build := func(info base.PickerBuildInfo) balancer.Picker {
+		return addrsPicker(addrsOf(info.ReadySCs))
+	}
+
+	t.Run("not grouped", func(t *testing.T) {
+		assert.Nil(t, newSplitPicker(base.PickerBuildInfo{
+			ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+				new(mockSubConn): {Address: resolver.Address{Addr: "a"}},
+			},
+		}, nil, build))
+	})
+
+	t.Run("zero weights", func(t *testing.T) {
+		p := newSplitPicker(base.PickerBuildInfo{
+			ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+				new(mockSubConn): {Address: endpoint.WithGroup(resolver.Address{Addr: "a"}, "v1")},
+			},
+		}, nil, build)
+		_, err := p.Pick(balancer.PickInfo{Ctx: context.Background()})
+		assert.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
+	})
+
+	t.Run("split", func(t *testing.T) {
+		p := newSplitPicker(base.PickerBuildInfo{
+			ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+				new(mockSubConn): {Address: endpoint.WithGroup(resolver.Address{Addr: "a"}, "v1")},
+				new(mockSubConn): {Address: endpoint.WithGroup(resolver.Address{Addr: "b"}, "v2")},
+				new(mockSubConn): {Address: endpoint.WithGroup(resolver.Address{Addr: "c"}, "v3")},
+				new(mockSubConn): {Address: resolver.Address{Addr: "d"}},
+			},
+		}, endpoint.GroupWeights{"v1": 90, "v2": 10}, build)
+
+		counts := make(map[string]int)
+		const total = 10000
+		for i := 0; i < total; i++ {
+			_, err := p.Pick(balancer.PickInfo{Ctx: context.Background()})
+			addrs := err.(addrsPicker)
+			assert.Len(t, addrs, 1)
+			counts[addrs[0]]++
+		}
+		assert.Len(t, counts, 2)
+		assert.InDelta(t, total*9/10, counts["a"], total/20)
+		assert.InDelta(t, total/10, counts["b"], total/20)
+	})
+}
+
+func TestConfigurablePickerBuilder_Split(t *testing.T) {
+	pb := &configurablePickerBuilder{
+		fn: func(c Config) base.PickerBuilder {
+			return pickerBuilderFunc(func(info base.PickerBuildInfo) balancer.Picker {
+				return addrsPicker(addrsOf(info.ReadySCs))
+			})
+		},
+	}
+	pb.pb = pb.fn(Config{})
+	info := base.PickerBuildInfo{
+		ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+			new(mockSubConn): {Address: endpoint.WithGroup(resolver.Address{Addr: "a"}, "v1")},
+			new(mockSubConn): {Address: endpoint.WithGroup(resolver.Address{Addr: "b"}, "v2")},
+		},
+	}
+
+	pb.setWeights(endpoint.GroupWeights{"v1": 100})
+	picker := pb.Build(info)
+	for i := 0; i < 10; i++ {
+		assertPicked(t, picker, context.Background(), []string{"a"})
+	}
+
+	pb.setWeights(endpoint.GroupWeights{"v2": 100})
+	picker = pb.Build(info)
+	for i := 0; i < 10; i++ {
+		assertPicked(t, picker, context.Background(), []string{"b"})
+	}
+}
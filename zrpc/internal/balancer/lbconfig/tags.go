@@ -0,0 +1,176 @@
+package lbconfig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/r27153733/fastgozero/core/routetag"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// FallbackAll falls back to all the endpoints if no endpoints match the tags.
+	FallbackAll = "all"
+	// FallbackUntagged falls back to the endpoints without version and tags,
+	// which are usually the stable ones, if no endpoints match the tags.
+	FallbackUntagged = "untagged"
+	// FallbackNone fails the requests if no endpoints match the tags.
+	FallbackNone = "none"
+
+	versionTag = "version"
+	// the route tags come from requests, limit the cached pickers to avoid memory leak.
+	maxTagPickers = 64
+)
+
+var errNoMatchedEndpoints = status.Error(codes.Unavailable, "no endpoints match the route tags")
+
+type tagPicker struct {
+	info     base.PickerBuildInfo
+	pb       base.PickerBuilder
+	zone     string
+	tags     map[string]string
+	fallback string
+	picker   balancer.Picker
+	pickers  map[string]balancer.Picker
+	lock     sync.Mutex
+}
+
+func newTagPicker(info base.PickerBuildInfo, pb base.PickerBuilder, c Config) *tagPicker {
+	p := &tagPicker{
+		info:     info,
+		pb:       pb,
+		zone:     c.Zone,
+		tags:     routetag.Parse(c.Tags),
+		fallback: c.Fallback,
+		pickers:  make(map[string]balancer.Picker),
+	}
+	p.picker = p.buildPicker(p.tags)
+
+	return p
+}
+
+func (p *tagPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	tags := requestTags(info.Ctx)
+	if len(tags) == 0 {
+		return p.picker.Pick(info)
+	}
+
+	return p.getPicker(tags).Pick(info)
+}
+
+func (p *tagPicker) buildPicker(selector map[string]string) balancer.Picker {
+	readySCs := SelectTags(p.info.ReadySCs, selector, p.fallback)
+	if len(readySCs) == 0 && len(p.info.ReadySCs) > 0 {
+		return base.NewErrPicker(errNoMatchedEndpoints)
+	}
+
+	return p.pb.Build(base.PickerBuildInfo{
+		ReadySCs: PreferZone(readySCs, p.zone),
+	})
+}
+
+func (p *tagPicker) getPicker(tags string) balancer.Picker {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if picker, ok := p.pickers[tags]; ok {
+		return picker
+	}
+
+	// the request tags override the static tags with the same keys
+	selector := routetag.Parse(tags)
+	for k, v := range p.tags {
+		if _, ok := selector[k]; !ok {
+			selector[k] = v
+		}
+	}
+
+	picker := p.buildPicker(selector)
+	if len(p.pickers) < maxTagPickers {
+		p.pickers[tags] = picker
+	}
+
+	return picker
+}
+
+// SelectTags returns the ready sub-conns matching all the given tags, the key version
+// matches the version of the endpoints, and the other keys match the endpoint tags.
+// If no sub-conns match, the fallback rule applies.
+func SelectTags(readySCs map[balancer.SubConn]base.SubConnInfo, tags map[string]string,
+	fallback string) map[balancer.SubConn]base.SubConnInfo {
+	if len(tags) == 0 {
+		return readySCs
+	}
+
+	matched := make(map[balancer.SubConn]base.SubConnInfo)
+	for conn, info := range readySCs {
+		if md, ok := endpoint.FromAddress(info.Address); ok && matchTags(md, tags) {
+			matched[conn] = info
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+
+	switch fallback {
+	case FallbackNone:
+		return nil
+	case FallbackUntagged:
+		for conn, info := range readySCs {
+			md, _ := endpoint.FromAddress(info.Address)
+			if len(md.Version) == 0 && len(md.Tags) == 0 {
+				matched[conn] = info
+			}
+		}
+		return matched
+	default:
+		return readySCs
+	}
+}
+
+func matchTags(md endpoint.Metadata, tags map[string]string) bool {
+	for k, v := range tags {
+		if k == versionTag {
+			if md.Version != v {
+				return false
+			}
+		} else if md.Tags[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func requestTags(ctx context.Context) string {
+	if tags := routetag.FromContext(ctx); len(tags) > 0 {
+		return tags
+	}
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	vals := md.Get(routetag.MetadataKey)
+	if len(vals) == 0 {
+		return ""
+	}
+
+	return vals[0]
+}
+
+func validateFallback(fallback string) error {
+	switch fallback {
+	case "", FallbackAll, FallbackUntagged, FallbackNone:
+		return nil
+	default:
+		return fmt.Errorf("unknown tag fallback: %q", fallback)
+	}
+}
@@ -0,0 +1,129 @@
+package lbconfig
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/r27153733/fastgozero/core/routetag"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+func TestBuilder_ParseConfigFallback(t *testing.T) {
+	b := NewBuilder("foo", func(_ Config) base.PickerBuilder {
+		return nil
+	})
+	parser := b.(balancer.ConfigParser)
+	c, err := parser.ParseConfig([]byte(`{"tags":"version=v2","fallback":"none"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "version=v2", c.(*Config).Tags)
+	assert.Equal(t, FallbackNone, c.(*Config).Fallback)
+
+	_, err = parser.ParseConfig([]byte(`{"fallback":"any"}`))
+	assert.Error(t, err)
+}
+
+func TestSelectTags(t *testing.T) {
+	ready := newTaggedReadySCs()
+
+	assert.Len(t, SelectTags(ready, nil, ""), 3)
+	assert.Equal(t, []string{"b"}, addrsOf(SelectTags(ready, map[string]string{"version": "v2"}, "")))
+	assert.Equal(t, []string{"b"}, addrsOf(SelectTags(ready, map[string]string{
+		"version": "v2",
+		"lane":    "canary",
+	}, "")))
+	assert.Equal(t, []string{"a"}, addrsOf(SelectTags(ready, map[string]string{"version": "v1"}, "")))
+	assert.Len(t, SelectTags(ready, map[string]string{"version": "v3"}, ""), 3)
+	assert.Len(t, SelectTags(ready, map[string]string{"version": "v3"}, FallbackAll), 3)
+	assert.Empty(t, SelectTags(ready, map[string]string{"version": "v3"}, FallbackNone))
+	assert.Equal(t, []string{"c"}, addrsOf(SelectTags(ready,
+		map[string]string{"lane": "blue"}, FallbackUntagged)))
+}
+
+func TestTagPicker(t *testing.T) {
+	pb := pickerBuilderFunc(func(info base.PickerBuildInfo) balancer.Picker {
+		return addrsPicker(addrsOf(info.ReadySCs))
+	})
+	ready := newTaggedReadySCs()
+
+	picker := newTagPicker(base.PickerBuildInfo{ReadySCs: ready}, pb, Config{})
+	assertPicked(t, picker, context.Background(), []string{"a", "b", "c"})
+	assertPicked(t, picker, routetag.NewContext(context.Background(), "version=v2"), []string{"b"})
+	assertPicked(t, picker, metadata.AppendToOutgoingContext(context.Background(),
+		routetag.MetadataKey, "version=v1"), []string{"a"})
+
+	picker = newTagPicker(base.PickerBuildInfo{ReadySCs: ready}, pb, Config{
+		Tags:     "version=v1",
+		Fallback: FallbackNone,
+	})
+	assertPicked(t, picker, context.Background(), []string{"a"})
+	assertPicked(t, picker, routetag.NewContext(context.Background(), "version=v2"), []string{"b"})
+	_, err := picker.Pick(balancer.PickInfo{
+		Ctx: routetag.NewContext(context.Background(), "lane=canary"),
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Len(t, picker.pickers, 2)
+}
+
+func TestTagPicker_maxPickers(t *testing.T) {
+	pb := pickerBuilderFunc(func(info base.PickerBuildInfo) balancer.Picker {
+		return addrsPicker(addrsOf(info.ReadySCs))
+	})
+	picker := newTagPicker(base.PickerBuildInfo{ReadySCs: newTaggedReadySCs()}, pb, Config{})
+	for i := 0; i < maxTagPickers*2; i++ {
+		picker.getPicker("version=" + string(rune('a'+i)))
+	}
+	assert.Len(t, picker.pickers, maxTagPickers)
+}
+
+func assertPicked(t *testing.T, picker balancer.Picker, ctx context.Context, expect []string) {
+	_, err := picker.Pick(balancer.PickInfo{Ctx: ctx})
+	assert.Equal(t, addrsPicker(expect), err)
+}
+
+func addrsOf(readySCs map[balancer.SubConn]base.SubConnInfo) []string {
+	var addrs []string
+	for _, info := range readySCs {
+		addrs = append(addrs, info.Address.Addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+func newTaggedReadySCs() map[balancer.SubConn]base.SubConnInfo {
+	return map[balancer.SubConn]base.SubConnInfo{
+		new(mockSubConn): {
+			Address: endpoint.WithMetadata(resolver.Address{Addr: "a"}, endpoint.Metadata{Version: "v1"}),
+		},
+		new(mockSubConn): {
+			Address: endpoint.WithMetadata(resolver.Address{Addr: "b"}, endpoint.Metadata{
+				Version: "v2",
+				Tags: map[string]string{
+					"lane": "canary",
+				},
+			}),
+		},
+		new(mockSubConn): {
+			Address: resolver.Address{Addr: "c"},
+		},
+	}
+}
+
+// addrsPicker returns the addresses of the ready sub-conns as the error,
+// to check which sub-conns the picker is built with.
+type addrsPicker []string
+
+func (p addrsPicker) Error() string {
+	return "addrs picker"
+}
+
+func (p addrsPicker) Pick(_ balancer.PickInfo) (balancer.PickResult, error) {
+	return balancer.PickResult{}, p
+}
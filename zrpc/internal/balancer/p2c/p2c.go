@@ -12,6 +12,8 @@ import (
 	"github.com/r27153733/fastgozero/core/logx"
 	"github.com/r27153733/fastgozero/core/syncx"
 	"github.com/r27153733/fastgozero/core/timex"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/attempt"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/lbconfig"
 	"github.com/r27153733/fastgozero/zrpc/internal/codes"
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
@@ -69,7 +71,9 @@ func (b *p2cPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
 }
 
 func newBuilder() balancer.Builder {
-	return base.NewBalancerBuilder(Name, new(p2cPickerBuilder), base.Config{HealthCheck: true})
+	return lbconfig.NewBuilder(Name, func(_ lbconfig.Config) base.PickerBuilder {
+		return new(p2cPickerBuilder)
+	})
 }
 
 type p2cPicker struct {
@@ -79,28 +83,31 @@ type p2cPicker struct {
 	lock  sync.Mutex
 }
 
-func (p *p2cPicker) Pick(_ balancer.PickInfo) (balancer.PickResult, error) {
+func (p *p2cPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	tracker := attempt.FromContext(info.Ctx)
+	conns := p.candidates(tracker)
+
 	var chosen *subConn
-	switch len(p.conns) {
+	switch len(conns) {
 	case 0:
 		return emptyPickResult, balancer.ErrNoSubConnAvailable
 	case 1:
-		chosen = p.choose(p.conns[0], nil)
+		chosen = p.choose(conns[0], nil)
 	case 2:
-		chosen = p.choose(p.conns[0], p.conns[1])
+		chosen = p.choose(conns[0], conns[1])
 	default:
 		var node1, node2 *subConn
 		for i := 0; i < pickTimes; i++ {
-			a := p.r.Intn(len(p.conns))
-			b := p.r.Intn(len(p.conns) - 1)
+			a := p.r.Intn(len(conns))
+			b := p.r.Intn(len(conns) - 1)
 			if b >= a {
 				b++
 			}
-			node1 = p.conns[a]
-			node2 = p.conns[b]
+			node1 = conns[a]
+			node2 = conns[b]
 			if node1.healthy() && node2.healthy() {
 				break
 			}
@@ -111,6 +118,9 @@ func (p *p2cPicker) Pick(_ balancer.PickInfo) (balancer.PickResult, error) {
 
 	atomic.AddInt64(&chosen.inflight, 1)
 	atomic.AddInt64(&chosen.requests, 1)
+	if tracker != nil {
+		tracker.Record(chosen.addr.Addr)
+	}
 
 	return balancer.PickResult{
 		SubConn: chosen.conn,
@@ -161,6 +171,26 @@ func (p *p2cPicker) buildDoneFunc(c *subConn) func(info balancer.DoneInfo) {
 	}
 }
 
+// candidates returns the conns that not picked by the previous attempts of the same call,
+// all conns are returned if every conn has been picked.
+func (p *p2cPicker) candidates(tracker *attempt.Tracker) []*subConn {
+	if tracker == nil || tracker.Len() == 0 {
+		return p.conns
+	}
+
+	conns := make([]*subConn, 0, len(p.conns))
+	for _, conn := range p.conns {
+		if !tracker.Picked(conn.addr.Addr) {
+			conns = append(conns, conn)
+		}
+	}
+	if len(conns) == 0 {
+		return p.conns
+	}
+
+	return conns
+}
+
 func (p *p2cPicker) choose(c1, c2 *subConn) *subConn {
 	start := int64(timex.Now())
 	if c2 == nil {
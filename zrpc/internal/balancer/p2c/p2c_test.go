@@ -11,6 +11,7 @@ import (
 	"github.com/r27153733/fastgozero/core/logx"
 	"github.com/r27153733/fastgozero/core/mathx"
 	"github.com/r27153733/fastgozero/core/stringx"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/attempt"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
@@ -132,6 +133,44 @@ func TestPickerWithEmptyConns(t *testing.T) {
 	assert.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
 }
 
+func TestP2cPicker_PickWithTracker(t *testing.T) {
+	builder := new(p2cPickerBuilder)
+	ready := make(map[balancer.SubConn]base.SubConnInfo)
+	for i := 0; i < 3; i++ {
+		ready[mockClientConn{
+			id: stringx.Rand(),
+		}] = base.SubConnInfo{
+			Address: resolver.Address{
+				Addr: strconv.Itoa(i),
+			},
+		}
+	}
+	picker := builder.Build(base.PickerBuildInfo{
+		ReadySCs: ready,
+	})
+
+	ctx, tracker := attempt.NewContext(context.Background())
+	picked := make(map[balancer.SubConn]struct{})
+	for i := 0; i < 3; i++ {
+		result, err := picker.Pick(balancer.PickInfo{
+			FullMethodName: "/",
+			Ctx:            ctx,
+		})
+		assert.NoError(t, err)
+		picked[result.SubConn] = struct{}{}
+		result.Done(balancer.DoneInfo{})
+	}
+	assert.Equal(t, 3, len(picked))
+	assert.Equal(t, 3, tracker.Len())
+
+	// all conns are picked, fall back to all conns
+	_, err := picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            ctx,
+	})
+	assert.NoError(t, err)
+}
+
 type mockClientConn struct {
 	// add random string member to avoid map key equality.
 	id string
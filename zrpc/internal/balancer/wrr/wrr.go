@@ -0,0 +1,115 @@
+package wrr
+
+import (
+	"sync"
+
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/attempt"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/lbconfig"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+const (
+	// Name is the name of weighted round robin balancer.
+	Name = "wrr"
+
+	// defaultWeight is the weight of the endpoints without weight published.
+	defaultWeight = 1
+)
+
+func init() {
+	balancer.Register(newBuilder())
+}
+
+type wrrPickerBuilder struct{}
+
+func (b *wrrPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	conns := make([]*subConn, 0, len(info.ReadySCs))
+	for conn, connInfo := range info.ReadySCs {
+		weight := defaultWeight
+		if md, ok := endpoint.FromAddress(connInfo.Address); ok && md.Weight > 0 {
+			weight = md.Weight
+		}
+
+		conns = append(conns, &subConn{
+			addr:   connInfo.Address.Addr,
+			conn:   conn,
+			weight: weight,
+		})
+	}
+
+	return &wrrPicker{
+		conns: conns,
+	}
+}
+
+func newBuilder() balancer.Builder {
+	return lbconfig.NewBuilder(Name, func(_ lbconfig.Config) base.PickerBuilder {
+		return new(wrrPickerBuilder)
+	})
+}
+
+// wrrPicker picks the sub-conns with the smooth weighted round robin algorithm.
+type wrrPicker struct {
+	conns []*subConn
+	lock  sync.Mutex
+}
+
+func (p *wrrPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	tracker := attempt.FromContext(info.Ctx)
+	var chosen *subConn
+	// the retried attempts go to the sub-conns not picked yet
+	if tracker != nil && tracker.Len() > 0 {
+		chosen = p.choose(tracker)
+	}
+	if chosen == nil {
+		chosen = p.choose(nil)
+	}
+	if chosen == nil {
+		return balancer.PickResult{}, balancer.ErrNoSubConnAvailable
+	}
+
+	if tracker != nil {
+		tracker.Record(chosen.addr)
+	}
+
+	return balancer.PickResult{
+		SubConn: chosen.conn,
+	}, nil
+}
+
+func (p *wrrPicker) choose(tracker *attempt.Tracker) *subConn {
+	var chosen *subConn
+	var total int
+	for _, c := range p.conns {
+		if tracker != nil && tracker.Picked(c.addr) {
+			continue
+		}
+
+		c.current += c.weight
+		total += c.weight
+		if chosen == nil || c.current > chosen.current {
+			chosen = c
+		}
+	}
+	if chosen != nil {
+		chosen.current -= total
+	}
+
+	return chosen
+}
+
+type subConn struct {
+	addr    string
+	conn    balancer.SubConn
+	weight  int
+	current int
+}
@@ -0,0 +1,101 @@
+package wrr
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/attempt"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestWrrPicker_PickNil(t *testing.T) {
+	builder := new(wrrPickerBuilder)
+	picker := builder.Build(base.PickerBuildInfo{})
+	_, err := picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            context.Background(),
+	})
+	assert.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
+
+	_, err = new(wrrPicker).Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            context.Background(),
+	})
+	assert.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
+}
+
+func TestWrrPicker_Pick(t *testing.T) {
+	ready := make(map[balancer.SubConn]base.SubConnInfo)
+	weights := []int{1, 3, 0}
+	for i, weight := range weights {
+		ready[&mockSubConn{id: i}] = base.SubConnInfo{
+			Address: endpoint.WithMetadata(resolver.Address{
+				Addr: strconv.Itoa(i),
+			}, endpoint.Metadata{
+				Weight: weight,
+			}),
+		}
+	}
+	builder := new(wrrPickerBuilder)
+	picker := builder.Build(base.PickerBuildInfo{
+		ReadySCs: ready,
+	})
+
+	dist := make(map[int]int)
+	for i := 0; i < 500; i++ {
+		result, err := picker.Pick(balancer.PickInfo{
+			FullMethodName: "/",
+			Ctx:            context.Background(),
+		})
+		assert.NoError(t, err)
+		dist[result.SubConn.(*mockSubConn).id]++
+	}
+	assert.Equal(t, 100, dist[0])
+	assert.Equal(t, 300, dist[1])
+	assert.Equal(t, 100, dist[2])
+}
+
+func TestWrrPicker_PickWithTracker(t *testing.T) {
+	ready := map[balancer.SubConn]base.SubConnInfo{
+		&mockSubConn{id: 0}: {
+			Address: endpoint.WithMetadata(resolver.Address{Addr: "0"}, endpoint.Metadata{Weight: 100}),
+		},
+		&mockSubConn{id: 1}: {
+			Address: resolver.Address{Addr: "1"},
+		},
+	}
+	builder := new(wrrPickerBuilder)
+	picker := builder.Build(base.PickerBuildInfo{
+		ReadySCs: ready,
+	})
+
+	ctx, tracker := attempt.NewContext(context.Background())
+	first, err := picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            ctx,
+	})
+	assert.NoError(t, err)
+	second, err := picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            ctx,
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.SubConn, second.SubConn)
+	assert.Equal(t, 2, tracker.Len())
+
+	_, err = picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            ctx,
+	})
+	assert.NoError(t, err)
+}
+
+type mockSubConn struct {
+	balancer.SubConn
+	id int
+}
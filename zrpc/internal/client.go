@@ -2,17 +2,24 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	_ "github.com/r27153733/fastgozero/zrpc/internal/balancer/consistenthash"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/lbconfig"
 	"github.com/r27153733/fastgozero/zrpc/internal/balancer/p2c"
+	_ "github.com/r27153733/fastgozero/zrpc/internal/balancer/wrr"
 	"github.com/r27153733/fastgozero/zrpc/internal/clientinterceptors"
+	_ "github.com/r27153733/fastgozero/zrpc/internal/encoding/zstd"
+	"github.com/r27153733/fastgozero/zrpc/internal/inproc"
 	"github.com/r27153733/fastgozero/zrpc/resolver"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/encoding/gzip"
 )
 
 const (
@@ -35,12 +42,19 @@ type (
 		NonBlock    bool
 		Timeout     time.Duration
 		Secure      bool
+		Retry       RetryConf
+		Methods     []MethodConf
 		DialOptions []grpc.DialOption
 	}
 
 	// ClientOption defines the method to customize a ClientOptions.
 	ClientOption func(options *ClientOptions)
 
+	// BalancerConfig is the config of the load balancing policy.
+	BalancerConfig = lbconfig.Config
+	// OutlierConfig is the config of the outlier detection of the balancers.
+	OutlierConfig = lbconfig.OutlierConfig
+
 	client struct {
 		conn        *grpc.ClientConn
 		middlewares ClientMiddlewaresConf
@@ -56,6 +70,14 @@ func NewClient(target string, middlewares ClientMiddlewaresConf, opts ...ClientO
 	svcCfg := fmt.Sprintf(`{"loadBalancingPolicy":"%s"}`, p2c.Name)
 	balancerOpt := WithDialOption(grpc.WithDefaultServiceConfig(svcCfg))
 	opts = append([]ClientOption{balancerOpt}, opts...)
+	if _, ok := inproc.ParseTarget(target); ok {
+		opts = append(opts, WithDialOption(grpc.WithResolvers(inproc.NewResolverBuilder())),
+			WithDialOption(grpc.WithContextDialer(inproc.Dial)))
+	}
+	if resolver.IsSplitTarget(target) {
+		// the split target is removed on closing its resolver, which is rebuilt on exiting idle.
+		opts = append(opts, WithDialOption(grpc.WithIdleTimeout(0)))
+	}
 	if err := cli.dial(target, opts...); err != nil {
 		return nil, err
 	}
@@ -67,12 +89,13 @@ func (c *client) Conn() *grpc.ClientConn {
 	return c.conn
 }
 
-func (c *client) buildDialOptions(opts ...ClientOption) []grpc.DialOption {
+func (c *client) buildDialOptions(opts ...ClientOption) ([]grpc.DialOption, error) {
 	var cliOpts ClientOptions
 	for _, opt := range opts {
 		opt(&cliOpts)
 	}
 
+
 	var options []grpc.DialOption
 	if !cliOpts.Secure {
 		options = append([]grpc.DialOption(nil),
@@ -83,27 +106,44 @@ func (c *client) buildDialOptions(opts ...ClientOption) []grpc.DialOption {
 		options = append(options, grpc.WithBlock())
 	}
 
+	unaryInterceptors, err := c.buildUnaryInterceptors(cliOpts.Timeout, cliOpts.Retry, cliOpts.Methods)
+	if err != nil {
+		return nil, err
+	}
+
 	options = append(options,
-		grpc.WithChainUnaryInterceptor(c.buildUnaryInterceptors(cliOpts.Timeout)...),
-		grpc.WithChainStreamInterceptor(c.buildStreamInterceptors()...),
+		grpc.WithStatsHandler(newClientOversizeHandler()),
+		grpc.WithChainUnaryInterceptor(unaryInterceptors...),
+		grpc.WithChainStreamInterceptor(c.buildStreamInterceptors(cliOpts.Methods)...),
 	)
 
-	return append(options, cliOpts.DialOptions...)
+	return append(options, cliOpts.DialOptions...), nil
 }
 
-func (c *client) buildStreamInterceptors() []grpc.StreamClientInterceptor {
+func (c *client) buildStreamInterceptors(methods []MethodConf) []grpc.StreamClientInterceptor {
 	var interceptors []grpc.StreamClientInterceptor
 
+	if len(methods) > 0 {
+		interceptors = append(interceptors, clientinterceptors.StreamMethodInterceptor(methods))
+	}
 	if c.middlewares.Trace {
 		interceptors = append(interceptors, clientinterceptors.StreamTracingInterceptor)
 	}
+	if c.middlewares.RouteTag {
+		interceptors = append(interceptors, clientinterceptors.StreamRouteTagInterceptor)
+	}
 
 	return interceptors
 }
 
-func (c *client) buildUnaryInterceptors(timeout time.Duration) []grpc.UnaryClientInterceptor {
+func (c *client) buildUnaryInterceptors(timeout time.Duration, retry RetryConf,
+	methods []MethodConf) ([]grpc.UnaryClientInterceptor, error) {
 	var interceptors []grpc.UnaryClientInterceptor
 
+	if len(methods) > 0 {
+		interceptors = append(interceptors, clientinterceptors.MethodInterceptor(methods,
+			c.middlewares.Breaker))
+	}
 	if c.middlewares.Trace {
 		interceptors = append(interceptors, clientinterceptors.UnaryTracingInterceptor)
 	}
@@ -113,18 +153,33 @@ func (c *client) buildUnaryInterceptors(timeout time.Duration) []grpc.UnaryClien
 	if c.middlewares.Prometheus {
 		interceptors = append(interceptors, clientinterceptors.PrometheusInterceptor)
 	}
-	if c.middlewares.Breaker {
+	if c.middlewares.Breaker || clientinterceptors.BreakerEnabled(methods) {
 		interceptors = append(interceptors, clientinterceptors.BreakerInterceptor)
 	}
 	if c.middlewares.Timeout {
 		interceptors = append(interceptors, clientinterceptors.TimeoutInterceptor(timeout))
 	}
+	if c.middlewares.RouteTag {
+		interceptors = append(interceptors, clientinterceptors.UnaryRouteTagInterceptor)
+	}
+	if retry.Enabled() {
+		interceptor, err := clientinterceptors.RetryInterceptor(retry)
+		if err != nil {
+			return nil, err
+		}
+
+		interceptors = append(interceptors, interceptor)
+	}
 
-	return interceptors
+	return interceptors, nil
 }
 
 func (c *client) dial(server string, opts ...ClientOption) error {
-	options := c.buildDialOptions(opts...)
+	options, err := c.buildDialOptions(opts...)
+	if err != nil {
+		return err
+	}
+
 	timeCtx, cancel := context.WithTimeout(context.Background(), dialTimeout)
 	defer cancel()
 	conn, err := grpc.DialContext(timeCtx, server, options...)
@@ -145,6 +200,24 @@ func (c *client) dial(server string, opts ...ClientOption) error {
 	return nil
 }
 
+// WithBalancer returns a func to customize a ClientOptions with given load balancing policy.
+func WithBalancer(policy string, c BalancerConfig) ClientOption {
+	return func(options *ClientOptions) {
+		val, err := json.Marshal([]map[string]BalancerConfig{
+			{
+				policy: c,
+			},
+		})
+		if err != nil {
+			// never happens, marshaling a plain struct
+			panic(err)
+		}
+
+		svcCfg := fmt.Sprintf(`{"loadBalancingConfig":%s}`, val)
+		options.DialOptions = append(options.DialOptions, grpc.WithDefaultServiceConfig(svcCfg))
+	}
+}
+
 // WithDialOption returns a func to customize a ClientOptions with given dial option.
 func WithDialOption(opt grpc.DialOption) ClientOption {
 	return func(options *ClientOptions) {
@@ -152,6 +225,13 @@ func WithDialOption(opt grpc.DialOption) ClientOption {
 	}
 }
 
+// WithMethods returns a func to customize a ClientOptions with given method configs.
+func WithMethods(methods []MethodConf) ClientOption {
+	return func(options *ClientOptions) {
+		options.Methods = methods
+	}
+}
+
 // WithNonBlock sets the dialing to be nonblock.
 func WithNonBlock() ClientOption {
 	return func(options *ClientOptions) {
@@ -159,6 +239,13 @@ func WithNonBlock() ClientOption {
 	}
 }
 
+// WithRetry returns a func to customize a ClientOptions with given retry policy.
+func WithRetry(retry RetryConf) ClientOption {
+	return func(options *ClientOptions) {
+		options.Retry = retry
+	}
+}
+
 // WithStreamClientInterceptor returns a func to customize a ClientOptions with given interceptor.
 func WithStreamClientInterceptor(interceptor grpc.StreamClientInterceptor) ClientOption {
 	return func(options *ClientOptions) {
@@ -34,6 +34,24 @@ func TestWithNonBlock(t *testing.T) {
 	assert.True(t, options.NonBlock)
 }
 
+func TestWithBalancer(t *testing.T) {
+	var options ClientOptions
+	opt := WithBalancer("consistent_hash", BalancerConfig{
+		HashKey: "user",
+	})
+	opt(&options)
+	assert.Equal(t, 1, len(options.DialOptions))
+}
+
+func TestWithRetry(t *testing.T) {
+	var options ClientOptions
+	opt := WithRetry(RetryConf{
+		MaxAttempts: 3,
+	})
+	opt(&options)
+	assert.Equal(t, 3, options.Retry.MaxAttempts)
+}
+
 func TestWithStreamClientInterceptor(t *testing.T) {
 	var options ClientOptions
 	opt := WithStreamClientInterceptor(func(ctx context.Context, desc *grpc.StreamDesc,
@@ -73,8 +91,15 @@ func TestBuildDialOptions(t *testing.T) {
 		},
 	}
 	agent := grpc.WithUserAgent("chrome")
-	opts := c.buildDialOptions(WithDialOption(agent))
+	opts, err := c.buildDialOptions(WithDialOption(agent))
+	assert.NoError(t, err)
 	assert.Contains(t, opts, agent)
+
+	_, err = c.buildDialOptions(WithRetry(RetryConf{
+		MaxAttempts: 3,
+		Codes:       []string{"NOT_A_CODE"},
+	}))
+	assert.Error(t, err)
 }
 
 func TestClientDial(t *testing.T) {
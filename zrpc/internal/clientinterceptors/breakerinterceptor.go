@@ -12,6 +12,10 @@ import (
 // BreakerInterceptor is an interceptor that acts as a circuit breaker.
 func BreakerInterceptor(ctx context.Context, method string, req, reply any,
 	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if breakerDisabled(opts) {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
 	breakerName := path.Join(cc.Target(), method)
 	return breaker.DoWithAcceptableCtx(ctx, breakerName, func() error {
 		return invoker(ctx, method, req, reply, cc, opts...)
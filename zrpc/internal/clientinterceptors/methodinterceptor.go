@@ -0,0 +1,200 @@
+package clientinterceptors
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	anyMethodSuffix = "*"
+	breakerOn       = "on"
+	breakerOff      = "off"
+)
+
+type (
+	// MethodConf defines the call options of the specified gRPC methods.
+	MethodConf struct {
+		// FullMethod is the full method name, like /pkg.Service/Method, the trailing *
+		// matches any suffix, like /pkg.Service/*.
+		FullMethod string
+		// Timeout overrides the client timeout if greater than 0,
+		// the timeout set by WithCallTimeout takes precedence.
+		Timeout time.Duration `json:",optional"`
+		// MaxAttempts enables retries if greater than 1, the retry policy of the client is
+		// used if not set.
+		MaxAttempts int `json:",optional"`
+		// RetryCodes, InitialBackoff, MaxBackoff and BackoffMultiplier override the ones
+		// of the client retry policy if set.
+		RetryCodes        []string      `json:",optional"`
+		InitialBackoff    time.Duration `json:",optional"`
+		MaxBackoff        time.Duration `json:",optional"`
+		BackoffMultiplier float64       `json:",optional"`
+		// Breaker turns the breaker on or off for the methods, empty means following
+		// ClientMiddlewaresConf.Breaker.
+		Breaker string `json:",optional,options=on|off"`
+		// WaitForReady blocks the calls until the connections are ready or the deadline
+		// is exceeded, instead of failing fast.
+		WaitForReady bool `json:",optional"`
+		// Compressor compresses the requests of the methods, overrides the one of the client.
+		Compressor string `json:",optional,options=gzip|zstd"`
+	}
+
+	// breakerCallOption is a call option that disables the breaker.
+	breakerCallOption struct {
+		grpc.EmptyCallOption
+	}
+
+	methodCallOptions struct {
+		prefix  string
+		options []grpc.CallOption
+	}
+
+	methodMatcher struct {
+		methods   map[string][]grpc.CallOption
+		wildcards []methodCallOptions
+		// defaults are the options of the methods not matched.
+		defaults []grpc.CallOption
+	}
+)
+
+// MethodInterceptor returns an interceptor that applies the call options of the methods,
+// it should be the first interceptor to let the following ones see the options.
+// The breaker is disabled on the methods not turning it on if breaker is false.
+func MethodInterceptor(methods []MethodConf, breaker bool) grpc.UnaryClientInterceptor {
+	matcher := newMethodMatcher(methods, breaker)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(ctx, method, req, reply, cc, matcher.apply(method, opts)...)
+	}
+}
+
+// StreamMethodInterceptor returns an interceptor that applies the call options of the methods,
+// the timeouts are not applied to streams.
+func StreamMethodInterceptor(methods []MethodConf) grpc.StreamClientInterceptor {
+	matcher := newMethodMatcher(methods, true)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(ctx, desc, cc, method, matcher.apply(method, opts)...)
+	}
+}
+
+// BreakerEnabled returns true if the breaker is turned on for any methods.
+func BreakerEnabled(methods []MethodConf) bool {
+	for _, m := range methods {
+		if m.Breaker == breakerOn {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryMethods returns the retry policies of the methods with retries enabled.
+func RetryMethods(methods []MethodConf) []MethodRetryConf {
+	var retries []MethodRetryConf
+	for _, m := range methods {
+		if m.MaxAttempts == 0 {
+			continue
+		}
+
+		retries = append(retries, MethodRetryConf{
+			FullMethod:        m.FullMethod,
+			MaxAttempts:       m.MaxAttempts,
+			Codes:             m.RetryCodes,
+			InitialBackoff:    m.InitialBackoff,
+			MaxBackoff:        m.MaxBackoff,
+			BackoffMultiplier: m.BackoffMultiplier,
+		})
+	}
+
+	return retries
+}
+
+func newMethodMatcher(methods []MethodConf, breaker bool) *methodMatcher {
+	matcher := &methodMatcher{
+		methods: make(map[string][]grpc.CallOption, len(methods)),
+	}
+	if !breaker {
+		matcher.defaults = []grpc.CallOption{breakerCallOption{}}
+	}
+
+	for _, m := range methods {
+		options := m.callOptions(breaker)
+		if prefix, ok := wildcardPrefix(m.FullMethod); ok {
+			matcher.wildcards = append(matcher.wildcards, methodCallOptions{
+				prefix:  prefix,
+				options: options,
+			})
+		} else if _, ok := matcher.methods[m.FullMethod]; !ok {
+			matcher.methods[m.FullMethod] = options
+		}
+	}
+
+	return matcher
+}
+
+// apply prepends the options of the method before opts, the ones in opts take precedence,
+// because grpc applies the call options in order, the later ones override the earlier ones.
+func (m *methodMatcher) apply(method string, opts []grpc.CallOption) []grpc.CallOption {
+	options := m.match(method)
+	if len(options) == 0 {
+		return opts
+	}
+
+	merged := make([]grpc.CallOption, 0, len(options)+len(opts))
+	merged = append(merged, options...)
+	return append(merged, opts...)
+}
+
+func (m *methodMatcher) match(method string) []grpc.CallOption {
+	if options, ok := m.methods[method]; ok {
+		return options
+	}
+
+	for _, w := range m.wildcards {
+		if strings.HasPrefix(method, w.prefix) {
+			return w.options
+		}
+	}
+
+	return m.defaults
+}
+
+func (m MethodConf) callOptions(breaker bool) []grpc.CallOption {
+	var options []grpc.CallOption
+	if m.Timeout > 0 {
+		options = append(options, WithCallTimeout(m.Timeout))
+	}
+	if m.Breaker == breakerOff || len(m.Breaker) == 0 && !breaker {
+		options = append(options, breakerCallOption{})
+	}
+	if m.WaitForReady {
+		options = append(options, grpc.WaitForReady(true))
+	}
+	if len(m.Compressor) > 0 {
+		options = append(options, grpc.UseCompressor(m.Compressor))
+	}
+
+	return options
+}
+
+func breakerDisabled(opts []grpc.CallOption) bool {
+	for _, opt := range opts {
+		if _, ok := opt.(breakerCallOption); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func wildcardPrefix(method string) (string, bool) {
+	if !strings.HasSuffix(method, anyMethodSuffix) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(method, anyMethodSuffix), true
+}
@@ -0,0 +1,165 @@
+package clientinterceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestMethodInterceptor(t *testing.T) {
+	methods := []MethodConf{
+		{
+			FullMethod:   "/foo.Foo/Bar",
+			Timeout:      time.Second,
+			Breaker:      breakerOff,
+			WaitForReady: true,
+			Compressor:   "gzip",
+		},
+		{
+			FullMethod: "/foo.Foo/*",
+			Timeout:    time.Second * 2,
+			Breaker:    breakerOn,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		breaker  bool
+		opts     []grpc.CallOption
+		timeout  time.Duration
+		disabled bool
+		length   int
+	}{
+		{
+			name:     "exact",
+			method:   "/foo.Foo/Bar",
+			breaker:  true,
+			timeout:  time.Second,
+			disabled: true,
+			length:   4,
+		},
+		{
+			name:    "wildcard",
+			method:  "/foo.Foo/Baz",
+			timeout: time.Second * 2,
+			length:  1,
+		},
+		{
+			name:    "call timeout first",
+			method:  "/foo.Foo/Baz",
+			breaker: true,
+			opts:    []grpc.CallOption{WithCallTimeout(time.Millisecond)},
+			timeout: time.Millisecond,
+			length:  2,
+		},
+		{
+			name:    "not matched",
+			method:  "/bar.Bar/Foo",
+			breaker: true,
+			length:  0,
+		},
+		{
+			name:     "not matched without breaker",
+			method:   "/bar.Bar/Foo",
+			disabled: true,
+			length:   1,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			interceptor := MethodInterceptor(methods, test.breaker)
+			err := interceptor(context.Background(), test.method, nil, nil, nil,
+				func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+					opts ...grpc.CallOption) error {
+					assert.Len(t, opts, test.length)
+					assert.Equal(t, test.timeout, getTimeoutFromCallOptions(opts, 0))
+					assert.Equal(t, test.disabled, breakerDisabled(opts))
+					return nil
+				}, test.opts...)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMethodInterceptor_CallOptionsFirst(t *testing.T) {
+	interceptor := MethodInterceptor([]MethodConf{
+		{
+			FullMethod: "/foo.Foo/Bar",
+			Timeout:    time.Second,
+			Compressor: "gzip",
+		},
+	}, true)
+	err := interceptor(context.Background(), "/foo.Foo/Bar", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			// grpc applies the call options in order, the last compressor is used.
+			var compressor string
+			for _, opt := range opts {
+				if o, ok := opt.(grpc.CompressorCallOption); ok {
+					compressor = o.CompressorType
+				}
+			}
+			assert.Equal(t, "identity", compressor)
+			assert.Equal(t, time.Millisecond, getTimeoutFromCallOptions(opts, 0))
+			return nil
+		}, grpc.UseCompressor("identity"), WithCallTimeout(time.Millisecond))
+	assert.NoError(t, err)
+}
+
+func TestStreamMethodInterceptor(t *testing.T) {
+	interceptor := StreamMethodInterceptor([]MethodConf{
+		{
+			FullMethod:   "/foo.Foo/*",
+			WaitForReady: true,
+		},
+	})
+	_, err := interceptor(context.Background(), nil, nil, "/foo.Foo/Bar",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+			opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			assert.Len(t, opts, 1)
+			return nil, nil
+		})
+	assert.NoError(t, err)
+}
+
+func TestBreakerEnabledAndRetryMethods(t *testing.T) {
+	methods := []MethodConf{
+		{
+			FullMethod:  "/foo.Foo/Bar",
+			MaxAttempts: 3,
+			RetryCodes:  []string{"ABORTED"},
+		},
+		{
+			FullMethod: "/foo.Foo/*",
+		},
+	}
+	assert.False(t, BreakerEnabled(methods))
+	assert.Equal(t, []MethodRetryConf{
+		{
+			FullMethod:  "/foo.Foo/Bar",
+			MaxAttempts: 3,
+			Codes:       []string{"ABORTED"},
+		},
+	}, RetryMethods(methods))
+
+	methods[1].Breaker = breakerOn
+	assert.True(t, BreakerEnabled(methods))
+}
+
+func TestBreakerInterceptor_disabled(t *testing.T) {
+	var called bool
+	err := BreakerInterceptor(context.Background(), "/foo", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			called = true
+			return nil
+		}, breakerCallOption{})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
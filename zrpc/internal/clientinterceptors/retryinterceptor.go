@@ -0,0 +1,476 @@
+package clientinterceptors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/collection"
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/mathx"
+	"github.com/r27153733/fastgozero/core/timex"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/attempt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	retryBudgetWindow    = time.Second * 10
+	retryBudgetBuckets   = 40
+	hedgeSamples         = 512
+	minHedgeSamples      = 100
+	hedgeRefreshInterval = time.Second
+)
+
+type (
+	// RetryConf defines the retry policy of the client calls.
+	RetryConf struct {
+		// MaxAttempts is the max attempts of a call, including the first one.
+		// Less than 2 means no retry.
+		MaxAttempts       int           `json:",optional"`
+		InitialBackoff    time.Duration `json:",default=50ms"`
+		MaxBackoff        time.Duration `json:",default=1s"`
+		BackoffMultiplier float64       `json:",default=2"`
+		// Jitter is the deviation of the backoff, like 0.2 means ±20%.
+		Jitter float64 `json:",default=0.2,range=[0:1]"`
+		// Codes are the retryable status codes, like UNAVAILABLE, RESOURCE_EXHAUSTED.
+		Codes []string `json:",default=[UNAVAILABLE]"`
+		// BudgetRatio is the max ratio of retries to requests in the last 10 seconds,
+		// to avoid retries amplifying an outage.
+		BudgetRatio float64 `json:",default=0.1"`
+		// BudgetMinRetries is the number of retries allowed in the last 10 seconds
+		// regardless of BudgetRatio.
+		BudgetMinRetries int64 `json:",default=10"`
+		// HedgePercentile enables hedged requests if greater than 0, like 0.95 means
+		// another attempt is sent if no response after the p95 latency of the method.
+		HedgePercentile float64 `json:",optional,range=[0:1)"`
+		// Methods are the retry policies of specified methods.
+		Methods []MethodRetryConf `json:",optional"`
+	}
+
+	// MethodRetryConf defines the retry policy of specified gRPC method.
+	MethodRetryConf struct {
+		// FullMethod is the full method name, the trailing * matches any suffix.
+		FullMethod  string
+		MaxAttempts int
+		Codes       []string `json:",optional"`
+		// InitialBackoff, MaxBackoff and BackoffMultiplier override the ones of RetryConf if set.
+		InitialBackoff    time.Duration `json:",optional"`
+		MaxBackoff        time.Duration `json:",optional"`
+		BackoffMultiplier float64       `json:",optional"`
+	}
+
+	retryPolicy struct {
+		maxAttempts       int
+		codes             []codes.Code
+		initialBackoff    time.Duration
+		maxBackoff        time.Duration
+		backoffMultiplier float64
+	}
+
+	wildcardRetryPolicy struct {
+		prefix string
+		policy retryPolicy
+	}
+
+	retrier struct {
+		conf      RetryConf
+		policy    retryPolicy
+		methods   map[string]retryPolicy
+		wildcards []wildcardRetryPolicy
+		budget    *retryBudget
+		unstable  mathx.Unstable
+		latencies sync.Map
+	}
+)
+
+// RetryInterceptor returns an interceptor that retries the failed calls with the given policy.
+// The retried and hedged attempts are preferred to be sent to the sub-conns that haven't been
+// tried by the same call.
+// An error is returned if c is invalid, like the unknown codes.
+func RetryInterceptor(c RetryConf) (grpc.UnaryClientInterceptor, error) {
+	r, err := newRetrier(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.intercept, nil
+}
+
+// Enabled returns true if retry is enabled on any method.
+func (c RetryConf) Enabled() bool {
+	if c.MaxAttempts > 1 {
+		return true
+	}
+
+	for _, m := range c.Methods {
+		if m.MaxAttempts > 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate validates the retry config.
+func (c RetryConf) Validate() error {
+	if _, err := parseCodes(c.Codes); err != nil {
+		return err
+	}
+
+	for _, m := range c.Methods {
+		if _, err := parseCodes(m.Codes); err != nil {
+			return fmt.Errorf("%s: %w", m.FullMethod, err)
+		}
+	}
+
+	return nil
+}
+
+func newRetrier(c RetryConf) (*retrier, error) {
+	retryCodes, err := parseCodes(c.Codes)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultPolicy := retryPolicy{
+		maxAttempts:       c.MaxAttempts,
+		codes:             retryCodes,
+		initialBackoff:    c.InitialBackoff,
+		maxBackoff:        c.MaxBackoff,
+		backoffMultiplier: c.BackoffMultiplier,
+	}
+	methods := make(map[string]retryPolicy, len(c.Methods))
+	var wildcards []wildcardRetryPolicy
+	for _, m := range c.Methods {
+		policy := defaultPolicy
+		policy.maxAttempts = m.MaxAttempts
+		if len(m.Codes) > 0 {
+			if policy.codes, err = parseCodes(m.Codes); err != nil {
+				return nil, fmt.Errorf("%s: %w", m.FullMethod, err)
+			}
+		}
+		if m.InitialBackoff > 0 {
+			policy.initialBackoff = m.InitialBackoff
+		}
+		if m.MaxBackoff > 0 {
+			policy.maxBackoff = m.MaxBackoff
+		}
+		if m.BackoffMultiplier > 0 {
+			policy.backoffMultiplier = m.BackoffMultiplier
+		}
+
+		if prefix, ok := wildcardPrefix(m.FullMethod); ok {
+			wildcards = append(wildcards, wildcardRetryPolicy{
+				prefix: prefix,
+				policy: policy,
+			})
+		} else if _, ok := methods[m.FullMethod]; !ok {
+			methods[m.FullMethod] = policy
+		}
+	}
+
+	return &retrier{
+		conf:      c,
+		policy:    defaultPolicy,
+		methods:   methods,
+		wildcards: wildcards,
+		budget:    newRetryBudget(c.BudgetRatio, c.BudgetMinRetries),
+		unstable:  mathx.NewUnstable(c.Jitter),
+	}, nil
+}
+
+func (r *retrier) backoff(policy retryPolicy, attempts int) time.Duration {
+	backoff := float64(policy.initialBackoff) * math.Pow(policy.backoffMultiplier, float64(attempts-1))
+	if policy.maxBackoff > 0 && backoff > float64(policy.maxBackoff) {
+		backoff = float64(policy.maxBackoff)
+	}
+
+	return r.unstable.AroundDuration(time.Duration(backoff))
+}
+
+// getPolicy returns the policy of the method, the exact matched one is preferred,
+// then the first matched wildcard one.
+func (r *retrier) getPolicy(method string) retryPolicy {
+	if policy, ok := r.methods[method]; ok {
+		return policy
+	}
+
+	for _, w := range r.wildcards {
+		if strings.HasPrefix(method, w.prefix) {
+			return w.policy
+		}
+	}
+
+	return r.policy
+}
+
+func (r *retrier) hedge(ctx context.Context, method string, req any, reply proto.Message,
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, policy retryPolicy, delay time.Duration,
+	opts ...grpc.CallOption) error {
+	type result struct {
+		reply proto.Message
+		err   error
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result, policy.maxAttempts)
+	var sent, received int
+	send := func() {
+		sent++
+		rp := reply.ProtoReflect().New().Interface()
+		go func() {
+			err := invoker(ctx, method, req, rp, cc, opts...)
+			results <- result{
+				reply: rp,
+				err:   err,
+			}
+		}()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	start := timex.Now()
+	r.budget.addRequest()
+	send()
+
+	var err error
+	for received < sent {
+		select {
+		case <-timer.C:
+			if sent < policy.maxAttempts && r.budget.allow() {
+				r.budget.addRetry()
+				send()
+				timer.Reset(delay)
+			}
+		case res := <-results:
+			received++
+			if res.err == nil {
+				r.addLatency(method, timex.Since(start))
+				proto.Merge(reply, res.reply)
+				return nil
+			}
+
+			err = res.err
+			if !policy.retryable(err) {
+				return err
+			}
+			// send the next hedged attempt immediately if the failure is retryable
+			if sent < policy.maxAttempts && r.budget.allow() {
+				r.budget.addRetry()
+				send()
+			}
+		}
+	}
+
+	return err
+}
+
+func (r *retrier) hedgeDelay(method string) time.Duration {
+	if r.conf.HedgePercentile <= 0 {
+		return 0
+	}
+
+	val, ok := r.latencies.Load(method)
+	if !ok {
+		return 0
+	}
+
+	return val.(*latencyStat).percentile(r.conf.HedgePercentile)
+}
+
+func (r *retrier) addLatency(method string, latency time.Duration) {
+	if r.conf.HedgePercentile <= 0 {
+		return
+	}
+
+	val, ok := r.latencies.Load(method)
+	if !ok {
+		val, _ = r.latencies.LoadOrStore(method, newLatencyStat())
+	}
+	val.(*latencyStat).add(latency)
+}
+
+func (r *retrier) intercept(ctx context.Context, method string, req, reply any,
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	policy := r.getPolicy(method)
+	if policy.maxAttempts < 2 {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	ctx, _ = attempt.NewContext(ctx)
+	if msg, ok := reply.(proto.Message); ok {
+		if delay := r.hedgeDelay(method); delay > 0 {
+			return r.hedge(ctx, method, req, msg, cc, invoker, policy, delay, opts...)
+		}
+	}
+
+	return r.retry(ctx, method, req, reply, cc, invoker, policy, opts...)
+}
+
+func (r *retrier) retry(ctx context.Context, method string, req, reply any,
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, policy retryPolicy, opts ...grpc.CallOption) error {
+	var err error
+	for i := 0; i < policy.maxAttempts; i++ {
+		if i == 0 {
+			r.budget.addRequest()
+		} else {
+			if !r.budget.allow() {
+				logx.WithContext(ctx).Errorf("retry budget exhausted, method: %s, error: %v", method, err)
+				return err
+			}
+
+			timer := time.NewTimer(r.backoff(policy, i))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+
+			r.budget.addRetry()
+		}
+
+		start := timex.Now()
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil {
+			r.addLatency(method, timex.Since(start))
+			return nil
+		}
+		if !policy.retryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (p retryPolicy) retryable(err error) bool {
+	code := status.Code(err)
+	for _, c := range p.codes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+type retryBudget struct {
+	ratio      float64
+	minRetries int64
+	window     *collection.RollingWindow[int64, *collection.Bucket[int64]]
+}
+
+func newRetryBudget(ratio float64, minRetries int64) *retryBudget {
+	newBucket := func() *collection.Bucket[int64] {
+		return new(collection.Bucket[int64])
+	}
+
+	return &retryBudget{
+		ratio:      ratio,
+		minRetries: minRetries,
+		window: collection.NewRollingWindow[int64, *collection.Bucket[int64]](newBucket,
+			retryBudgetBuckets, retryBudgetWindow/retryBudgetBuckets),
+	}
+}
+
+func (b *retryBudget) addRequest() {
+	b.window.Add(0)
+}
+
+func (b *retryBudget) addRetry() {
+	b.window.Add(1)
+}
+
+// allow checks if a retry is allowed, the Sum of buckets is the number of retries,
+// and the Count of buckets is the number of requests plus retries.
+func (b *retryBudget) allow() bool {
+	var total, retries int64
+	b.window.Reduce(func(b *collection.Bucket[int64]) {
+		total += b.Count
+		retries += b.Sum
+	})
+
+	return retries < b.minRetries || float64(retries) < float64(total-retries)*b.ratio
+}
+
+type latencyStat struct {
+	lock      sync.Mutex
+	samples   []time.Duration
+	index     int
+	full      bool
+	value     time.Duration
+	refreshed time.Duration
+}
+
+func newLatencyStat() *latencyStat {
+	return &latencyStat{
+		samples: make([]time.Duration, hedgeSamples),
+	}
+}
+
+func (s *latencyStat) add(latency time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.samples[s.index] = latency
+	s.index++
+	if s.index >= len(s.samples) {
+		s.index = 0
+		s.full = true
+	}
+}
+
+// percentile returns the latency of percentile p, recalculated at most once per second,
+// returns 0 if the samples are not enough.
+func (s *latencyStat) percentile(p float64) time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := timex.Now()
+	if s.value > 0 && now-s.refreshed < hedgeRefreshInterval {
+		return s.value
+	}
+
+	n := s.index
+	if s.full {
+		n = len(s.samples)
+	}
+	if n < minHedgeSamples {
+		return 0
+	}
+
+	samples := make([]time.Duration, n)
+	copy(samples, s.samples[:n])
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+	s.value = samples[int(float64(n-1)*p)]
+	s.refreshed = now
+
+	return s.value
+}
+
+func parseCodes(names []string) ([]codes.Code, error) {
+	retryCodes := make([]codes.Code, 0, len(names))
+	for _, name := range names {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(name)))); err != nil {
+			return nil, err
+		}
+		retryCodes = append(retryCodes, code)
+	}
+
+	return retryCodes, nil
+}
@@ -0,0 +1,269 @@
+package clientinterceptors
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/conf"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/attempt"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func newTestRetryConf(t *testing.T) RetryConf {
+	var c RetryConf
+	assert.NoError(t, conf.FillDefault(&c))
+	c.MaxAttempts = 3
+	c.InitialBackoff = time.Millisecond
+	c.MaxBackoff = time.Millisecond * 5
+	return c
+}
+
+func TestRetryConf(t *testing.T) {
+	c := newTestRetryConf(t)
+	assert.Equal(t, []string{"UNAVAILABLE"}, c.Codes)
+	assert.True(t, c.Enabled())
+	assert.NoError(t, c.Validate())
+
+	c.MaxAttempts = 1
+	assert.False(t, c.Enabled())
+	c.Methods = []MethodRetryConf{
+		{
+			FullMethod:  "/foo",
+			MaxAttempts: 2,
+			Codes:       []string{"bad"},
+		},
+	}
+	assert.True(t, c.Enabled())
+	assert.Error(t, c.Validate())
+
+	c.Methods = nil
+	c.Codes = []string{"unknown_code"}
+	assert.Error(t, c.Validate())
+}
+
+func TestRetryInterceptor_invalid(t *testing.T) {
+	c := newTestRetryConf(t)
+	c.Codes = []string{"unknown_code"}
+	_, err := RetryInterceptor(c)
+	assert.Error(t, err)
+
+	c = newTestRetryConf(t)
+	c.Methods = []MethodRetryConf{
+		{
+			FullMethod:  "/foo",
+			MaxAttempts: 2,
+			Codes:       []string{"unknown_code"},
+		},
+	}
+	_, err = RetryInterceptor(c)
+	assert.Error(t, err)
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	c := newTestRetryConf(t)
+	interceptor, err := RetryInterceptor(c)
+	assert.NoError(t, err)
+	cc := new(grpc.ClientConn)
+
+	var calls int32
+	err = interceptor(context.Background(), "/foo", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			assert.NotNil(t, attempt.FromContext(ctx))
+			if atomic.AddInt32(&calls, 1) < 3 {
+				return status.Error(codes.Unavailable, "unavailable")
+			}
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	err = interceptor(context.Background(), "/foo", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.Unavailable, "unavailable")
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	err = interceptor(context.Background(), "/foo", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.InvalidArgument, "invalid")
+		})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryInterceptor_method(t *testing.T) {
+	c := newTestRetryConf(t)
+	c.Methods = []MethodRetryConf{
+		{
+			FullMethod:  "/foo",
+			MaxAttempts: 1,
+		},
+		{
+			FullMethod:  "/bar",
+			MaxAttempts: 2,
+			Codes:       []string{"RESOURCE_EXHAUSTED"},
+		},
+	}
+	interceptor, err := RetryInterceptor(c)
+	assert.NoError(t, err)
+	cc := new(grpc.ClientConn)
+
+	var calls int32
+	err = interceptor(context.Background(), "/foo", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			assert.Nil(t, attempt.FromContext(ctx))
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.Unavailable, "unavailable")
+		})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	err = interceptor(context.Background(), "/bar", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.ResourceExhausted, "exhausted")
+		})
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestRetryInterceptor_canceled(t *testing.T) {
+	c := newTestRetryConf(t)
+	c.InitialBackoff = time.Hour
+	c.MaxBackoff = time.Hour
+	interceptor, err := RetryInterceptor(c)
+	assert.NoError(t, err)
+	cc := new(grpc.ClientConn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	var calls int32
+	err = interceptor(ctx, "/foo", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.Unavailable, "unavailable")
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryInterceptor_hedge(t *testing.T) {
+	c := newTestRetryConf(t)
+	c.HedgePercentile = 0.9
+	r, err := newRetrier(c)
+	assert.NoError(t, err)
+	for i := 0; i < minHedgeSamples; i++ {
+		r.addLatency("/foo", time.Millisecond)
+	}
+	assert.Equal(t, time.Millisecond, r.hedgeDelay("/foo"))
+	assert.Equal(t, time.Duration(0), r.hedgeDelay("/bar"))
+
+	cc := new(grpc.ClientConn)
+	var calls int32
+	reply := new(wrapperspb.StringValue)
+	err = r.intercept(context.Background(), "/foo", nil, reply, cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				// the first attempt is slow, the hedged one wins
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Second):
+				}
+			}
+
+			reply.(*wrapperspb.StringValue).Value = "hedged"
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "hedged", reply.Value)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	err = r.intercept(context.Background(), "/foo", nil, new(wrapperspb.StringValue), cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.Unavailable, "unavailable")
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryBudget(t *testing.T) {
+	budget := newRetryBudget(0.5, 1)
+	assert.True(t, budget.allow())
+	budget.addRequest()
+	budget.addRetry()
+	assert.False(t, budget.allow())
+	budget.addRequest()
+	budget.addRequest()
+	budget.addRequest()
+	assert.True(t, budget.allow())
+}
+
+func TestRetrier_backoff(t *testing.T) {
+	c := newTestRetryConf(t)
+	c.Jitter = 0
+	c.InitialBackoff = time.Millisecond
+	c.MaxBackoff = time.Millisecond * 3
+	r, err := newRetrier(c)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Millisecond, r.backoff(r.policy, 1))
+	assert.Equal(t, time.Millisecond*2, r.backoff(r.policy, 2))
+	assert.Equal(t, time.Millisecond*3, r.backoff(r.policy, 3))
+}
+
+func TestRetrier_methodPolicy(t *testing.T) {
+	c := newTestRetryConf(t)
+	c.Jitter = 0
+	c.Methods = []MethodRetryConf{
+		{
+			FullMethod:     "/foo.Foo/Bar",
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond * 10,
+		},
+		{
+			FullMethod:  "/foo.Foo/*",
+			MaxAttempts: 4,
+			Codes:       []string{"ABORTED"},
+		},
+		{
+			FullMethod:  "/foo.Foo/Bar",
+			MaxAttempts: 5,
+		},
+	}
+	r, err := newRetrier(c)
+	assert.NoError(t, err)
+
+	policy := r.getPolicy("/foo.Foo/Bar")
+	assert.Equal(t, 2, policy.maxAttempts)
+	assert.Equal(t, time.Millisecond*5, r.backoff(policy, 1))
+	assert.Equal(t, time.Millisecond, r.backoff(r.policy, 1))
+
+	policy = r.getPolicy("/foo.Foo/Baz")
+	assert.Equal(t, 4, policy.maxAttempts)
+	assert.True(t, policy.retryable(status.Error(codes.Aborted, "")))
+	assert.False(t, policy.retryable(status.Error(codes.Unavailable, "")))
+
+	assert.Equal(t, 3, r.getPolicy("/bar.Bar/Foo").maxAttempts)
+}
@@ -0,0 +1,37 @@
+package clientinterceptors
+
+import (
+	"context"
+
+	"github.com/r27153733/fastgozero/core/routetag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// StreamRouteTagInterceptor is an interceptor that propagates the route tags in context
+// to the server through the outgoing metadata.
+func StreamRouteTagInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withOutgoingRouteTags(ctx), desc, cc, method, opts...)
+}
+
+// UnaryRouteTagInterceptor is an interceptor that propagates the route tags in context
+// to the server through the outgoing metadata.
+func UnaryRouteTagInterceptor(ctx context.Context, method string, req, reply any,
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withOutgoingRouteTags(ctx), method, req, reply, cc, opts...)
+}
+
+func withOutgoingRouteTags(ctx context.Context) context.Context {
+	tags := routetag.FromContext(ctx)
+	if len(tags) == 0 {
+		return ctx
+	}
+
+	// the tags set explicitly by the caller take precedence
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(routetag.MetadataKey)) > 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, routetag.MetadataKey, tags)
+}
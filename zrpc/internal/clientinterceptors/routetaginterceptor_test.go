@@ -0,0 +1,55 @@
+package clientinterceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/r27153733/fastgozero/core/routetag"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryRouteTagInterceptor(t *testing.T) {
+	cc := new(grpc.ClientConn)
+	err := UnaryRouteTagInterceptor(context.Background(), "/foo", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			_, ok := metadata.FromOutgoingContext(ctx)
+			assert.False(t, ok)
+			return nil
+		})
+	assert.NoError(t, err)
+
+	ctx := routetag.NewContext(context.Background(), "version=v2")
+	err = UnaryRouteTagInterceptor(ctx, "/foo", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			assert.Equal(t, []string{"version=v2"}, md.Get(routetag.MetadataKey))
+			return nil
+		})
+	assert.NoError(t, err)
+
+	ctx = metadata.AppendToOutgoingContext(ctx, routetag.MetadataKey, "version=v3")
+	err = UnaryRouteTagInterceptor(ctx, "/foo", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			assert.Equal(t, []string{"version=v3"}, md.Get(routetag.MetadataKey))
+			return nil
+		})
+	assert.NoError(t, err)
+}
+
+func TestStreamRouteTagInterceptor(t *testing.T) {
+	ctx := routetag.NewContext(context.Background(), "version=v2")
+	_, err := StreamRouteTagInterceptor(ctx, nil, new(grpc.ClientConn), "/foo",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+			opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			assert.Equal(t, []string{"version=v2"}, md.Get(routetag.MetadataKey))
+			return nil, nil
+		})
+	assert.NoError(t, err)
+}
@@ -36,9 +36,11 @@ func WithCallTimeout(timeout time.Duration) grpc.CallOption {
 	}
 }
 
+// getTimeoutFromCallOptions returns the timeout of the last TimeoutCallOption,
+// to override like the other call options.
 func getTimeoutFromCallOptions(opts []grpc.CallOption, defaultTimeout time.Duration) time.Duration {
-	for _, opt := range opts {
-		if o, ok := opt.(TimeoutCallOption); ok {
+	for i := len(opts) - 1; i >= 0; i-- {
+		if o, ok := opts[i].(TimeoutCallOption); ok {
 			return o.timeout
 		}
 	}
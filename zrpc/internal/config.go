@@ -1,6 +1,14 @@
 package internal
 
-import "github.com/r27153733/fastgozero/zrpc/internal/serverinterceptors"
+import (
+	"time"
+
+	"github.com/r27153733/fastgozero/core/service"
+	"github.com/r27153733/fastgozero/zrpc/internal/auth"
+	"github.com/r27153733/fastgozero/zrpc/internal/clientinterceptors"
+	"github.com/r27153733/fastgozero/zrpc/internal/serverinterceptors"
+	"github.com/r27153733/fastgozero/zrpc/internal/tlsx"
+)
 
 type (
 	// StatConf defines the stat config.
@@ -13,9 +21,12 @@ type (
 		Prometheus bool `json:",default=true"`
 		Breaker    bool `json:",default=true"`
 		Timeout    bool `json:",default=true"`
+		// RouteTag propagates the route tags in the context to the servers.
+		RouteTag bool `json:",default=false"`
 	}
 
 	// ServerMiddlewaresConf defines whether to use server middlewares.
+	// Stat and Prometheus apply to both unary requests and streams.
 	ServerMiddlewaresConf struct {
 		Trace      bool     `json:",default=true"`
 		Recover    bool     `json:",default=true"`
@@ -23,8 +34,110 @@ type (
 		StatConf   StatConf `json:",optional"`
 		Prometheus bool     `json:",default=true"`
 		Breaker    bool     `json:",default=true"`
+		// RouteTag trusts the route tags in the metadata, only enable it if the callers are trusted.
+		RouteTag bool `json:",default=false"`
+		// StreamShedding sheds the streams on opening if CpuThreshold is set.
+		StreamShedding bool `json:",default=true"`
+		// MaxStreamLifetime cancels the context of the streams after it, 0 means no limit.
+		MaxStreamLifetime time.Duration `json:",optional"`
+	}
+
+	// MetadataConf defines the metadata of the server published in service discovery.
+	MetadataConf struct {
+		// Weight is used by the weighted balancers, like wrr.
+		Weight int `json:",optional"`
+		// Zone is used by the zone-aware balancing.
+		Zone    string            `json:",optional"`
+		Version string            `json:",optional"`
+		Tags    map[string]string `json:",optional"`
+	}
+
+	// AuthPolicyConf defines the authentication of the methods.
+	AuthPolicyConf struct {
+		// Jwt enables the jwt authenticator if Secret is set.
+		Jwt JwtAuthConf `json:",optional"`
+		// ApiKeys enables the apikey authenticator with the keys in x-api-key metadata.
+		ApiKeys []string `json:",optional"`
+		// Default are the authenticators of the methods not matching any rules,
+		// any of them passes the requests, empty means no authentication required.
+		Default []string `json:",optional"`
+		// Rules are checked in order, the first matched one is applied.
+		Rules []AuthRuleConf `json:",optional"`
+	}
+
+	// IntrospectionConf defines the reflection, channelz and admin services of the server.
+	IntrospectionConf struct {
+		// Reflection registers the reflection services, auto means enabled in dev and test mode.
+		Reflection string `json:",default=auto,options=auto|on|off"`
+		// Channelz registers the channelz services.
+		Channelz bool `json:",optional"`
+		// Admin registers the grpc admin services, including channelz, and csds if xds is used.
+		Admin bool `json:",optional"`
+		// OnDevServer serves the services on the devserver port instead of the public one,
+		// the devserver is shared in the process, only the first server is served on it.
+		OnDevServer bool `json:",optional"`
+	}
+
+	// OutlierDetectionConf defines the outlier detection of the client, which ejects
+	// the failing endpoints temporarily, enabled if ConsecutiveErrors or ErrorRate is set.
+	OutlierDetectionConf struct {
+		// ConsecutiveErrors ejects the endpoint on the consecutive errors, 0 means disabled.
+		ConsecutiveErrors int `json:",optional"`
+		// ErrorRate ejects the endpoint if the error percentage in Interval reaches it,
+		// with at least MinRequests requests, 0 means disabled.
+		ErrorRate   int           `json:",optional,range=[0:100]"`
+		MinRequests int           `json:",default=20"`
+		Interval    time.Duration `json:",default=10s"`
+		// BaseEjectionTime is the first ejection time of an endpoint, which is doubled
+		// on each ejection in a row, up to MaxEjectionTime.
+		BaseEjectionTime time.Duration `json:",default=30s"`
+		MaxEjectionTime  time.Duration `json:",default=5m"`
+		// MaxEjectionPercent is the max percentage of the endpoints to eject.
+		MaxEjectionPercent int `json:",default=50,range=[0:100]"`
+	}
+
+	// AuthRuleConf defines the authentication of the matched methods.
+	AuthRuleConf = auth.Rule
+
+	// JwtAuthConf defines the jwt authenticator, the same as the rest jwt auth.
+	JwtAuthConf struct {
+		Secret     string `json:",optional"`
+		PrevSecret string `json:",optional"`
 	}
 
 	// MethodTimeoutConf defines specified timeout for gRPC methods.
 	MethodTimeoutConf = serverinterceptors.MethodTimeoutConf
+
+	// MethodConf defines the call options of the specified gRPC methods on client side.
+	MethodConf = clientinterceptors.MethodConf
+
+	// RetryConf defines the retry policy of client calls.
+	RetryConf = clientinterceptors.RetryConf
+	// MethodRetryConf defines the retry policy of specified gRPC method.
+	MethodRetryConf = clientinterceptors.MethodRetryConf
+
+	// TlsConf defines the TLS config of the rpc servers and clients.
+	TlsConf = tlsx.Conf
 )
+
+// Enabled returns true if the authentication policy is configured.
+func (c AuthPolicyConf) Enabled() bool {
+	return len(c.Default) > 0 || len(c.Rules) > 0
+}
+
+// Enabled returns true if any introspection services are registered in the given mode.
+func (c IntrospectionConf) Enabled(mode string) bool {
+	return c.ReflectionEnabled(mode) || c.Channelz || c.Admin
+}
+
+// ReflectionEnabled returns true if the reflection services are registered in the given mode.
+func (c IntrospectionConf) ReflectionEnabled(mode string) bool {
+	switch c.Reflection {
+	case reflectionOn:
+		return true
+	case reflectionOff:
+		return false
+	default:
+		return mode == service.DevMode || mode == service.TestMode
+	}
+}
@@ -0,0 +1,91 @@
+// Package zstd registers the zstd compressor to gRPC, which is used by setting the
+// Compressor of the clients or methods to zstd.
+package zstd
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+// Name is the name of the zstd compressor.
+const Name = "zstd"
+
+type (
+	compressor struct {
+		encoders sync.Pool
+		decoders sync.Pool
+	}
+
+	writer struct {
+		*zstd.Encoder
+		pool *sync.Pool
+	}
+
+	reader struct {
+		*zstd.Decoder
+		pool *sync.Pool
+	}
+)
+
+func init() {
+	encoding.RegisterCompressor(newCompressor())
+}
+
+func newCompressor() *compressor {
+	c := &compressor{}
+	c.encoders.New = func() any {
+		// never fails without options conflicts
+		enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return &writer{
+			Encoder: enc,
+			pool:    &c.encoders,
+		}
+	}
+	c.decoders.New = func() any {
+		// never fails without options conflicts, concurrency 1 decodes synchronously
+		dec, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		return &reader{
+			Decoder: dec,
+			pool:    &c.decoders,
+		}
+	}
+
+	return c
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	z := c.encoders.Get().(*writer)
+	z.Encoder.Reset(w)
+	return z, nil
+}
+
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	z := c.decoders.Get().(*reader)
+	if err := z.Decoder.Reset(r); err != nil {
+		c.decoders.Put(z)
+		return nil, err
+	}
+
+	return z, nil
+}
+
+func (c *compressor) Name() string {
+	return Name
+}
+
+func (z *writer) Close() error {
+	defer z.pool.Put(z)
+	return z.Encoder.Close()
+}
+
+func (z *reader) Read(p []byte) (int, error) {
+	n, err := z.Decoder.Read(p)
+	if err == io.EOF {
+		z.pool.Put(z)
+	}
+
+	return n, err
+}
@@ -0,0 +1,43 @@
+package zstd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestCompressor(t *testing.T) {
+	c := encoding.GetCompressor(Name)
+	assert.NotNil(t, c)
+	assert.Equal(t, Name, c.Name())
+
+	content := strings.Repeat("zrpc zstd compressor ", 1024)
+	for i := 0; i < 3; i++ {
+		var buf bytes.Buffer
+		w, err := c.Compress(&buf)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+		assert.Less(t, buf.Len(), len(content))
+
+		r, err := c.Decompress(&buf)
+		assert.NoError(t, err)
+		val, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(val))
+	}
+}
+
+func TestCompressor_badInput(t *testing.T) {
+	c := encoding.GetCompressor(Name)
+	r, err := c.Decompress(strings.NewReader("not zstd"))
+	if err == nil {
+		_, err = io.ReadAll(r)
+	}
+	assert.Error(t, err)
+}
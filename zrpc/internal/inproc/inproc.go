@@ -0,0 +1,135 @@
+package inproc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	// Scheme is the scheme of the in-process targets, like inproc://name.
+	Scheme = "inproc"
+
+	schemePrefix = Scheme + "://"
+	bufferSize   = 1 << 20
+)
+
+var (
+	// ErrAddrInUse is an error that indicates the name is served by another server.
+	ErrAddrInUse = errors.New("inproc: name is already in use")
+
+	listeners = make(map[string]*listener)
+	lock      sync.Mutex
+)
+
+type (
+	listener struct {
+		*bufconn.Listener
+		name    string
+		serving bool
+	}
+
+	resolverBuilder struct{}
+
+	nopResolver struct{}
+)
+
+// Dial dials the in-process server with the given name, it waits until the server
+// starts serving or ctx is done, which keeps the starting order of the servers and
+// clients irrelevant.
+func Dial(ctx context.Context, name string) (net.Conn, error) {
+	return getListener(name).DialContext(ctx)
+}
+
+// Listen returns an in-memory listener with the given name.
+func Listen(name string) (net.Listener, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	l := getListenerLocked(name)
+	if l.serving {
+		return nil, ErrAddrInUse
+	}
+
+	l.serving = true
+	return l, nil
+}
+
+// NewResolverBuilder returns a resolver.Builder that resolves the in-process targets.
+func NewResolverBuilder() resolver.Builder {
+	return resolverBuilder{}
+}
+
+// ParseTarget returns the name of the in-process target, and whether target is in-process.
+func ParseTarget(target string) (string, bool) {
+	if !strings.HasPrefix(target, schemePrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(target, schemePrefix), true
+}
+
+// Target returns the in-process target with the given name.
+func Target(name string) string {
+	return schemePrefix + name
+}
+
+func (l *listener) Close() error {
+	lock.Lock()
+	if listeners[l.name] == l {
+		delete(listeners, l.name)
+	}
+	lock.Unlock()
+
+	return l.Listener.Close()
+}
+
+func (b resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn,
+	_ resolver.BuildOptions) (resolver.Resolver, error) {
+	if err := cc.UpdateState(resolver.State{
+		Addresses: []resolver.Address{
+			{
+				Addr: target.URL.Host,
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	return nopResolver{}, nil
+}
+
+func (b resolverBuilder) Scheme() string {
+	return Scheme
+}
+
+func (r nopResolver) Close() {
+}
+
+func (r nopResolver) ResolveNow(_ resolver.ResolveNowOptions) {
+}
+
+func getListener(name string) *listener {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return getListenerLocked(name)
+}
+
+func getListenerLocked(name string) *listener {
+	l, ok := listeners[name]
+	if !ok {
+		l = &listener{
+			Listener: bufconn.Listen(bufferSize),
+			name:     name,
+		}
+		listeners[name] = l
+	}
+
+	return l
+}
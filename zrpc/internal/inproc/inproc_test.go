@@ -0,0 +1,52 @@
+package inproc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTarget(t *testing.T) {
+	name, ok := ParseTarget(Target("foo"))
+	assert.True(t, ok)
+	assert.Equal(t, "foo", name)
+
+	_, ok = ParseTarget("direct:///localhost:8080")
+	assert.False(t, ok)
+}
+
+func TestListenAndDial(t *testing.T) {
+	const name = "listen"
+
+	// dialing before listening waits for the server.
+	conns := make(chan error, 1)
+	go func() {
+		conn, err := Dial(context.Background(), name)
+		if err == nil {
+			conn.Close()
+		}
+		conns <- err
+	}()
+
+	lis, err := Listen(name)
+	assert.NoError(t, err)
+	_, err = Listen(name)
+	assert.Equal(t, ErrAddrInUse, err)
+
+	conn, err := lis.Accept()
+	assert.NoError(t, err)
+	conn.Close()
+	assert.NoError(t, <-conns)
+
+	assert.NoError(t, lis.Close())
+	lis, err = Listen(name)
+	assert.NoError(t, err)
+	assert.NoError(t, lis.Close())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = Dial(ctx, "none")
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"github.com/r27153733/fastgozero/core/proc"
+	"github.com/r27153733/fastgozero/internal/devserver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/admin"
+	channelzgrpc "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	channelz "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+const (
+	reflectionOn  = "on"
+	reflectionOff = "off"
+)
+
+// RegisterIntrospection registers the reflection, channelz and admin services of server
+// by c, the services are served by a standalone server on the devserver port if
+// c.OnDevServer is true, and the reflection services still describe the given server.
+func RegisterIntrospection(server *grpc.Server, c IntrospectionConf, mode string) error {
+	var registrar grpc.ServiceRegistrar = server
+	if c.OnDevServer {
+		inner := grpc.NewServer()
+		if !devserver.HandleGrpc(inner) {
+			// the devserver is shared, only the first server is introspected on it.
+			inner.Stop()
+			return nil
+		}
+		proc.AddShutdownListener(inner.Stop)
+		registrar = inner
+	}
+
+	if c.ReflectionEnabled(mode) && !registered(registrar, reflectionv1.ServerReflection_ServiceDesc) {
+		opts := reflection.ServerOptions{Services: server}
+		reflectionv1alpha.RegisterServerReflectionServer(registrar, reflection.NewServer(opts))
+		reflectionv1.RegisterServerReflectionServer(registrar, reflection.NewServerV1(opts))
+	}
+
+	if c.Admin {
+		cleanup, err := admin.Register(registrar)
+		if err != nil {
+			return err
+		}
+
+		proc.AddShutdownListener(cleanup)
+	} else if c.Channelz && !registered(registrar, channelzgrpc.Channelz_ServiceDesc) {
+		channelz.RegisterChannelzServiceToServer(registrar)
+	}
+
+	return nil
+}
+
+// registered checks if the service is registered in RegisterFn, like reflection.Register
+// in dev mode, which panics on registering twice.
+func registered(registrar grpc.ServiceRegistrar, desc grpc.ServiceDesc) bool {
+	server, ok := registrar.(*grpc.Server)
+	if !ok {
+		return false
+	}
+
+	_, ok = server.GetServiceInfo()[desc.ServiceName]
+	return ok
+}
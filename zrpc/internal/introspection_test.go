@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/r27153733/fastgozero/core/service"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+const (
+	reflectionV1Service = "grpc.reflection.v1.ServerReflection"
+	channelzService     = "grpc.channelz.v1.Channelz"
+)
+
+func TestIntrospectionConf(t *testing.T) {
+	var c IntrospectionConf
+	assert.False(t, c.Enabled(service.ProMode))
+	assert.True(t, c.Enabled(service.DevMode))
+	assert.True(t, c.ReflectionEnabled(service.TestMode))
+
+	c.Reflection = reflectionOff
+	assert.False(t, c.ReflectionEnabled(service.DevMode))
+	c.Channelz = true
+	assert.True(t, c.Enabled(service.DevMode))
+
+	c.Reflection = reflectionOn
+	assert.True(t, c.ReflectionEnabled(service.ProMode))
+}
+
+func TestRegisterIntrospection(t *testing.T) {
+	server := grpc.NewServer()
+	assert.NoError(t, RegisterIntrospection(server, IntrospectionConf{
+		Channelz: true,
+	}, service.DevMode))
+	infos := server.GetServiceInfo()
+	assert.Contains(t, infos, reflectionV1Service)
+	assert.Contains(t, infos, channelzService)
+
+	server = grpc.NewServer()
+	assert.NoError(t, RegisterIntrospection(server, IntrospectionConf{
+		Admin: true,
+	}, service.ProMode))
+	infos = server.GetServiceInfo()
+	assert.NotContains(t, infos, reflectionV1Service)
+	assert.Contains(t, infos, channelzService)
+
+	// served on the devserver, the public server is kept clean.
+	server = grpc.NewServer()
+	assert.NoError(t, RegisterIntrospection(server, IntrospectionConf{
+		Reflection:  reflectionOn,
+		OnDevServer: true,
+	}, service.ProMode))
+	assert.Empty(t, server.GetServiceInfo())
+
+	// the other servers are not served on the shared devserver.
+	server = grpc.NewServer()
+	assert.NoError(t, RegisterIntrospection(server, IntrospectionConf{
+		Reflection:  reflectionOn,
+		OnDevServer: true,
+	}, service.ProMode))
+	assert.Empty(t, server.GetServiceInfo())
+}
+
+func TestRegisterIntrospection_registered(t *testing.T) {
+	server := grpc.NewServer()
+	reflection.Register(server)
+	assert.NotPanics(t, func() {
+		assert.NoError(t, RegisterIntrospection(server, IntrospectionConf{}, service.DevMode))
+	})
+}
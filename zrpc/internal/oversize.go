@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/metric"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+const oversizeMessage = "larger than max"
+
+var (
+	metricClientMsgOversizeTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "rpc_client",
+		Subsystem: "messages",
+		Name:      "oversize_total",
+		Help:      "rpc client calls failed by oversize messages count.",
+		Labels:    []string{"method"},
+	})
+
+	metricServerMsgOversizeTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "rpc_server",
+		Subsystem: "messages",
+		Name:      "oversize_total",
+		Help:      "rpc server requests failed by oversize messages count.",
+		Labels:    []string{"method"},
+	})
+)
+
+type (
+	// oversizeHandler logs and counts the rpcs failed by the messages larger than
+	// the max message sizes, which can't be seen by the interceptors, because the
+	// requests are received before the server interceptors.
+	oversizeHandler struct {
+		side   string
+		metric metric.CounterVec
+	}
+
+	oversizeMethodKey struct{}
+)
+
+func newClientOversizeHandler() stats.Handler {
+	return oversizeHandler{
+		side:   "client",
+		metric: metricClientMsgOversizeTotal,
+	}
+}
+
+func newServerOversizeHandler() stats.Handler {
+	return oversizeHandler{
+		side:   "server",
+		metric: metricServerMsgOversizeTotal,
+	}
+}
+
+func (h oversizeHandler) HandleConn(_ context.Context, _ stats.ConnStats) {
+}
+
+func (h oversizeHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	end, ok := s.(*stats.End)
+	if !ok || !isOversize(end.Error) {
+		return
+	}
+
+	method, _ := ctx.Value(oversizeMethodKey{}).(string)
+	h.metric.Inc(method)
+	logx.WithContext(ctx).Errorf("[RPC] %s message oversize - %s - %s, increase the MaxRecvMsgSize "+
+		"or MaxSendMsgSize of the clients and servers if expected", h.side, method,
+		status.Convert(end.Error).Message())
+}
+
+func (h oversizeHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h oversizeHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, oversizeMethodKey{}, info.FullMethodName)
+}
+
+func isOversize(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	st := status.Convert(err)
+	return st.Code() == codes.ResourceExhausted && strings.Contains(st.Message(), oversizeMessage)
+}
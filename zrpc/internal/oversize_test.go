@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsOversize(t *testing.T) {
+	assert.False(t, isOversize(nil))
+	assert.False(t, isOversize(errors.New("any")))
+	assert.False(t, isOversize(status.Error(codes.ResourceExhausted, "service overloaded")))
+	assert.True(t, isOversize(status.Error(codes.ResourceExhausted,
+		"grpc: received message larger than max (10 vs. 1)")))
+	assert.True(t, isOversize(status.Error(codes.ResourceExhausted,
+		"trying to send message larger than max (10 vs. 1)")))
+}
+
+func TestOversizeHandler(t *testing.T) {
+	for _, h := range []stats.Handler{newClientOversizeHandler(), newServerOversizeHandler()} {
+		ctx := h.TagConn(context.Background(), nil)
+		h.HandleConn(ctx, nil)
+		ctx = h.TagRPC(ctx, &stats.RPCTagInfo{
+			FullMethodName: "/foo.Foo/Bar",
+		})
+		assert.Equal(t, "/foo.Foo/Bar", ctx.Value(oversizeMethodKey{}))
+		assert.NotPanics(t, func() {
+			h.HandleRPC(ctx, &stats.Begin{})
+			h.HandleRPC(ctx, &stats.End{})
+			h.HandleRPC(ctx, &stats.End{
+				Error: status.Error(codes.ResourceExhausted, "grpc: received message larger than max (10 vs. 1)"),
+			})
+		})
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"strings"
 
 	"github.com/r27153733/fastgozero/core/discov"
+	"github.com/r27153733/fastgozero/core/logx"
 	"github.com/r27153733/fastgozero/core/netx"
+	"github.com/r27153733/fastgozero/core/proc"
 )
 
 const (
@@ -14,39 +16,56 @@ const (
 )
 
 // NewRpcPubServer returns a Server.
-func NewRpcPubServer(etcd discov.EtcdConf, listenOn string,
+// The metadata md is published along with the address if not empty.
+func NewRpcPubServer(etcd discov.EtcdConf, listenOn string, md MetadataConf,
 	opts ...ServerOption) (Server, error) {
-	registerEtcd := func() error {
-		pubListenOn := figureOutListenOn(listenOn)
-		var pubOpts []discov.PubOption
-		if etcd.HasAccount() {
-			pubOpts = append(pubOpts, discov.WithPubEtcdAccount(etcd.User, etcd.Pass))
-		}
-		if etcd.HasTLS() {
-			pubOpts = append(pubOpts, discov.WithPubEtcdTLS(etcd.CertFile, etcd.CertKeyFile,
-				etcd.CACertFile, etcd.InsecureSkipVerify))
+	registry, err := discov.NewEtcdRegistry(etcd)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRpcRegistryServer(registry, etcd.Key, listenOn, md, opts...), nil
+}
+
+// NewRpcRegistryServer returns a Server that registers itself as an instance of service
+// in registry on start, and deregisters on shutdown.
+// The metadata md is registered along with the address if not empty.
+func NewRpcRegistryServer(registry discov.Registry, service, listenOn string, md MetadataConf,
+	opts ...ServerOption) Server {
+	register := func() error {
+		ep := discov.Endpoint{
+			Addr:    figureOutListenOn(listenOn),
+			Weight:  md.Weight,
+			Zone:    md.Zone,
+			Version: md.Version,
+			Tags:    md.Tags,
 		}
-		if etcd.HasID() {
-			pubOpts = append(pubOpts, discov.WithId(etcd.ID))
+		if err := registry.Register(service, ep); err != nil {
+			return err
 		}
-		pubClient := discov.NewPublisher(etcd.Hosts, etcd.Key, pubListenOn, pubOpts...)
-		return pubClient.KeepAlive()
-	}
-	server := keepAliveServer{
-		registerEtcd: registerEtcd,
-		Server:       NewRpcServer(listenOn, opts...),
+
+		proc.AddWrapUpListener(func() {
+			if err := registry.Deregister(service, ep); err != nil {
+				logx.Errorf("deregister %s from %s, error: %v", ep.Addr, service, err)
+			}
+		})
+
+		return nil
 	}
 
-	return server, nil
+	return keepAliveServer{
+		register: register,
+		Server:   NewRpcServer(listenOn, opts...),
+	}
 }
 
 type keepAliveServer struct {
-	registerEtcd func() error
+	register func() error
 	Server
 }
 
 func (s keepAliveServer) Start(fn RegisterFn) error {
-	if err := s.registerEtcd(); err != nil {
+	if err := s.register(); err != nil {
 		return err
 	}
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/r27153733/fastgozero/core/proc"
 	"github.com/r27153733/fastgozero/internal/health"
+	"github.com/r27153733/fastgozero/zrpc/internal/inproc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -45,7 +46,7 @@ func (s *rpcServer) SetName(name string) {
 }
 
 func (s *rpcServer) Start(register RegisterFn) error {
-	lis, err := net.Listen("tcp", s.address)
+	lis, err := listen(s.address)
 	if err != nil {
 		return err
 	}
@@ -78,6 +79,14 @@ func (s *rpcServer) Start(register RegisterFn) error {
 	return server.Serve(lis)
 }
 
+func listen(address string) (net.Listener, error) {
+	if name, ok := inproc.ParseTarget(address); ok {
+		return inproc.Listen(name)
+	}
+
+	return net.Listen("tcp", address)
+}
+
 // WithRpcHealth returns a func that sets rpc health switch to a Server.
 func WithRpcHealth(health bool) ServerOption {
 	return func(options *rpcServerOptions) {
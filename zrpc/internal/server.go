@@ -40,9 +40,12 @@ func newBaseRpcServer(address string, rpcServerOpts *rpcServerOptions) *baseRpcS
 	return &baseRpcServer{
 		address: address,
 		health:  h,
-		options: []grpc.ServerOption{grpc.KeepaliveParams(keepalive.ServerParameters{
-			MaxConnectionIdle: defaultConnectionIdleDuration,
-		})},
+		options: []grpc.ServerOption{
+			grpc.KeepaliveParams(keepalive.ServerParameters{
+				MaxConnectionIdle: defaultConnectionIdleDuration,
+			}),
+			grpc.StatsHandler(newServerOversizeHandler()),
+		},
 	}
 }
 
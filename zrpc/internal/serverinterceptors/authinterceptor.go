@@ -8,22 +8,50 @@ import (
 )
 
 // StreamAuthorizeInterceptor returns a func that uses given authenticator in processing stream requests.
-func StreamAuthorizeInterceptor(authenticator *auth.Authenticator) grpc.StreamServerInterceptor {
+func StreamAuthorizeInterceptor(authenticator auth.Authenticator) grpc.StreamServerInterceptor {
 	return func(svr any, stream grpc.ServerStream, info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler) error {
-		if err := authenticator.Authenticate(stream.Context()); err != nil {
+		ctx, err := authenticator.Authenticate(stream.Context())
+		if err != nil {
 			return err
 		}
 
-		return handler(svr, stream)
+		return handler(svr, withStreamContext(stream, ctx))
+	}
+}
+
+// StreamAuthPolicyInterceptor returns a func that uses given policy in processing stream requests.
+func StreamAuthPolicyInterceptor(policy *auth.Policy) grpc.StreamServerInterceptor {
+	return func(svr any, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		ctx, err := policy.Authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(svr, withStreamContext(stream, ctx))
 	}
 }
 
 // UnaryAuthorizeInterceptor returns a func that uses given authenticator in processing unary requests.
-func UnaryAuthorizeInterceptor(authenticator *auth.Authenticator) grpc.UnaryServerInterceptor {
+func UnaryAuthorizeInterceptor(authenticator auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticator.Authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// UnaryAuthPolicyInterceptor returns a func that uses given policy in processing unary requests.
+func UnaryAuthPolicyInterceptor(policy *auth.Policy) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler) (any, error) {
-		if err := authenticator.Authenticate(ctx); err != nil {
+		ctx, err := policy.Authenticate(ctx, info.FullMethod)
+		if err != nil {
 			return nil, err
 		}
 
@@ -54,7 +54,7 @@ func TestStreamAuthorizeInterceptor(t *testing.T) {
 				defer store.Hdel("apps", test.app)
 			}
 
-			authenticator, err := auth.NewAuthenticator(store, "apps", test.strict)
+			authenticator, err := auth.NewRedisAuthenticator(store, "apps", test.strict)
 			assert.Nil(t, err)
 			interceptor := StreamAuthorizeInterceptor(authenticator)
 			md := metadata.New(map[string]string{
@@ -118,7 +118,7 @@ func TestUnaryAuthorizeInterceptor(t *testing.T) {
 				defer store.Hdel("apps", test.app)
 			}
 
-			authenticator, err := auth.NewAuthenticator(store, "apps", test.strict)
+			authenticator, err := auth.NewRedisAuthenticator(store, "apps", test.strict)
 			assert.Nil(t, err)
 			interceptor := UnaryAuthorizeInterceptor(authenticator)
 			md := metadata.New(map[string]string{
@@ -191,3 +191,41 @@ func (m mockedStream) SendMsg(_ any) error {
 func (m mockedStream) RecvMsg(_ any) error {
 	return nil
 }
+
+func TestAuthPolicyInterceptor(t *testing.T) {
+	policy := auth.NewPolicy([]string{auth.ApiKeyName}, []auth.Rule{
+		{
+			Method: "/foo.Public/*",
+		},
+	})
+	policy.AddAuthenticator(auth.ApiKeyName, auth.NewApiKeyAuthenticator([]string{"key"}))
+
+	unary := UnaryAuthPolicyInterceptor(policy)
+	_, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{
+		FullMethod: "/foo.Public/Ping",
+	}, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	_, err = unary(context.Background(), nil, &grpc.UnaryServerInfo{
+		FullMethod: "/foo.Admin/Ping",
+	}, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	assert.Error(t, err)
+
+	stream := StreamAuthPolicyInterceptor(policy)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "key"))
+	err = stream(nil, mockedStream{ctx: ctx}, &grpc.StreamServerInfo{
+		FullMethod: "/foo.Admin/Ping",
+	}, func(_ any, _ grpc.ServerStream) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	err = stream(nil, mockedStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+		FullMethod: "/foo.Admin/Ping",
+	}, func(_ any, _ grpc.ServerStream) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
@@ -29,8 +29,81 @@ var (
 		Help:      "rpc server requests code count.",
 		Labels:    []string{"method", "code"},
 	})
+
+	metricServerStreamDur = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "streams",
+		Name:      "duration_ms",
+		Help:      "rpc server streams duration(ms).",
+		Labels:    []string{"method"},
+		Buckets: []float64{10, 100, 500, 1000, 5000, 10000, 30000, 60000, 300000, 600000,
+			1800000, 3600000},
+	})
+
+	metricServerStreamOpenTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "streams",
+		Name:      "open_total",
+		Help:      "rpc server opened streams count.",
+		Labels:    []string{"method"},
+	})
+
+	metricServerStreamCodeTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "streams",
+		Name:      "code_total",
+		Help:      "rpc server closed streams code count.",
+		Labels:    []string{"method", "code"},
+	})
+
+	metricServerStreamActive = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "streams",
+		Name:      "active",
+		Help:      "rpc server active streams.",
+		Labels:    []string{"method"},
+	})
+
+	metricServerStreamMsgSent = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "streams",
+		Name:      "msgs_sent_total",
+		Help:      "rpc server stream messages sent count.",
+		Labels:    []string{"method"},
+	})
+
+	metricServerStreamMsgReceived = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "streams",
+		Name:      "msgs_received_total",
+		Help:      "rpc server stream messages received count.",
+		Labels:    []string{"method"},
+	})
 )
 
+type promServerStream struct {
+	grpc.ServerStream
+	method string
+}
+
+// StreamPrometheusInterceptor reports the statistics of the streams to the prometheus server,
+// including the opened, active and closed streams, the durations and the messages.
+func StreamPrometheusInterceptor(svr any, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	startTime := timex.Now()
+	metricServerStreamOpenTotal.Inc(info.FullMethod)
+	metricServerStreamActive.Inc(info.FullMethod)
+	defer metricServerStreamActive.Dec(info.FullMethod)
+
+	err := handler(svr, &promServerStream{
+		ServerStream: stream,
+		method:       info.FullMethod,
+	})
+	metricServerStreamDur.Observe(timex.Since(startTime).Milliseconds(), info.FullMethod)
+	metricServerStreamCodeTotal.Inc(info.FullMethod, strconv.Itoa(int(status.Code(err))))
+	return err
+}
+
 // UnaryPrometheusInterceptor reports the statistics to the prometheus server.
 func UnaryPrometheusInterceptor(ctx context.Context, req any,
 	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
@@ -40,3 +113,21 @@ func UnaryPrometheusInterceptor(ctx context.Context, req any,
 	metricServerReqCodeTotal.Inc(info.FullMethod, strconv.Itoa(int(status.Code(err))))
 	return resp, err
 }
+
+func (s *promServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		metricServerStreamMsgReceived.Inc(s.method)
+	}
+
+	return err
+}
+
+func (s *promServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		metricServerStreamMsgSent.Inc(s.method)
+	}
+
+	return err
+}
@@ -2,6 +2,7 @@ package serverinterceptors
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/r27153733/fastgozero/core/prometheus"
@@ -9,6 +10,8 @@ import (
 	"google.golang.org/grpc"
 )
 
+var errMock = errors.New("mock")
+
 func TestUnaryPromMetricInterceptor_Disabled(t *testing.T) {
 	_, err := UnaryPrometheusInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{
 		FullMethod: "/",
@@ -18,6 +21,26 @@ func TestUnaryPromMetricInterceptor_Disabled(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestStreamPromMetricInterceptor(t *testing.T) {
+	err := StreamPrometheusInterceptor(nil, &mockedServerStream{}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(svr any, stream grpc.ServerStream) error {
+		assert.NoError(t, stream.SendMsg(nil))
+		assert.NoError(t, stream.RecvMsg(nil))
+		return nil
+	})
+	assert.Nil(t, err)
+
+	err = StreamPrometheusInterceptor(nil, &mockedServerStream{err: errMock}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(svr any, stream grpc.ServerStream) error {
+		assert.Equal(t, errMock, stream.SendMsg(nil))
+		assert.Equal(t, errMock, stream.RecvMsg(nil))
+		return errMock
+	})
+	assert.Equal(t, errMock, err)
+}
+
 func TestUnaryPromMetricInterceptor_Enabled(t *testing.T) {
 	prometheus.StartAgent(prometheus.Config{
 		Host: "localhost",
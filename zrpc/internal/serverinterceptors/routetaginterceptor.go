@@ -0,0 +1,64 @@
+package serverinterceptors
+
+import (
+	"context"
+
+	"github.com/r27153733/fastgozero/core/routetag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// StreamRouteTagInterceptor is an interceptor that puts the incoming route tags into
+// the context, to propagate them to the downstream calls.
+func StreamRouteTagInterceptor(svr any, stream grpc.ServerStream, _ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	ctx := stream.Context()
+	tags := routeTagsFromIncoming(ctx)
+	if len(tags) == 0 {
+		return handler(svr, stream)
+	}
+
+	return handler(svr, withStreamContext(stream, routetag.NewContext(ctx, tags)))
+}
+
+// UnaryRouteTagInterceptor is an interceptor that puts the incoming route tags into
+// the context, to propagate them to the downstream calls.
+func UnaryRouteTagInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	if tags := routeTagsFromIncoming(ctx); len(tags) > 0 {
+		ctx = routetag.NewContext(ctx, tags)
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// withStreamContext returns a grpc.ServerStream with the given ctx.
+func withStreamContext(stream grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &contextServerStream{
+		ServerStream: stream,
+		ctx:          ctx,
+	}
+}
+
+func routeTagsFromIncoming(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	vals := md.Get(routetag.MetadataKey)
+	if len(vals) == 0 {
+		return ""
+	}
+
+	return vals[0]
+}
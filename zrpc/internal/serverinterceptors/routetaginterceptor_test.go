@@ -0,0 +1,47 @@
+package serverinterceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/r27153733/fastgozero/core/routetag"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryRouteTagInterceptor(t *testing.T) {
+	_, err := UnaryRouteTagInterceptor(context.Background(), nil, nil,
+		func(ctx context.Context, req any) (any, error) {
+			assert.Empty(t, routetag.FromContext(ctx))
+			return nil, nil
+		})
+	assert.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(routetag.MetadataKey, "version=v2"))
+	_, err = UnaryRouteTagInterceptor(ctx, nil, nil,
+		func(ctx context.Context, req any) (any, error) {
+			assert.Equal(t, "version=v2", routetag.FromContext(ctx))
+			return nil, nil
+		})
+	assert.NoError(t, err)
+}
+
+func TestStreamRouteTagInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(routetag.MetadataKey, "version=v2"))
+	err := StreamRouteTagInterceptor(nil, mockedStream{ctx: ctx}, nil,
+		func(svr any, stream grpc.ServerStream) error {
+			assert.Equal(t, "version=v2", routetag.FromContext(stream.Context()))
+			return nil
+		})
+	assert.NoError(t, err)
+
+	err = StreamRouteTagInterceptor(nil, mockedStream{ctx: context.Background()}, nil,
+		func(svr any, stream grpc.ServerStream) error {
+			assert.Empty(t, routetag.FromContext(stream.Context()))
+			return nil
+		})
+	assert.NoError(t, err)
+}
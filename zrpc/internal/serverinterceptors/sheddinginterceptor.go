@@ -19,6 +19,29 @@ var (
 	lock         sync.Mutex
 )
 
+// StreamSheddingInterceptor returns a func that does load shedding on opening streams.
+func StreamSheddingInterceptor(shedder load.Shedder, metrics *stat.Metrics) grpc.StreamServerInterceptor {
+	ensureSheddingStat()
+
+	return func(svr any, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		sheddingStat.IncrementTotal()
+		promise, err := shedder.Allow()
+		if err != nil {
+			metrics.AddDrop()
+			sheddingStat.IncrementDrop()
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+
+		// release the promise without reporting the response time once the stream is opened,
+		// otherwise the long-lived streams skew the response time of the shedder.
+		sheddingStat.IncrementPass()
+		promise.Fail()
+
+		return handler(svr, stream)
+	}
+}
+
 // UnarySheddingInterceptor returns a func that does load shedding on processing unary requests.
 func UnarySheddingInterceptor(shedder load.Shedder, metrics *stat.Metrics) grpc.UnaryServerInterceptor {
 	ensureSheddingStat()
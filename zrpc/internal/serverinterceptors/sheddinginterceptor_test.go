@@ -57,6 +57,26 @@ func TestUnarySheddingInterceptor(t *testing.T) {
 	}
 }
 
+func TestStreamSheddingInterceptor(t *testing.T) {
+	metrics := stat.NewMetrics("mock")
+	interceptor := StreamSheddingInterceptor(mockedShedder{allow: true}, metrics)
+	err := interceptor(nil, &mockedServerStream{}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(svr any, stream grpc.ServerStream) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	interceptor = StreamSheddingInterceptor(mockedShedder{allow: false}, metrics)
+	err = interceptor(nil, &mockedServerStream{}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(svr any, stream grpc.ServerStream) error {
+		t.Fatal("should not be called")
+		return nil
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
 type mockedShedder struct {
 	allow bool
 }
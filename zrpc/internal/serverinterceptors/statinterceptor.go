@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/r27153733/fastgozero/core/collection"
@@ -41,6 +42,41 @@ func SetSlowThreshold(threshold time.Duration) {
 	slowThreshold.Set(threshold)
 }
 
+type statServerStream struct {
+	grpc.ServerStream
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+// StreamStatInterceptor returns a func that logs the streams on closing, with the durations
+// and the counts of the messages sent and received. The slow threshold doesn't apply,
+// because streams are usually long-lived.
+func StreamStatInterceptor(svr any, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	startTime := timex.Now()
+	ss := &statServerStream{
+		ServerStream: stream,
+	}
+	err := handler(svr, ss)
+
+	ctx := stream.Context()
+	var addr string
+	if client, ok := peer.FromContext(ctx); ok {
+		addr = client.Addr.String()
+	}
+
+	logger := logx.WithContext(ctx).WithDuration(timex.Since(startTime))
+	if err != nil {
+		logger.Errorf("[RPC] stream closed - %s - %s - sent: %d, received: %d, error: %v",
+			addr, info.FullMethod, ss.sent.Load(), ss.received.Load(), err)
+	} else {
+		logger.Infof("[RPC] stream closed - %s - %s - sent: %d, received: %d",
+			addr, info.FullMethod, ss.sent.Load(), ss.received.Load())
+	}
+
+	return err
+}
+
 // UnaryStatInterceptor returns a func that uses given metrics to report stats.
 func UnaryStatInterceptor(metrics *stat.Metrics, conf StatConf) grpc.UnaryServerInterceptor {
 	staticNotLoggingContentMethods := collection.NewSet()
@@ -96,3 +132,21 @@ func shouldLogContent(method string, ignoreMethods *collection.Set) bool {
 	_, ok := ignoreContentMethods.Load(method)
 	return !ok && !ignoreMethods.Contains(method)
 }
+
+func (s *statServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(1)
+	}
+
+	return err
+}
+
+func (s *statServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	}
+
+	return err
+}
@@ -37,6 +37,32 @@ func TestUnaryStatInterceptor(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestStreamStatInterceptor(t *testing.T) {
+	stream := &mockedServerStream{}
+	err := StreamStatInterceptor(nil, stream, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(svr any, stream grpc.ServerStream) error {
+		ss := stream.(*statServerStream)
+		assert.NoError(t, stream.SendMsg(nil))
+		assert.NoError(t, stream.RecvMsg(nil))
+		assert.NoError(t, stream.RecvMsg(nil))
+		assert.Equal(t, int64(1), ss.sent.Load())
+		assert.Equal(t, int64(2), ss.received.Load())
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = StreamStatInterceptor(nil, &mockedServerStream{err: errMock}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(svr any, stream grpc.ServerStream) error {
+		ss := stream.(*statServerStream)
+		assert.Equal(t, errMock, stream.SendMsg(nil))
+		assert.Zero(t, ss.sent.Load())
+		return errMock
+	})
+	assert.Equal(t, errMock, err)
+}
+
 func TestLogDuration(t *testing.T) {
 	addrs, err := net.InterfaceAddrs()
 	assert.Nil(t, err)
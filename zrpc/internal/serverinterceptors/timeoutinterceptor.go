@@ -24,6 +24,24 @@ type (
 	methodTimeouts map[string]time.Duration
 )
 
+// StreamTimeoutInterceptor returns a func that limits the lifetime of the incoming streams,
+// the context of the streams is canceled after lifetime, the handlers should return on
+// the context done with its error, then DeadlineExceeded is returned to the clients to reopen the streams.
+func StreamTimeoutInterceptor(lifetime time.Duration) grpc.StreamServerInterceptor {
+	return func(svr any, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		ctx, cancel := context.WithTimeout(stream.Context(), lifetime)
+		defer cancel()
+
+		err := handler(svr, withStreamContext(stream, ctx))
+		if errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return status.Error(codes.DeadlineExceeded, "stream lifetime exceeded")
+		}
+
+		return err
+	}
+}
+
 // UnaryTimeoutInterceptor returns a func that sets timeout to incoming unary requests.
 func UnaryTimeoutInterceptor(timeout time.Duration,
 	methodTimeouts ...MethodTimeoutConf) grpc.UnaryServerInterceptor {
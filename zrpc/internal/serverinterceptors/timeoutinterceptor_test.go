@@ -17,6 +17,26 @@ var (
 	canceledErr         = status.Error(codes.Canceled, context.Canceled.Error())
 )
 
+func TestStreamTimeoutInterceptor(t *testing.T) {
+	interceptor := StreamTimeoutInterceptor(time.Millisecond * 10)
+	err := interceptor(nil, &mockedServerStream{}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(svr any, stream grpc.ServerStream) error {
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	err = interceptor(nil, &mockedServerStream{}, &grpc.StreamServerInfo{
+		FullMethod: "/",
+	}, func(svr any, stream grpc.ServerStream) error {
+		_, ok := stream.Context().Deadline()
+		assert.True(t, ok)
+		return errMock
+	})
+	assert.Equal(t, errMock, err)
+}
+
 func TestUnaryTimeoutInterceptor(t *testing.T) {
 	interceptor := UnaryTimeoutInterceptor(time.Millisecond * 10)
 	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{
@@ -0,0 +1,172 @@
+package tlsx
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/timex"
+	"google.golang.org/grpc/credentials"
+)
+
+type (
+	configBuilder func(c Conf) (*tls.Config, error)
+
+	// reloadableCredentials reloads the certificates if the files are changed,
+	// the files are checked on handshakes at most once per ReloadInterval.
+	reloadableCredentials struct {
+		conf      Conf
+		build     configBuilder
+		interval  time.Duration
+		lock      sync.Mutex
+		creds     credentials.TransportCredentials
+		modTimes  []time.Time
+		lastCheck time.Duration
+	}
+)
+
+// NewClientCredentials returns the client side credentials, which reload the
+// certificates if the files are rotated.
+func NewClientCredentials(c Conf) (credentials.TransportCredentials, error) {
+	return newReloadableCredentials(c, NewClientConfig)
+}
+
+// NewServerCredentials returns the server side credentials, which reload the
+// certificates if the files are rotated.
+func NewServerCredentials(c Conf) (credentials.TransportCredentials, error) {
+	return newReloadableCredentials(c, NewServerConfig)
+}
+
+func newReloadableCredentials(c Conf, build configBuilder) (*reloadableCredentials, error) {
+	interval := c.ReloadInterval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+
+	rc := &reloadableCredentials{
+		conf:     c,
+		build:    build,
+		interval: interval,
+	}
+	if err := rc.load(); err != nil {
+		return nil, err
+	}
+
+	return rc, nil
+}
+
+func (rc *reloadableCredentials) ClientHandshake(ctx context.Context, authority string,
+	conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return rc.current().ClientHandshake(ctx, authority, conn)
+}
+
+func (rc *reloadableCredentials) Clone() credentials.TransportCredentials {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	return &reloadableCredentials{
+		conf:      rc.conf,
+		build:     rc.build,
+		interval:  rc.interval,
+		creds:     rc.creds.Clone(),
+		modTimes:  rc.modTimes,
+		lastCheck: rc.lastCheck,
+	}
+}
+
+func (rc *reloadableCredentials) Info() credentials.ProtocolInfo {
+	return rc.current().Info()
+}
+
+// OverrideServerName overrides the server name used to verify the hostname of the servers.
+// Deprecated: use grpc.WithAuthority instead, kept to implement credentials.TransportCredentials.
+func (rc *reloadableCredentials) OverrideServerName(name string) error {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	rc.conf.ServerName = name
+	return rc.loadLocked()
+}
+
+func (rc *reloadableCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return rc.current().ServerHandshake(conn)
+}
+
+func (rc *reloadableCredentials) current() credentials.TransportCredentials {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	now := timex.Now()
+	if now-rc.lastCheck < rc.interval {
+		return rc.creds
+	}
+
+	rc.lastCheck = now
+	modTimes := rc.fileModTimes()
+	if equalTimes(modTimes, rc.modTimes) {
+		return rc.creds
+	}
+
+	// keep using the old certificates on errors, like the files are partially written.
+	if err := rc.loadLocked(); err != nil {
+		logx.Errorf("failed to reload tls certificates, error: %v", err)
+	} else {
+		logx.Info("tls certificates reloaded")
+	}
+
+	return rc.creds
+}
+
+func (rc *reloadableCredentials) fileModTimes() []time.Time {
+	files := []string{rc.conf.CertFile, rc.conf.KeyFile, rc.conf.CaFile}
+	modTimes := make([]time.Time, len(files))
+	for i, file := range files {
+		if len(file) == 0 {
+			continue
+		}
+
+		if info, err := os.Stat(file); err == nil {
+			modTimes[i] = info.ModTime()
+		}
+	}
+
+	return modTimes
+}
+
+func (rc *reloadableCredentials) load() error {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	rc.lastCheck = timex.Now()
+	return rc.loadLocked()
+}
+
+func (rc *reloadableCredentials) loadLocked() error {
+	modTimes := rc.fileModTimes()
+	cfg, err := rc.build(rc.conf)
+	if err != nil {
+		return err
+	}
+
+	rc.creds = credentials.NewTLS(cfg)
+	rc.modTimes = modTimes
+	return nil
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
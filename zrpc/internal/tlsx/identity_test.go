@@ -0,0 +1,56 @@
+package tlsx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestPeerIdentityFromContext(t *testing.T) {
+	_, ok := PeerIdentityFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{})
+	_, ok = PeerIdentityFromContext(ctx)
+	assert.False(t, ok)
+
+	ctx = peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{},
+	})
+	_, ok = PeerIdentityFromContext(ctx)
+	assert.False(t, ok)
+
+	uri, err := url.Parse("spiffe://example.com/user")
+	assert.NoError(t, err)
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "user"},
+		DNSNames:       []string{"user.example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("127.0.0.1")},
+		URIs:           []*url.URL{uri},
+		EmailAddresses: []string{"user@example.com"},
+	}
+	ctx = peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{cert}},
+			},
+		},
+	})
+	identity, ok := PeerIdentityFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, PeerIdentity{
+		CommonName:     "user",
+		DNSNames:       []string{"user.example.com"},
+		IPAddresses:    []string{"127.0.0.1"},
+		URIs:           []string{"spiffe://example.com/user"},
+		EmailAddresses: []string{"user@example.com"},
+	}, identity)
+}
@@ -0,0 +1,282 @@
+package tlsx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	clientAuthNone             = "none"
+	clientAuthRequest          = "request"
+	clientAuthRequire          = "require"
+	clientAuthVerifyIfGiven    = "verify_if_given"
+	clientAuthRequireAndVerify = "require_and_verify"
+
+	defaultReloadInterval = time.Minute
+)
+
+var (
+	errMissingKeyPair  = errors.New("tls: CertFile and KeyFile must be set together")
+	errMissingCert     = errors.New("tls: CertFile and KeyFile are required on server side")
+	errInvalidCaFile   = errors.New("tls: no certificates found in CaFile")
+	errSanNotAllowed   = errors.New("tls: peer certificate SANs are not allowed")
+	errMissingPeerCert = errors.New("tls: missing peer certificate")
+	errUnverifiedSans  = errors.New("tls: AllowedSans requires verifying the client certificates")
+)
+
+// A Conf is the TLS config of the rpc servers and clients.
+type Conf struct {
+	// CertFile and KeyFile are the certificate and private key of this side,
+	// required on server side, and required on client side if mutual TLS is used.
+	CertFile string `json:",optional"`
+	KeyFile  string `json:",optional"`
+	// CaFile is used to verify the peer certificates, the system roots are used
+	// by the clients if not set.
+	CaFile string `json:",optional"`
+	// ClientAuth is the client certificate policy on server side, defaults to
+	// require_and_verify if CaFile is set, otherwise none.
+	ClientAuth string `json:",optional,options=none|request|require|verify_if_given|require_and_verify"`
+	// AllowedSans restricts the peer certificates to the ones with any of the SANs,
+	// including the dns names, ip addresses, uris and emails, like *.example.com.
+	// If set without ServerName on client side, the server hostname is not verified.
+	// On server side, it requires verifying the client certificates with CaFile.
+	AllowedSans []string `json:",optional"`
+	// ServerName is used by the clients to verify the hostname of the servers.
+	ServerName string `json:",optional"`
+	// ReloadInterval is the interval to check if the files are rotated.
+	ReloadInterval time.Duration `json:",default=1m"`
+}
+
+// Enabled returns true if TLS is configured.
+func (c Conf) Enabled() bool {
+	return len(c.CertFile) > 0 || len(c.CaFile) > 0 || len(c.ServerName) > 0
+}
+
+// VerifyClients returns true if the servers verify the client certificates.
+func (c Conf) VerifyClients() bool {
+	if len(c.CaFile) == 0 {
+		return false
+	}
+
+	switch c.ClientAuth {
+	case "", clientAuthVerifyIfGiven, clientAuthRequireAndVerify:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate validates the config.
+func (c Conf) Validate() error {
+	if (len(c.CertFile) == 0) != (len(c.KeyFile) == 0) {
+		return errMissingKeyPair
+	}
+
+	return nil
+}
+
+// NewClientConfig returns a client side tls.Config built from c.
+func NewClientConfig(c Conf) (*tls.Config, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+	if len(c.CertFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	var roots *x509.CertPool
+	if len(c.CaFile) > 0 {
+		pool, err := loadCertPool(c.CaFile)
+		if err != nil {
+			return nil, err
+		}
+		roots = pool
+		cfg.RootCAs = pool
+	}
+
+	if len(c.AllowedSans) > 0 {
+		if len(c.ServerName) == 0 {
+			// the hostname is verified by the SANs, but the chain still needs to be verified.
+			cfg.InsecureSkipVerify = true
+			cfg.VerifyConnection = verifyChainAndSans(roots, c.AllowedSans)
+		} else {
+			cfg.VerifyConnection = verifySans(c.AllowedSans)
+		}
+	}
+
+	return cfg, nil
+}
+
+// NewServerConfig returns a server side tls.Config built from c.
+func NewServerConfig(c Conf) (*tls.Config, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if len(c.CertFile) == 0 {
+		return nil, errMissingCert
+	}
+	// the SANs of the unverified client certificates can be forged.
+	if len(c.AllowedSans) > 0 && !c.VerifyClients() {
+		return nil, errUnverifiedSans
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if len(c.CaFile) > 0 {
+		pool, err := loadCertPool(c.CaFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+	}
+
+	cfg.ClientAuth, err = clientAuthType(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.AllowedSans) > 0 {
+		checkSans := verifySans(c.AllowedSans)
+		cfg.VerifyConnection = func(state tls.ConnectionState) error {
+			// no client certificates if the client auth policy allows
+			if len(state.PeerCertificates) == 0 {
+				return nil
+			}
+			if len(state.VerifiedChains) == 0 {
+				return errUnverifiedSans
+			}
+
+			return checkSans(state)
+		}
+	}
+
+	return cfg, nil
+}
+
+func clientAuthType(c Conf) (tls.ClientAuthType, error) {
+	switch c.ClientAuth {
+	case "":
+		if len(c.CaFile) > 0 {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.NoClientCert, nil
+	case clientAuthNone:
+		return tls.NoClientCert, nil
+	case clientAuthRequest:
+		return tls.RequestClientCert, nil
+	case clientAuthRequire:
+		return tls.RequireAnyClientCert, nil
+	case clientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case clientAuthRequireAndVerify:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("tls: unknown ClientAuth %q", c.ClientAuth)
+	}
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, errInvalidCaFile
+	}
+
+	return pool, nil
+}
+
+func matchSan(pattern, name string) bool {
+	if pattern == name {
+		return true
+	}
+
+	// wildcard matches only one non-empty label, like *.example.com matches a.example.com,
+	// but not .example.com, see RFC 6125 section 6.4.3.
+	if strings.HasPrefix(pattern, "*.") {
+		suffix := pattern[1:]
+		label, ok := strings.CutSuffix(name, suffix)
+		return ok && len(label) > 0 && !strings.Contains(label, ".")
+	}
+
+	return false
+}
+
+func sansOf(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses)+
+		len(cert.URIs)+len(cert.EmailAddresses))
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	return append(sans, cert.EmailAddresses...)
+}
+
+func verifyChainAndSans(roots *x509.CertPool, allowed []string) func(tls.ConnectionState) error {
+	checkSans := verifySans(allowed)
+
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errMissingPeerCert
+		}
+
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range state.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		if _, err := state.PeerCertificates[0].Verify(opts); err != nil {
+			return err
+		}
+
+		return checkSans(state)
+	}
+}
+
+func verifySans(allowed []string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errMissingPeerCert
+		}
+
+		for _, san := range sansOf(state.PeerCertificates[0]) {
+			for _, pattern := range allowed {
+				if matchSan(pattern, san) {
+					return nil
+				}
+			}
+		}
+
+		return errSanNotAllowed
+	}
+}
@@ -0,0 +1,354 @@
+package tlsx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func TestConf(t *testing.T) {
+	var c Conf
+	assert.False(t, c.Enabled())
+	assert.False(t, c.VerifyClients())
+	assert.NoError(t, c.Validate())
+
+	c.CertFile = "cert.pem"
+	assert.True(t, c.Enabled())
+	assert.Error(t, c.Validate())
+	c.KeyFile = "key.pem"
+	assert.NoError(t, c.Validate())
+
+	c.CaFile = "ca.pem"
+	assert.True(t, c.VerifyClients())
+	c.ClientAuth = clientAuthRequire
+	assert.False(t, c.VerifyClients())
+	c.ClientAuth = clientAuthVerifyIfGiven
+	assert.True(t, c.VerifyClients())
+}
+
+func TestClientAuthType(t *testing.T) {
+	tests := map[string]tls.ClientAuthType{
+		clientAuthNone:             tls.NoClientCert,
+		clientAuthRequest:          tls.RequestClientCert,
+		clientAuthRequire:          tls.RequireAnyClientCert,
+		clientAuthVerifyIfGiven:    tls.VerifyClientCertIfGiven,
+		clientAuthRequireAndVerify: tls.RequireAndVerifyClientCert,
+	}
+	for mode, expect := range tests {
+		actual, err := clientAuthType(Conf{ClientAuth: mode})
+		assert.NoError(t, err)
+		assert.Equal(t, expect, actual)
+	}
+
+	actual, err := clientAuthType(Conf{})
+	assert.NoError(t, err)
+	assert.Equal(t, tls.NoClientCert, actual)
+	actual, err = clientAuthType(Conf{CaFile: "ca.pem"})
+	assert.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, actual)
+	_, err = clientAuthType(Conf{ClientAuth: "any"})
+	assert.Error(t, err)
+}
+
+func TestMatchSan(t *testing.T) {
+	assert.True(t, matchSan("a.example.com", "a.example.com"))
+	assert.True(t, matchSan("*.example.com", "a.example.com"))
+	assert.False(t, matchSan("*.example.com", "a.b.example.com"))
+	assert.False(t, matchSan("*.example.com", "example.com"))
+	assert.False(t, matchSan("*.example.com", ".example.com"))
+	assert.False(t, matchSan("a.example.com", "b.example.com"))
+}
+
+func TestNewConfig_errors(t *testing.T) {
+	_, err := NewServerConfig(Conf{})
+	assert.Error(t, err)
+	_, err = NewServerConfig(Conf{CertFile: "cert.pem"})
+	assert.Error(t, err)
+	_, err = NewServerConfig(Conf{CertFile: "cert.pem", KeyFile: "key.pem"})
+	assert.Error(t, err)
+	_, err = NewServerConfig(Conf{CertFile: "cert.pem", KeyFile: "key.pem",
+		AllowedSans: []string{"*.example.com"}})
+	assert.Equal(t, errUnverifiedSans, err)
+	_, err = NewServerConfig(Conf{CertFile: "cert.pem", KeyFile: "key.pem", CaFile: "ca.pem",
+		ClientAuth: clientAuthRequire, AllowedSans: []string{"*.example.com"}})
+	assert.Equal(t, errUnverifiedSans, err)
+	_, err = NewClientConfig(Conf{CertFile: "cert.pem"})
+	assert.Error(t, err)
+	_, err = NewClientConfig(Conf{CertFile: "cert.pem", KeyFile: "key.pem"})
+	assert.Error(t, err)
+	_, err = NewClientConfig(Conf{CaFile: "ca.pem"})
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "ca.pem")
+	assert.NoError(t, os.WriteFile(file, []byte("bad"), 0o600))
+	_, err = NewClientConfig(Conf{CaFile: file})
+	assert.Equal(t, errInvalidCaFile, err)
+}
+
+func TestHandshake(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	serverConf := Conf{
+		CaFile: writeCert(t, dir, "ca", ca, nil),
+	}
+	serverConf.CertFile, serverConf.KeyFile = writeKeyPair(t, dir, "server",
+		newTestCert(t, ca, "server", "server.example.com"))
+	clientConf := Conf{
+		CaFile: serverConf.CaFile,
+	}
+	clientConf.CertFile, clientConf.KeyFile = writeKeyPair(t, dir, "client",
+		newTestCert(t, ca, "client", "client.example.com"))
+
+	tests := []struct {
+		name      string
+		serverMod func(c *Conf)
+		clientMod func(c *Conf)
+		hasError  bool
+	}{
+		{
+			name: "server name",
+			clientMod: func(c *Conf) {
+				c.ServerName = "server.example.com"
+			},
+		},
+		{
+			name: "wrong server name",
+			clientMod: func(c *Conf) {
+				c.ServerName = "other.example.com"
+			},
+			hasError: true,
+		},
+		{
+			name: "allowed sans",
+			serverMod: func(c *Conf) {
+				c.AllowedSans = []string{"*.example.com"}
+			},
+			clientMod: func(c *Conf) {
+				c.AllowedSans = []string{"server.example.com"}
+			},
+		},
+		{
+			name: "server not allowed",
+			clientMod: func(c *Conf) {
+				c.AllowedSans = []string{"other.example.com"}
+			},
+			hasError: true,
+		},
+		{
+			name: "client not allowed",
+			serverMod: func(c *Conf) {
+				c.AllowedSans = []string{"other.example.com"}
+			},
+			clientMod: func(c *Conf) {
+				c.ServerName = "server.example.com"
+			},
+			hasError: true,
+		},
+		{
+			name: "self-signed client with allowed sans",
+			serverMod: func(c *Conf) {
+				c.AllowedSans = []string{"*.example.com"}
+			},
+			clientMod: func(c *Conf) {
+				c.ServerName = "server.example.com"
+				c.CertFile, c.KeyFile = writeKeyPair(t, dir, "forged",
+					newTestCert(t, newTestCA(t), "forged", "client.example.com"))
+			},
+			hasError: true,
+		},
+		{
+			name: "client cert required",
+			clientMod: func(c *Conf) {
+				c.ServerName = "server.example.com"
+				c.CertFile = ""
+				c.KeyFile = ""
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			sc, cc := serverConf, clientConf
+			if test.serverMod != nil {
+				test.serverMod(&sc)
+			}
+			if test.clientMod != nil {
+				test.clientMod(&cc)
+			}
+
+			serverCfg, err := NewServerConfig(sc)
+			assert.NoError(t, err)
+			clientCfg, err := NewClientConfig(cc)
+			assert.NoError(t, err)
+
+			// tcp instead of net.Pipe, the tls 1.3 server sends the alert after the client
+			// finishes the handshake, which blocks on the unbuffered pipe.
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.NoError(t, err)
+			defer lis.Close()
+
+			errs := make(chan error, 1)
+			go func() {
+				conn, err := lis.Accept()
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				errs <- tls.Server(conn, serverCfg).Handshake()
+				conn.Close()
+			}()
+
+			conn, err := net.Dial("tcp", lis.Addr().String())
+			assert.NoError(t, err)
+			clientErr := tls.Client(conn, clientCfg).Handshake()
+			serverErr := <-errs
+			conn.Close()
+
+			if test.hasError {
+				assert.True(t, clientErr != nil || serverErr != nil)
+			} else {
+				assert.NoError(t, clientErr)
+				assert.NoError(t, serverErr)
+			}
+		})
+	}
+}
+
+func TestReloadableCredentials(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	c := Conf{
+		CaFile:         writeCert(t, dir, "ca", ca, nil),
+		ReloadInterval: time.Millisecond,
+	}
+	c.CertFile, c.KeyFile = writeKeyPair(t, dir, "server", newTestCert(t, ca, "server", "a.example.com"))
+
+	creds, err := NewServerCredentials(c)
+	assert.NoError(t, err)
+	rc := creds.(*reloadableCredentials)
+	old := rc.current()
+	assert.Equal(t, "tls", rc.Info().SecurityProtocol)
+
+	// not changed
+	time.Sleep(time.Millisecond * 2)
+	assert.True(t, old == rc.current())
+
+	// broken files keep the old certificates
+	assert.NoError(t, os.WriteFile(c.CertFile, []byte("bad"), 0o600))
+	touch(t, c.CertFile)
+	time.Sleep(time.Millisecond * 2)
+	assert.True(t, old == rc.current())
+
+	writeKeyPair(t, dir, "server", newTestCert(t, ca, "server", "b.example.com"))
+	touch(t, c.CertFile)
+	time.Sleep(time.Millisecond * 2)
+	assert.False(t, old == rc.current())
+
+	clone := creds.Clone().(*reloadableCredentials)
+	assert.Equal(t, rc.conf, clone.conf)
+	assert.NoError(t, clone.OverrideServerName("b.example.com"))
+	assert.Equal(t, "b.example.com", clone.conf.ServerName)
+
+	_, err = NewClientCredentials(Conf{CaFile: filepath.Join(dir, "none.pem")})
+	assert.Error(t, err)
+}
+
+func touch(t *testing.T, file string) {
+	now := time.Now().Add(time.Second)
+	assert.NoError(t, os.Chtimes(file, now, now))
+}
+
+func newTestCA(t *testing.T) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return testCert{
+		cert: cert,
+		key:  key,
+	}
+}
+
+func newTestCert(t *testing.T, ca testCert, name string, dnsNames ...string) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return testCert{
+		cert: cert,
+		key:  key,
+	}
+}
+
+func writeCert(t *testing.T, dir, name string, cert testCert, key *string) string {
+	file := filepath.Join(dir, name+".pem")
+	assert.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: cert.cert.Raw,
+	}), 0o600))
+
+	if key != nil {
+		der, err := x509.MarshalECPrivateKey(cert.key)
+		assert.NoError(t, err)
+		*key = filepath.Join(dir, name+".key")
+		assert.NoError(t, os.WriteFile(*key, pem.EncodeToMemory(&pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: der,
+		}), 0o600))
+	}
+
+	return file
+}
+
+func writeKeyPair(t *testing.T, dir, name string, cert testCert) (string, string) {
+	var key string
+	file := writeCert(t, dir, name, cert, &key)
+	return file, key
+}
@@ -4,7 +4,7 @@ import (
 	"strings"
 
 	"github.com/r27153733/fastgozero/core/discov"
-	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
 	"github.com/r27153733/fastgozero/zrpc/resolver/internal/targets"
 	"google.golang.org/grpc/resolver"
 )
@@ -16,31 +16,36 @@ func (b *discovBuilder) Build(target resolver.Target, cc resolver.ClientConn, _
 	hosts := strings.FieldsFunc(targets.GetAuthority(target), func(r rune) bool {
 		return r == EndpointSepChar
 	})
-	sub, err := discov.NewSubscriber(hosts, targets.GetEndpoints(target))
+	registry, err := discov.NewEtcdRegistry(discov.EtcdConf{
+		Hosts: hosts,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	update := func() {
-		vals := subset(sub.Values(), subsetSize)
-		addrs := make([]resolver.Address, 0, len(vals))
-		for _, val := range vals {
-			addrs = append(addrs, resolver.Address{
-				Addr: val,
-			})
-		}
-		if err := cc.UpdateState(resolver.State{
-			Addresses: addrs,
-		}); err != nil {
-			logx.Error(err)
-		}
-	}
-	sub.AddListener(update)
-	update()
-
-	return &nopResolver{cc: cc}, nil
+	key := targets.GetEndpoints(target)
+	return watchEndpoints(cc, func(listener func([]discov.Endpoint)) (func(), error) {
+		return registry.Watch(key, listener)
+	})
 }
 
 func (b *discovBuilder) Scheme() string {
 	return DiscovScheme
 }
+
+// buildAddress builds the resolver.Address of ep, with the metadata attached as attributes.
+func buildAddress(ep discov.Endpoint) resolver.Address {
+	addr := resolver.Address{
+		Addr: ep.Addr,
+	}
+	if !ep.HasMetadata() {
+		return addr
+	}
+
+	return endpoint.WithMetadata(addr, endpoint.Metadata{
+		Weight:  ep.Weight,
+		Zone:    ep.Zone,
+		Version: ep.Version,
+		Tags:    ep.Tags,
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/r27153733/fastgozero/core/discov"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
 	"github.com/stretchr/testify/assert"
 	"go.etcd.io/etcd/client/v3/mock/mockserver"
 	"google.golang.org/grpc/resolver"
@@ -38,6 +40,26 @@ func TestDiscovBuilder_Build(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestBuildAddress(t *testing.T) {
+	addr := buildAddress(discov.Endpoint{
+		Addr: "localhost:8080",
+	})
+	assert.Equal(t, resolver.Address{Addr: "localhost:8080"}, addr)
+
+	addr = buildAddress(discov.Endpoint{
+		Addr:    "localhost:8080",
+		Weight:  10,
+		Zone:    "zone-a",
+		Version: "v2",
+	})
+	assert.Equal(t, "localhost:8080", addr.Addr)
+	md, ok := endpoint.FromAddress(addr)
+	assert.True(t, ok)
+	assert.Equal(t, 10, md.Weight)
+	assert.Equal(t, "zone-a", md.Zone)
+	assert.Equal(t, "v2", md.Version)
+}
+
 type mockClientConn struct{}
 
 func (m mockClientConn) UpdateState(_ resolver.State) error {
@@ -10,6 +10,7 @@ import (
 
 const (
 	colon            = ":"
+	slash            = "/"
 	defaultNamespace = "default"
 )
 
@@ -22,15 +23,29 @@ type Service struct {
 	Port      int
 }
 
+// ParseService parses the service like namespace/name:port, name:port or name,
+// the namespace is default if not given.
+func ParseService(service string) (Service, error) {
+	namespace, endpoints, ok := strings.Cut(service, slash)
+	if !ok {
+		namespace, endpoints = "", service
+	}
+
+	return parseService(namespace, endpoints)
+}
+
 // ParseTarget parses the resolver.Target.
 func ParseTarget(target resolver.Target) (Service, error) {
+	return parseService(targets.GetAuthority(target), targets.GetEndpoints(target))
+}
+
+func parseService(namespace, endpoints string) (Service, error) {
 	var service Service
-	service.Namespace = targets.GetAuthority(target)
+	service.Namespace = namespace
 	if len(service.Namespace) == 0 {
 		service.Namespace = defaultNamespace
 	}
 
-	endpoints := targets.GetEndpoints(target)
 	if strings.Contains(endpoints, colon) {
 		segs := strings.SplitN(endpoints, colon, 2)
 		service.Name = segs[0]
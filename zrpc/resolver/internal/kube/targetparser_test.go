@@ -73,3 +73,51 @@ func TestParseTarget(t *testing.T) {
 		})
 	}
 }
+
+func TestParseService(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect Service
+		hasErr bool
+	}{
+		{
+			input: "ns1/my-svc:8080",
+			expect: Service{
+				Namespace: "ns1",
+				Name:      "my-svc",
+				Port:      8080,
+			},
+		},
+		{
+			input: "my-svc:8080",
+			expect: Service{
+				Namespace: defaultNamespace,
+				Name:      "my-svc",
+				Port:      8080,
+			},
+		},
+		{
+			input: "ns1/my-svc",
+			expect: Service{
+				Namespace: "ns1",
+				Name:      "my-svc",
+			},
+		},
+		{
+			input:  "ns1/my-svc:port",
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			svc, err := ParseService(test.input)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expect, svc)
+			}
+		})
+	}
+}
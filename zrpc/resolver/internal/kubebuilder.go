@@ -5,9 +5,10 @@ package internal
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"time"
 
-	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/core/discov"
 	"github.com/r27153733/fastgozero/core/threading"
 	"github.com/r27153733/fastgozero/zrpc/resolver/internal/kube"
 	"google.golang.org/grpc/resolver"
@@ -22,33 +23,52 @@ const (
 	nameSelector   = "metadata.name="
 )
 
-type kubeResolver struct {
-	cc     resolver.ClientConn
-	inf    informers.SharedInformerFactory
-	stopCh chan struct{}
-}
+type (
+	kubeBuilder struct{}
 
-func (r *kubeResolver) ResolveNow(_ resolver.ResolveNowOptions) {}
+	// kubeRegistry is a read only discov.Registry, the endpoints are registered by kubernetes.
+	kubeRegistry struct{}
+)
 
-func (r *kubeResolver) start() {
-	threading.GoSafe(func() {
-		r.inf.Start(r.stopCh)
+func (b *kubeBuilder) Build(target resolver.Target, cc resolver.ClientConn,
+	_ resolver.BuildOptions) (resolver.Resolver, error) {
+	svc, err := kube.ParseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return watchEndpoints(cc, func(listener func([]discov.Endpoint)) (func(), error) {
+		return watchService(svc, listener)
 	})
 }
 
-func (r *kubeResolver) Close() {
-	close(r.stopCh)
+func (b *kubeBuilder) Scheme() string {
+	return KubernetesScheme
 }
 
-type kubeBuilder struct{}
+func newKubeRegistry(_ *url.URL) (discov.Registry, error) {
+	return kubeRegistry{}, nil
+}
 
-func (b *kubeBuilder) Build(target resolver.Target, cc resolver.ClientConn,
-	_ resolver.BuildOptions) (resolver.Resolver, error) {
-	svc, err := kube.ParseTarget(target)
+func (r kubeRegistry) Deregister(_ string, _ discov.Endpoint) error {
+	return discov.ErrReadOnlyRegistry
+}
+
+func (r kubeRegistry) Register(_ string, _ discov.Endpoint) error {
+	return discov.ErrReadOnlyRegistry
+}
+
+// Watch watches the endpoints of service, like namespace/name:port.
+func (r kubeRegistry) Watch(service string, listener func([]discov.Endpoint)) (func(), error) {
+	svc, err := kube.ParseService(service)
 	if err != nil {
 		return nil, err
 	}
 
+	return watchService(svc, listener)
+}
+
+func watchService(svc kube.Service, listener func([]discov.Endpoint)) (func(), error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		return nil, err
@@ -71,19 +91,13 @@ func (b *kubeBuilder) Build(target resolver.Target, cc resolver.ClientConn,
 	}
 
 	handler := kube.NewEventHandler(func(endpoints []string) {
-		endpoints = subset(endpoints, subsetSize)
-		addrs := make([]resolver.Address, 0, len(endpoints))
+		eps := make([]discov.Endpoint, 0, len(endpoints))
 		for _, val := range endpoints {
-			addrs = append(addrs, resolver.Address{
+			eps = append(eps, discov.Endpoint{
 				Addr: fmt.Sprintf("%s:%d", val, svc.Port),
 			})
 		}
-
-		if err := cc.UpdateState(resolver.State{
-			Addresses: addrs,
-		}); err != nil {
-			logx.Error(err)
-		}
+		listener(eps)
 	})
 	inf := informers.NewSharedInformerFactoryWithOptions(cs, resyncInterval,
 		informers.WithNamespace(svc.Namespace),
@@ -106,16 +120,12 @@ func (b *kubeBuilder) Build(target resolver.Target, cc resolver.ClientConn,
 
 	handler.Update(endpoints)
 
-	r := &kubeResolver{
-		cc:     cc,
-		inf:    inf,
-		stopCh: make(chan struct{}),
-	}
-	r.start()
-
-	return r, nil
-}
+	stopCh := make(chan struct{})
+	threading.GoSafe(func() {
+		inf.Start(stopCh)
+	})
 
-func (b *kubeBuilder) Scheme() string {
-	return KubernetesScheme
+	return func() {
+		close(stopCh)
+	}, nil
 }
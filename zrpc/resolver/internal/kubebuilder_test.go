@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/r27153733/fastgozero/core/discov"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/resolver"
 )
@@ -32,3 +33,16 @@ func TestKubeBuilder_Build(t *testing.T) {
 	}, nil, resolver.BuildOptions{})
 	assert.Error(t, err)
 }
+
+func TestKubeRegistry(t *testing.T) {
+	r, err := newKubeRegistry(nil)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, r.Register("ns/svc:8080", discov.Endpoint{}), discov.ErrReadOnlyRegistry)
+	assert.ErrorIs(t, r.Deregister("ns/svc:8080", discov.Endpoint{}), discov.ErrReadOnlyRegistry)
+
+	_, err = r.Watch("ns/svc:port", func([]discov.Endpoint) {})
+	assert.Error(t, err)
+	// not in cluster
+	_, err = r.Watch("ns/svc:8080", func([]discov.Endpoint) {})
+	assert.Error(t, err)
+}
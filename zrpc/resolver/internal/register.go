@@ -2,7 +2,7 @@
 
 package internal
 
-// RegisterResolver registers the direct, etcd and discov schemes to the resolver.
+// RegisterResolver registers the direct, etcd, discov, split and registry schemes to the resolver.
 func RegisterResolver() {
 	register()
 }
@@ -2,12 +2,17 @@
 
 package internal
 
-import "google.golang.org/grpc/resolver"
+import (
+	"github.com/r27153733/fastgozero/core/discov"
+	"google.golang.org/grpc/resolver"
+)
 
 var k8sResolverBuilder kubeBuilder
 
-// RegisterResolver registers the direct, etcd, discov and k8s schemes to the resolver.
+// RegisterResolver registers the direct, etcd, discov, split, registry and k8s schemes
+// to the resolver, and the k8s scheme to the discov registries.
 func RegisterResolver() {
 	register()
 	resolver.Register(&k8sResolverBuilder)
+	discov.RegisterRegistry(KubernetesScheme, newKubeRegistry)
 }
@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/r27153733/fastgozero/core/discov"
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/zrpc/resolver/internal/targets"
+	"google.golang.org/grpc/resolver"
+)
+
+// RegistryTargetKey is the query key of the registry target in the registry scheme targets,
+// like registry:///user.rpc?target=dns%3A%2F%2F%2F.
+const RegistryTargetKey = "target"
+
+var errEmptyRegistryTarget = errors.New("empty registry target")
+
+type (
+	registryBuilder struct{}
+
+	registryResolver struct {
+		stop func()
+	}
+)
+
+func (b *registryBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (
+	resolver.Resolver, error) {
+	registryTarget := target.URL.Query().Get(RegistryTargetKey)
+	if len(registryTarget) == 0 {
+		return nil, errEmptyRegistryTarget
+	}
+
+	registry, err := discov.NewRegistry(registryTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	service := targets.GetEndpoints(target)
+	return watchEndpoints(cc, func(listener func([]discov.Endpoint)) (func(), error) {
+		return registry.Watch(service, listener)
+	})
+}
+
+func (b *registryBuilder) Scheme() string {
+	return RegistryScheme
+}
+
+func (r *registryResolver) Close() {
+	r.stop()
+}
+
+func (r *registryResolver) ResolveNow(_ resolver.ResolveNowOptions) {
+}
+
+// watchEndpoints updates the state of cc with the endpoints watched by watch.
+func watchEndpoints(cc resolver.ClientConn,
+	watch func(listener func([]discov.Endpoint)) (func(), error)) (resolver.Resolver, error) {
+	stop, err := watch(func(eps []discov.Endpoint) {
+		// subset shuffles in place, don't mess up the ones of the registry.
+		eps = subset(slices.Clone(eps), subsetSize)
+		addrs := make([]resolver.Address, 0, len(eps))
+		for _, ep := range eps {
+			addrs = append(addrs, buildAddress(ep))
+		}
+		if err := cc.UpdateState(resolver.State{
+			Addresses: addrs,
+		}); err != nil {
+			logx.Error(err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &registryResolver{stop: stop}, nil
+}
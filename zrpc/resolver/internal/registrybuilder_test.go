@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/r27153733/fastgozero/core/discov"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestRegistryBuilder_Build(t *testing.T) {
+	var b registryBuilder
+	assert.Equal(t, RegistryScheme, b.Scheme())
+
+	_, err := b.Build(registryTarget(t, "user.rpc", ""), new(mockedClientConn), resolver.BuildOptions{})
+	assert.ErrorIs(t, err, errEmptyRegistryTarget)
+	_, err = b.Build(registryTarget(t, "user.rpc", "unknown:///"), new(mockedClientConn),
+		resolver.BuildOptions{})
+	assert.Error(t, err)
+
+	file := filepath.Join(t.TempDir(), "registry.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"user.rpc":[{"addr":"localhost:8080"},
+{"addr":"localhost:8081","zone":"zone-a"}],"order.rpc":[{"addr":"localhost:9090"}]}`), 0o644))
+	cc := new(mockedClientConn)
+	r, err := b.Build(registryTarget(t, "user.rpc", "file://"+file), cc, resolver.BuildOptions{})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"localhost:8080", "localhost:8081"}, addrsOf(cc.state))
+	assert.NotPanics(t, func() {
+		r.ResolveNow(resolver.ResolveNowOptions{})
+		r.Close()
+	})
+}
+
+func TestWatchEndpoints(t *testing.T) {
+	_, err := watchEndpoints(new(mockedClientConn), func(func([]discov.Endpoint)) (func(), error) {
+		return nil, errors.New("foo")
+	})
+	assert.Error(t, err)
+
+	eps := []discov.Endpoint{
+		{Addr: "localhost:8080"},
+		{Addr: "localhost:8081"},
+	}
+	cc := &mockedClientConn{err: errors.New("foo")}
+	var stopped bool
+	r, err := watchEndpoints(cc, func(listener func([]discov.Endpoint)) (func(), error) {
+		listener(eps)
+		return func() {
+			stopped = true
+		}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"localhost:8080", "localhost:8081"}, addrsOf(cc.state))
+	r.Close()
+	assert.True(t, stopped)
+}
+
+func registryTarget(t *testing.T, service, target string) resolver.Target {
+	uri, err := url.Parse(fmt.Sprintf("%s:///%s?%s=%s", RegistryScheme, service,
+		RegistryTargetKey, url.QueryEscape(target)))
+	assert.NoError(t, err)
+	return resolver.Target{URL: *uri}
+}
@@ -15,6 +15,10 @@ const (
 	EtcdScheme = "etcd"
 	// KubernetesScheme stands for k8s scheme.
 	KubernetesScheme = "k8s"
+	// SplitScheme stands for split scheme.
+	SplitScheme = "split"
+	// RegistryScheme stands for the scheme of the discov.Registry backed targets.
+	RegistryScheme = "registry"
 	// EndpointSepChar is the separator cha in endpoints.
 	EndpointSepChar = ','
 
@@ -25,15 +29,19 @@ var (
 	// EndpointSep is the separator string in endpoints.
 	EndpointSep = fmt.Sprintf("%c", EndpointSepChar)
 
-	directResolverBuilder directBuilder
-	discovResolverBuilder discovBuilder
-	etcdResolverBuilder   etcdBuilder
+	directResolverBuilder   directBuilder
+	discovResolverBuilder   discovBuilder
+	etcdResolverBuilder     etcdBuilder
+	splitResolverBuilder    splitBuilder
+	registryResolverBuilder registryBuilder
 )
 
 func register() {
 	resolver.Register(&directResolverBuilder)
 	resolver.Register(&discovResolverBuilder)
 	resolver.Register(&etcdResolverBuilder)
+	resolver.Register(&splitResolverBuilder)
+	resolver.Register(&registryResolverBuilder)
 }
 
 type nopResolver struct {
@@ -0,0 +1,350 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
+	"github.com/r27153733/fastgozero/zrpc/resolver/internal/targets"
+	"google.golang.org/grpc/resolver"
+)
+
+var (
+	// ErrNoSplitTargets is an error that indicates no targets to split the traffic across.
+	ErrNoSplitTargets = errors.New("no split targets")
+	// ErrZeroSplitWeights is an error that indicates no traffic goes to any split targets.
+	ErrZeroSplitWeights = errors.New("total weight of split targets is 0")
+
+	splitTables = make(map[string]*splitTable)
+	splitLock   sync.Mutex
+)
+
+type (
+	// WeightedTarget is a target with the weight of the traffic that goes to it.
+	WeightedTarget struct {
+		// Target is the target to resolve, like etcd://127.0.0.1:2379/svc-v1.
+		Target string
+		// Weight is the relative weight of the traffic, 0 means no traffic.
+		Weight int `json:",range=[0:]"`
+	}
+
+	splitTable struct {
+		targets   []WeightedTarget
+		resolvers map[*splitResolver]struct{}
+	}
+
+	splitBuilder struct{}
+
+	splitResolver struct {
+		cc       resolver.ClientConn
+		name     string
+		opts     resolver.BuildOptions
+		children map[string]*splitChild
+		// updateLock serializes the updates of the children,
+		// lock guards the children, pushLock keeps the pushed states in order.
+		updateLock sync.Mutex
+		lock       sync.Mutex
+		pushLock   sync.Mutex
+	}
+
+	splitChild struct {
+		target   string
+		weight   int
+		addrs    []resolver.Address
+		resolver resolver.Resolver
+	}
+
+	splitClientConn struct {
+		resolver.ClientConn
+		parent *splitResolver
+		child  *splitChild
+	}
+)
+
+// RegisterSplitTargets sets the targets of the split target with the given name
+// if it's not registered yet, the registered targets are kept.
+func RegisterSplitTargets(name string, targets []WeightedTarget) error {
+	if err := validateSplitTargets(targets); err != nil {
+		return err
+	}
+
+	splitLock.Lock()
+	defer splitLock.Unlock()
+
+	if _, ok := splitTables[name]; !ok {
+		splitTables[name] = &splitTable{
+			targets:   append([]WeightedTarget(nil), targets...),
+			resolvers: make(map[*splitResolver]struct{}),
+		}
+	}
+
+	return nil
+}
+
+// UpdateSplitTargets sets the targets that the traffic of the split target with
+// the given name is split across, the resolvers built on it are updated accordingly.
+func UpdateSplitTargets(name string, targets []WeightedTarget) error {
+	if err := validateSplitTargets(targets); err != nil {
+		return err
+	}
+
+	splitLock.Lock()
+	table, ok := splitTables[name]
+	if !ok {
+		table = &splitTable{
+			resolvers: make(map[*splitResolver]struct{}),
+		}
+		splitTables[name] = table
+	}
+	table.targets = append([]WeightedTarget(nil), targets...)
+	resolvers := make([]*splitResolver, 0, len(table.resolvers))
+	for r := range table.resolvers {
+		resolvers = append(resolvers, r)
+	}
+	splitLock.Unlock()
+
+	for _, r := range resolvers {
+		if err := r.update(); err != nil {
+			logx.Errorf("split target %q: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateSplitTargets(targets []WeightedTarget) error {
+	if len(targets) == 0 {
+		return ErrNoSplitTargets
+	}
+
+	var total int
+	for _, target := range targets {
+		if _, err := parseSplitTarget(target.Target); err != nil {
+			return err
+		}
+		if target.Weight < 0 {
+			return fmt.Errorf("negative weight %d of split target %q", target.Weight, target.Target)
+		}
+		total += target.Weight
+	}
+	if total == 0 {
+		return ErrZeroSplitWeights
+	}
+
+	return nil
+}
+
+func (b *splitBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (
+	resolver.Resolver, error) {
+	name := targets.GetEndpoints(target)
+	r := &splitResolver{
+		cc:       cc,
+		name:     name,
+		opts:     opts,
+		children: make(map[string]*splitChild),
+	}
+
+	splitLock.Lock()
+	table, ok := splitTables[name]
+	if !ok {
+		splitLock.Unlock()
+		return nil, fmt.Errorf("split target %q not found", name)
+	}
+	table.resolvers[r] = struct{}{}
+	splitLock.Unlock()
+
+	if err := r.update(); err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (b *splitBuilder) Scheme() string {
+	return SplitScheme
+}
+
+// Close closes the resolver, the split target is removed with its last resolver.
+func (r *splitResolver) Close() {
+	splitLock.Lock()
+	if table, ok := splitTables[r.name]; ok {
+		delete(table.resolvers, r)
+		if len(table.resolvers) == 0 {
+			delete(splitTables, r.name)
+		}
+	}
+	splitLock.Unlock()
+
+	r.updateLock.Lock()
+	defer r.updateLock.Unlock()
+
+	r.lock.Lock()
+	children := r.children
+	r.children = make(map[string]*splitChild)
+	r.lock.Unlock()
+
+	for _, child := range children {
+		child.resolver.Close()
+	}
+}
+
+func (r *splitResolver) ResolveNow(opts resolver.ResolveNowOptions) {
+	r.lock.Lock()
+	children := r.children
+	r.lock.Unlock()
+
+	// the children might update the states in ResolveNow, so no r.lock held.
+	for _, child := range children {
+		child.resolver.ResolveNow(opts)
+	}
+}
+
+// build builds the resolver of the child, should be called without holding r.lock,
+// because the child resolvers might update the states in Build.
+func (r *splitResolver) build(child *splitChild) error {
+	u, err := parseSplitTarget(child.target)
+	if err != nil {
+		return err
+	}
+
+	builder := resolver.Get(u.Scheme)
+	if builder == nil {
+		return fmt.Errorf("resolver of scheme %q not registered", u.Scheme)
+	}
+
+	child.resolver, err = builder.Build(resolver.Target{URL: *u}, &splitClientConn{
+		ClientConn: r.cc,
+		parent:     r,
+		child:      child,
+	}, r.opts)
+	return err
+}
+
+func (r *splitResolver) push() {
+	r.pushLock.Lock()
+	defer r.pushLock.Unlock()
+
+	var addrs []resolver.Address
+	weights := make(endpoint.GroupWeights)
+	r.lock.Lock()
+	for _, child := range r.children {
+		weights[child.target] = child.weight
+		for _, addr := range child.addrs {
+			addrs = append(addrs, endpoint.WithGroup(addr, child.target))
+		}
+	}
+	r.lock.Unlock()
+
+	if err := r.cc.UpdateState(endpoint.WithGroupWeights(resolver.State{
+		Addresses: addrs,
+	}, weights)); err != nil {
+		logx.Errorf("split target %q: %v", r.name, err)
+	}
+}
+
+// update updates the children with the latest targets of the split table,
+// reading the latest ones keeps the concurrent updates in order.
+func (r *splitResolver) update() error {
+	r.updateLock.Lock()
+	defer r.updateLock.Unlock()
+
+	splitLock.Lock()
+	var weightedTargets []WeightedTarget
+	if table, ok := splitTables[r.name]; ok {
+		weightedTargets = table.targets
+	}
+	splitLock.Unlock()
+
+	r.lock.Lock()
+	existing := r.children
+	r.lock.Unlock()
+
+	children := make(map[string]*splitChild, len(weightedTargets))
+	for _, target := range weightedTargets {
+		// weights of the same targets are merged
+		if child, ok := children[target.Target]; ok {
+			r.lock.Lock()
+			child.weight += target.Weight
+			r.lock.Unlock()
+			continue
+		}
+
+		if child, ok := existing[target.Target]; ok {
+			r.lock.Lock()
+			child.weight = target.Weight
+			r.lock.Unlock()
+			children[target.Target] = child
+			continue
+		}
+
+		child := &splitChild{
+			target: target.Target,
+			weight: target.Weight,
+		}
+		if err := r.build(child); err != nil {
+			for _, c := range children {
+				if _, ok := existing[c.target]; !ok {
+					c.resolver.Close()
+				}
+			}
+			return err
+		}
+		children[target.Target] = child
+	}
+
+	r.lock.Lock()
+	r.children = children
+	r.lock.Unlock()
+
+	for target, child := range existing {
+		if _, ok := children[target]; !ok {
+			child.resolver.Close()
+		}
+	}
+	r.push()
+
+	return nil
+}
+
+func (c *splitClientConn) NewAddress(addrs []resolver.Address) {
+	_ = c.UpdateState(resolver.State{
+		Addresses: addrs,
+	})
+}
+
+func (c *splitClientConn) ReportError(err error) {
+	// the other targets might be fine, don't fail the whole split target
+	logx.Errorf("split target %q, resolve %q: %v", c.parent.name, c.child.target, err)
+}
+
+func (c *splitClientConn) UpdateState(state resolver.State) error {
+	c.parent.lock.Lock()
+	c.child.addrs = state.Addresses
+	// the children being built are pushed after built, the closed ones are ignored.
+	current := c.parent.children[c.child.target] == c.child
+	c.parent.lock.Unlock()
+	if current {
+		c.parent.push()
+	}
+
+	return nil
+}
+
+func parseSplitTarget(target string) (*url.URL, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if len(u.Scheme) == 0 {
+		return nil, fmt.Errorf("split target %q without scheme", target)
+	}
+	if u.Scheme == SplitScheme {
+		return nil, fmt.Errorf("split target %q can't be nested", target)
+	}
+
+	return u, nil
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"net/url"
+	"sort"
+	"testing"
+
+	"github.com/r27153733/fastgozero/zrpc/internal/balancer/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestUpdateSplitTargets(t *testing.T) {
+	assert.ErrorIs(t, UpdateSplitTargets("foo", nil), ErrNoSplitTargets)
+	assert.Error(t, UpdateSplitTargets("foo", []WeightedTarget{
+		{Target: "localhost", Weight: 1},
+	}))
+	assert.Error(t, UpdateSplitTargets("foo", []WeightedTarget{
+		{Target: "split:///bar", Weight: 1},
+	}))
+	assert.Error(t, UpdateSplitTargets("foo", []WeightedTarget{
+		{Target: "direct:///localhost:8080", Weight: -1},
+	}))
+	assert.Error(t, UpdateSplitTargets("foo", []WeightedTarget{
+		{Target: ":bad", Weight: 1},
+	}))
+	assert.ErrorIs(t, UpdateSplitTargets("foo", []WeightedTarget{
+		{Target: "direct:///localhost:8080", Weight: 0},
+		{Target: "direct:///localhost:8081", Weight: 0},
+	}), ErrZeroSplitWeights)
+}
+
+func TestRegisterSplitTargets(t *testing.T) {
+	assert.ErrorIs(t, RegisterSplitTargets("register", nil), ErrNoSplitTargets)
+
+	assert.NoError(t, UpdateSplitTargets("register", []WeightedTarget{
+		{Target: "direct:///localhost:8081", Weight: 10},
+	}))
+	// the registered targets are kept.
+	assert.NoError(t, RegisterSplitTargets("register", []WeightedTarget{
+		{Target: "direct:///localhost:8082", Weight: 90},
+	}))
+	splitLock.Lock()
+	assert.Equal(t, []WeightedTarget{
+		{Target: "direct:///localhost:8081", Weight: 10},
+	}, splitTables["register"].targets)
+	delete(splitTables, "register")
+	splitLock.Unlock()
+
+	assert.NoError(t, RegisterSplitTargets("register", []WeightedTarget{
+		{Target: "direct:///localhost:8082", Weight: 90},
+	}))
+	splitLock.Lock()
+	assert.Equal(t, []WeightedTarget{
+		{Target: "direct:///localhost:8082", Weight: 90},
+	}, splitTables["register"].targets)
+	delete(splitTables, "register")
+	splitLock.Unlock()
+}
+
+func TestSplitBuilder_Build(t *testing.T) {
+	RegisterResolver()
+
+	var b splitBuilder
+	assert.Equal(t, SplitScheme, b.Scheme())
+	_, err := b.Build(splitTarget(t, "split-not-found"), new(mockedClientConn), resolver.BuildOptions{})
+	assert.Error(t, err)
+
+	assert.NoError(t, UpdateSplitTargets("split-unknown-scheme", []WeightedTarget{
+		{Target: "unknown:///localhost:8080", Weight: 1},
+	}))
+	_, err = b.Build(splitTarget(t, "split-unknown-scheme"), new(mockedClientConn), resolver.BuildOptions{})
+	assert.Error(t, err)
+
+	const (
+		v1 = "direct:///localhost:8081,localhost:8082"
+		v2 = "direct:///localhost:8083"
+		v3 = "direct:///localhost:8084"
+	)
+	assert.NoError(t, UpdateSplitTargets("split", []WeightedTarget{
+		{Target: v1, Weight: 90},
+		{Target: v2, Weight: 10},
+	}))
+	cc := new(mockedClientConn)
+	r, err := b.Build(splitTarget(t, "split"), cc, resolver.BuildOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"localhost:8081": v1,
+		"localhost:8082": v1,
+		"localhost:8083": v2,
+	}, groupsOf(cc.state))
+	weights, ok := endpoint.GroupWeightsFromState(cc.state)
+	assert.True(t, ok)
+	assert.Equal(t, endpoint.GroupWeights{v1: 90, v2: 10}, weights)
+	assert.NotPanics(t, func() {
+		r.ResolveNow(resolver.ResolveNowOptions{})
+	})
+
+	// the same targets are merged
+	assert.NoError(t, UpdateSplitTargets("split", []WeightedTarget{
+		{Target: v1, Weight: 10},
+		{Target: v2, Weight: 80},
+		{Target: v2, Weight: 10},
+	}))
+	weights, _ = endpoint.GroupWeightsFromState(cc.state)
+	assert.Equal(t, endpoint.GroupWeights{v1: 10, v2: 90}, weights)
+
+	assert.NoError(t, UpdateSplitTargets("split", []WeightedTarget{
+		{Target: v2, Weight: 50},
+		{Target: v3, Weight: 50},
+	}))
+	assert.Equal(t, map[string]string{
+		"localhost:8083": v2,
+		"localhost:8084": v3,
+	}, groupsOf(cc.state))
+	weights, _ = endpoint.GroupWeightsFromState(cc.state)
+	assert.Equal(t, endpoint.GroupWeights{v2: 50, v3: 50}, weights)
+
+	// closed resolvers are not updated anymore, and the split target is removed with the last one
+	r.Close()
+	splitLock.Lock()
+	_, ok = splitTables["split"]
+	splitLock.Unlock()
+	assert.False(t, ok)
+	assert.NoError(t, UpdateSplitTargets("split", []WeightedTarget{
+		{Target: v1, Weight: 100},
+	}))
+	weights, _ = endpoint.GroupWeightsFromState(cc.state)
+	assert.Equal(t, endpoint.GroupWeights{v2: 50, v3: 50}, weights)
+}
+
+func TestSplitClientConn(t *testing.T) {
+	cc := new(mockedClientConn)
+	child := &splitChild{
+		target: "direct:///localhost:8081",
+		weight: 1,
+	}
+	parent := &splitResolver{
+		cc:   cc,
+		name: "split",
+		children: map[string]*splitChild{
+			child.target: child,
+		},
+	}
+	conn := &splitClientConn{
+		ClientConn: cc,
+		parent:     parent,
+		child:      child,
+	}
+
+	conn.NewAddress([]resolver.Address{{Addr: "localhost:8081"}})
+	assert.Equal(t, map[string]string{
+		"localhost:8081": child.target,
+	}, groupsOf(cc.state))
+	assert.NotPanics(t, func() {
+		conn.ReportError(assert.AnError)
+	})
+
+	// the stale children don't push
+	parent.children = map[string]*splitChild{}
+	assert.NoError(t, conn.UpdateState(resolver.State{
+		Addresses: []resolver.Address{{Addr: "localhost:8082"}},
+	}))
+	assert.Equal(t, []string{"localhost:8081"}, addrsOf(cc.state))
+}
+
+func addrsOf(state resolver.State) []string {
+	var addrs []string
+	for _, addr := range state.Addresses {
+		addrs = append(addrs, addr.Addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+func groupsOf(state resolver.State) map[string]string {
+	groups := make(map[string]string)
+	for _, addr := range state.Addresses {
+		group, _ := endpoint.GroupFromAddress(addr)
+		groups[addr.Addr] = group
+	}
+	return groups
+}
+
+func splitTarget(t *testing.T, name string) resolver.Target {
+	uri, err := url.Parse(SplitScheme + ":///" + name)
+	assert.NoError(t, err)
+	return resolver.Target{URL: *uri}
+}
@@ -2,7 +2,7 @@ package internal
 
 import "math/rand"
 
-func subset(set []string, sub int) []string {
+func subset[T any](set []T, sub int) []T {
 	rand.Shuffle(len(set), func(i, j int) {
 		set[i], set[j] = set[j], set[i]
 	})
@@ -0,0 +1,89 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	configurator "github.com/r27153733/fastgozero/core/configcenter"
+	"github.com/r27153733/fastgozero/core/hash"
+	"github.com/r27153733/fastgozero/core/logx"
+	"github.com/r27153733/fastgozero/zrpc/resolver/internal"
+)
+
+type (
+	// SplitConf is the config to split the traffic across the targets by weight,
+	// like 90% to etcd://127.0.0.1:2379/svc-v1 and 10% to etcd://127.0.0.1:2379/svc-v2.
+	SplitConf struct {
+		// Name is the name of the split target, generated from the targets if empty.
+		// Use the same name to update the targets, like from the config center.
+		Name    string `json:",optional"`
+		Targets []WeightedTarget
+	}
+
+	// WeightedTarget is a target with the weight of the traffic that goes to it.
+	WeightedTarget = internal.WeightedTarget
+)
+
+// BuildSplitTarget registers the targets of c and returns the split target
+// that splits the traffic across them. The targets are registered only if the name
+// is not known yet, the ones set by UpdateSplitTargets or WatchSplitTargets are kept.
+func BuildSplitTarget(c SplitConf) (string, error) {
+	name := c.Name
+	if len(name) == 0 {
+		name = splitName(c.Targets)
+	}
+
+	if err := internal.RegisterSplitTargets(name, c.Targets); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:///%s", internal.SplitScheme, name), nil
+}
+
+// IsSplitTarget returns true if target is built by BuildSplitTarget.
+func IsSplitTarget(target string) bool {
+	return strings.HasPrefix(target, internal.SplitScheme+"://")
+}
+
+// UpdateSplitTargets updates the targets of the split target with the given name,
+// the clients on it split the traffic by the new weights without reconnecting
+// to the endpoints of the unchanged targets.
+func UpdateSplitTargets(name string, targets []WeightedTarget) error {
+	return internal.UpdateSplitTargets(name, targets)
+}
+
+// WatchSplitTargets updates the targets of the split target with the given name
+// from the configurator, and keeps them updated on changes.
+func WatchSplitTargets(name string, c configurator.Configurator[SplitConf]) error {
+	update := func() error {
+		conf, err := c.GetConfig()
+		if err != nil {
+			return err
+		}
+
+		return UpdateSplitTargets(name, conf.Targets)
+	}
+
+	if err := update(); err != nil {
+		return err
+	}
+
+	c.AddListener(func() {
+		if err := update(); err != nil {
+			logx.Errorf("failed to update split target %q: %v", name, err)
+		}
+	})
+
+	return nil
+}
+
+// splitName returns the name of the targets with their weights,
+// the clients with different weights don't share the same split target.
+func splitName(targets []WeightedTarget) string {
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, fmt.Sprintf("%s=%d", target.Target, target.Weight))
+	}
+
+	return hash.Md5Hex([]byte(strings.Join(names, internal.EndpointSep)))
+}
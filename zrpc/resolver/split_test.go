@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSplitTarget(t *testing.T) {
+	target, err := BuildSplitTarget(SplitConf{
+		Name: "foo",
+		Targets: []WeightedTarget{
+			{Target: "direct:///localhost:123", Weight: 90},
+			{Target: "direct:///localhost:456", Weight: 10},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "split:///foo", target)
+
+	targets := []WeightedTarget{
+		{Target: "direct:///localhost:123", Weight: 50},
+		{Target: "direct:///localhost:456", Weight: 50},
+	}
+	target, err = BuildSplitTarget(SplitConf{Targets: targets})
+	assert.NoError(t, err)
+	assert.Equal(t, "split:///"+splitName(targets), target)
+	assert.True(t, IsSplitTarget(target))
+	assert.False(t, IsSplitTarget("direct:///localhost:123"))
+
+	// the same targets with different weights are different split targets
+	assert.NotEqual(t, splitName(targets), splitName([]WeightedTarget{
+		{Target: "direct:///localhost:123", Weight: 90},
+		{Target: "direct:///localhost:456", Weight: 10},
+	}))
+
+	_, err = BuildSplitTarget(SplitConf{Name: "foo"})
+	assert.Error(t, err)
+}
+
+func TestWatchSplitTargets(t *testing.T) {
+	c := &mockedConfigurator{
+		conf: SplitConf{
+			Targets: []WeightedTarget{
+				{Target: "direct:///localhost:123", Weight: 1},
+			},
+		},
+	}
+	assert.NoError(t, WatchSplitTargets("bar", c))
+	assert.NotNil(t, c.listener)
+	c.conf.Targets = nil
+	assert.NotPanics(t, c.listener)
+
+	c = &mockedConfigurator{
+		err: errors.New("foo"),
+	}
+	assert.Error(t, WatchSplitTargets("bar", c))
+	assert.Nil(t, c.listener)
+}
+
+type mockedConfigurator struct {
+	conf     SplitConf
+	err      error
+	listener func()
+}
+
+func (m *mockedConfigurator) GetConfig() (SplitConf, error) {
+	return m.conf, m.err
+}
+
+func (m *mockedConfigurator) AddListener(listener func()) {
+	m.listener = listener
+}
@@ -2,8 +2,10 @@ package resolver
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 
+	"github.com/r27153733/fastgozero/core/discov"
 	"github.com/r27153733/fastgozero/zrpc/resolver/internal"
 )
 
@@ -18,3 +20,9 @@ func BuildDiscovTarget(endpoints []string, key string) string {
 	return fmt.Sprintf("%s://%s/%s", internal.EtcdScheme,
 		strings.Join(endpoints, internal.EndpointSep), key)
 }
+
+// BuildRegistryTarget returns a string that represents the service in the registry of c.
+func BuildRegistryTarget(c discov.RegistryConf) string {
+	return fmt.Sprintf("%s:///%s?%s=%s", internal.RegistryScheme, c.Service,
+		internal.RegistryTargetKey, url.QueryEscape(c.Target))
+}
@@ -3,6 +3,7 @@ package resolver
 import (
 	"testing"
 
+	"github.com/r27153733/fastgozero/core/discov"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,3 +16,11 @@ func TestBuildDiscovTarget(t *testing.T) {
 	target := BuildDiscovTarget([]string{"localhost:123", "localhost:456"}, "foo")
 	assert.Equal(t, "etcd://localhost:123,localhost:456/foo", target)
 }
+
+func TestBuildRegistryTarget(t *testing.T) {
+	target := BuildRegistryTarget(discov.RegistryConf{
+		Target:  "dns://8.8.8.8:53?ttl=10s",
+		Service: "svc.example.com:8080",
+	})
+	assert.Equal(t, "registry:///svc.example.com:8080?target=dns%3A%2F%2F8.8.8.8%3A53%3Fttl%3D10s", target)
+}
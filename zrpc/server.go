@@ -1,8 +1,10 @@
 package zrpc
 
 import (
+	"context"
 	"time"
 
+	"github.com/r27153733/fastgozero/core/discov"
 	"github.com/r27153733/fastgozero/core/load"
 	"github.com/r27153733/fastgozero/core/logx"
 	"github.com/r27153733/fastgozero/core/stat"
@@ -10,13 +12,30 @@ import (
 	"github.com/r27153733/fastgozero/zrpc/internal"
 	"github.com/r27153733/fastgozero/zrpc/internal/auth"
 	"github.com/r27153733/fastgozero/zrpc/internal/serverinterceptors"
+	"github.com/r27153733/fastgozero/zrpc/internal/tlsx"
 	"google.golang.org/grpc"
 )
 
-// A RpcServer is a rpc server.
-type RpcServer struct {
-	server   internal.Server
-	register internal.RegisterFn
+type (
+	// An Authenticator authenticates the rpc requests, the returned context is passed
+	// to the handlers, which can carry the authenticated identity.
+	Authenticator = auth.Authenticator
+
+	// A PeerIdentity is the identity of the peer, from its verified TLS certificate.
+	PeerIdentity = tlsx.PeerIdentity
+
+	// A RpcServer is a rpc server.
+	RpcServer struct {
+		server   internal.Server
+		register internal.RegisterFn
+		policy   *auth.Policy
+	}
+)
+
+// PeerIdentityFromContext returns the identity of the peer in ctx, only the
+// certificates verified by Tls.CaFile are taken into account.
+func PeerIdentityFromContext(ctx context.Context) (PeerIdentity, bool) {
+	return tlsx.PeerIdentityFromContext(ctx)
 }
 
 // MustNewServer returns a RpcSever, exits on any error.
@@ -40,25 +59,49 @@ func NewServer(c RpcServerConf, register internal.RegisterFn) (*RpcServer, error
 	}
 
 	if c.HasEtcd() {
-		server, err = internal.NewRpcPubServer(c.Etcd, c.ListenOn, serverOptions...)
+		server, err = internal.NewRpcPubServer(c.Etcd, c.ListenOn, c.Metadata, serverOptions...)
+		if err != nil {
+			return nil, err
+		}
+	} else if c.HasRegistry() {
+		registry, err := discov.NewRegistry(c.Registry.Target)
 		if err != nil {
 			return nil, err
 		}
+
+		server = internal.NewRpcRegistryServer(registry, c.Registry.Service, c.ListenOn,
+			c.Metadata, serverOptions...)
 	} else {
 		server = internal.NewRpcServer(c.ListenOn, serverOptions...)
 	}
 
+	if c.Tls.Enabled() {
+		creds, err := tlsx.NewServerCredentials(c.Tls)
+		if err != nil {
+			return nil, err
+		}
+
+		server.AddOptions(grpc.Creds(creds))
+	}
+
+	if opts := c.serverOptions(); len(opts) > 0 {
+		server.AddOptions(opts...)
+	}
+
 	server.SetName(c.Name)
 	metrics.SetName(c.Name)
-	setupStreamInterceptors(server, c)
-	setupUnaryInterceptors(server, c, metrics)
-	if err = setupAuthInterceptors(server, c); err != nil {
+	shedder := newShedder(c)
+	setupStreamInterceptors(server, c, metrics, shedder)
+	setupUnaryInterceptors(server, c, metrics, shedder)
+	policy, err := setupAuthInterceptors(server, c)
+	if err != nil {
 		return nil, err
 	}
 
 	rpcServer := &RpcServer{
 		server:   server,
-		register: register,
+		register: withIntrospection(c, register),
+		policy:   policy,
 	}
 	if err = c.SetUp(); err != nil {
 		return nil, err
@@ -67,6 +110,17 @@ func NewServer(c RpcServerConf, register internal.RegisterFn) (*RpcServer, error
 	return rpcServer, nil
 }
 
+// AddAuthenticator adds the authenticator with the given name, which can be used
+// in AuthPolicy rules, only works if AuthPolicy is configured.
+func (rs *RpcServer) AddAuthenticator(name string, authenticator Authenticator) {
+	if rs.policy == nil {
+		logx.Errorf("AuthPolicy is not configured, authenticator %q is ignored", name)
+		return
+	}
+
+	rs.policy.AddAuthenticator(name, authenticator)
+}
+
 // AddOptions adds given options.
 func (rs *RpcServer) AddOptions(options ...grpc.ServerOption) {
 	rs.server.AddOptions(options...)
@@ -109,39 +163,102 @@ func SetServerSlowThreshold(threshold time.Duration) {
 	serverinterceptors.SetSlowThreshold(threshold)
 }
 
-func setupAuthInterceptors(svr internal.Server, c RpcServerConf) error {
-	if !c.Auth {
-		return nil
+func setupAuthInterceptors(svr internal.Server, c RpcServerConf) (*auth.Policy, error) {
+	var appToken auth.Authenticator
+	if c.Auth && !c.authByPeer() || c.AuthPolicy.Enabled() && len(c.Redis.Host) > 0 {
+		rds, err := redis.NewRedis(c.Redis.RedisConf)
+		if err != nil {
+			return nil, err
+		}
+
+		appToken, err = auth.NewRedisAuthenticator(rds, c.Redis.Key, c.StrictControl)
+		if err != nil {
+			return nil, err
+		}
 	}
-	rds, err := redis.NewRedis(c.Redis.RedisConf)
-	if err != nil {
-		return err
+
+	if c.Auth {
+		authenticator := appToken
+		if c.authByPeer() {
+			authenticator = auth.NewPeerAuthenticator()
+		}
+		svr.AddStreamInterceptors(serverinterceptors.StreamAuthorizeInterceptor(authenticator))
+		svr.AddUnaryInterceptors(serverinterceptors.UnaryAuthorizeInterceptor(authenticator))
 	}
 
-	authenticator, err := auth.NewAuthenticator(rds, c.Redis.Key, c.StrictControl)
-	if err != nil {
-		return err
+	if !c.AuthPolicy.Enabled() {
+		return nil, nil
+	}
+
+	policy := auth.NewPolicy(c.AuthPolicy.Default, c.AuthPolicy.Rules)
+	policy.AddAuthenticator(auth.MtlsName, auth.NewPeerAuthenticator())
+	if appToken != nil {
+		policy.AddAuthenticator(auth.AppTokenName, appToken)
+	}
+	if len(c.AuthPolicy.Jwt.Secret) > 0 {
+		policy.AddAuthenticator(auth.JwtName,
+			auth.NewJwtAuthenticator(c.AuthPolicy.Jwt.Secret, c.AuthPolicy.Jwt.PrevSecret))
+	}
+	if len(c.AuthPolicy.ApiKeys) > 0 {
+		policy.AddAuthenticator(auth.ApiKeyName, auth.NewApiKeyAuthenticator(c.AuthPolicy.ApiKeys))
+	}
+	svr.AddStreamInterceptors(serverinterceptors.StreamAuthPolicyInterceptor(policy))
+	svr.AddUnaryInterceptors(serverinterceptors.UnaryAuthPolicyInterceptor(policy))
+
+	return policy, nil
+}
+
+func newShedder(c RpcServerConf) load.Shedder {
+	if c.CpuThreshold <= 0 {
+		return nil
 	}
 
-	svr.AddStreamInterceptors(serverinterceptors.StreamAuthorizeInterceptor(authenticator))
-	svr.AddUnaryInterceptors(serverinterceptors.UnaryAuthorizeInterceptor(authenticator))
+	return load.NewAdaptiveShedder(load.WithCpuThreshold(c.CpuThreshold))
+}
+
+func withIntrospection(c RpcServerConf, register internal.RegisterFn) internal.RegisterFn {
+	if !c.Introspection.Enabled(c.Mode) {
+		return register
+	}
 
-	return nil
+	return func(server *grpc.Server) {
+		register(server)
+		if err := internal.RegisterIntrospection(server, c.Introspection, c.Mode); err != nil {
+			logx.Errorf("failed to register introspection services, error: %v", err)
+		}
+	}
 }
 
-func setupStreamInterceptors(svr internal.Server, c RpcServerConf) {
+func setupStreamInterceptors(svr internal.Server, c RpcServerConf, metrics *stat.Metrics,
+	shedder load.Shedder) {
 	if c.Middlewares.Trace {
 		svr.AddStreamInterceptors(serverinterceptors.StreamTracingInterceptor)
 	}
 	if c.Middlewares.Recover {
 		svr.AddStreamInterceptors(serverinterceptors.StreamRecoverInterceptor)
 	}
+	if c.Middlewares.Stat {
+		svr.AddStreamInterceptors(serverinterceptors.StreamStatInterceptor)
+	}
+	if c.Middlewares.Prometheus {
+		svr.AddStreamInterceptors(serverinterceptors.StreamPrometheusInterceptor)
+	}
 	if c.Middlewares.Breaker {
 		svr.AddStreamInterceptors(serverinterceptors.StreamBreakerInterceptor)
 	}
+	if c.Middlewares.RouteTag {
+		svr.AddStreamInterceptors(serverinterceptors.StreamRouteTagInterceptor)
+	}
+	if shedder != nil && c.Middlewares.StreamShedding {
+		svr.AddStreamInterceptors(serverinterceptors.StreamSheddingInterceptor(shedder, metrics))
+	}
+	if c.Middlewares.MaxStreamLifetime > 0 {
+		svr.AddStreamInterceptors(serverinterceptors.StreamTimeoutInterceptor(c.Middlewares.MaxStreamLifetime))
+	}
 }
 
-func setupUnaryInterceptors(svr internal.Server, c RpcServerConf, metrics *stat.Metrics) {
+func setupUnaryInterceptors(svr internal.Server, c RpcServerConf, metrics *stat.Metrics,
+	shedder load.Shedder) {
 	if c.Middlewares.Trace {
 		svr.AddUnaryInterceptors(serverinterceptors.UnaryTracingInterceptor)
 	}
@@ -157,8 +274,10 @@ func setupUnaryInterceptors(svr internal.Server, c RpcServerConf, metrics *stat.
 	if c.Middlewares.Breaker {
 		svr.AddUnaryInterceptors(serverinterceptors.UnaryBreakerInterceptor)
 	}
-	if c.CpuThreshold > 0 {
-		shedder := load.NewAdaptiveShedder(load.WithCpuThreshold(c.CpuThreshold))
+	if c.Middlewares.RouteTag {
+		svr.AddUnaryInterceptors(serverinterceptors.UnaryRouteTagInterceptor)
+	}
+	if shedder != nil {
 		svr.AddUnaryInterceptors(serverinterceptors.UnarySheddingInterceptor(shedder, metrics))
 	}
 	if c.Timeout > 0 {
@@ -2,16 +2,21 @@ package zrpc
 
 import (
 	"context"
+	"net"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/r27153733/fastgozero/core/conf"
 	"github.com/r27153733/fastgozero/core/discov"
 	"github.com/r27153733/fastgozero/core/logx"
 	"github.com/r27153733/fastgozero/core/service"
 	"github.com/r27153733/fastgozero/core/stat"
 	"github.com/r27153733/fastgozero/core/stores/redis"
+	"github.com/r27153733/fastgozero/internal/mock"
 	"github.com/r27153733/fastgozero/zrpc/internal"
+	"github.com/r27153733/fastgozero/zrpc/internal/auth"
 	"github.com/r27153733/fastgozero/zrpc/internal/serverinterceptors"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
@@ -114,6 +119,58 @@ func TestServer_HasEtcd(t *testing.T) {
 	svr.Stop()
 }
 
+func TestServer_Registry(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	listenOn := listener.Addr().String()
+	assert.NoError(t, listener.Close())
+
+	registry := RegistryConf{
+		Target:  "file://" + filepath.Join(t.TempDir(), "registry.json"),
+		Service: "deposit.rpc",
+	}
+	var sc RpcServerConf
+	assert.NoError(t, conf.FillDefault(&sc))
+	sc.ListenOn = listenOn
+	sc.Registry = registry
+	svr, err := NewServer(sc, func(server *grpc.Server) {
+		mock.RegisterDepositServiceServer(server, &mock.DepositServer{})
+	})
+	assert.NoError(t, err)
+	go svr.Start()
+	defer svr.Stop()
+
+	r, err := discov.NewRegistry(registry.Target)
+	assert.NoError(t, err)
+	registered := make(chan struct{})
+	stop, err := r.Watch(registry.Service, func(eps []discov.Endpoint) {
+		if len(eps) > 0 {
+			close(registered)
+		}
+	})
+	assert.NoError(t, err)
+	defer stop()
+	<-registered
+
+	var cc RpcClientConf
+	assert.NoError(t, conf.FillDefault(&cc))
+	cc.Registry = registry
+	cli, err := NewClient(cc)
+	assert.NoError(t, err)
+	resp, err := mock.NewDepositServiceClient(cli.Conn()).Deposit(context.Background(),
+		&mock.DepositRequest{Amount: 1})
+	assert.NoError(t, err)
+	assert.True(t, resp.GetOk())
+
+	sc.Registry.Service = ""
+	_, err = NewServer(sc, nil)
+	assert.Error(t, err)
+	sc.Registry.Service = "deposit.rpc"
+	sc.Registry.Target = "unknown:///"
+	_, err = NewServer(sc, nil)
+	assert.Error(t, err)
+}
+
 func TestServer_StartFailed(t *testing.T) {
 	svr := MustNewServer(RpcServerConf{
 		ServiceConf: service.ServiceConf{
@@ -218,7 +275,7 @@ func Test_setupUnaryInterceptors(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			metrics := stat.NewMetrics("abc")
-			setupUnaryInterceptors(test.r, test.conf, metrics)
+			setupUnaryInterceptors(test.r, test.conf, metrics, newShedder(test.conf))
 			assert.Equal(t, test.len, len(test.r.unaryInterceptors))
 		})
 	}
@@ -259,13 +316,31 @@ func Test_setupStreamInterceptors(t *testing.T) {
 					Breaker:    true,
 				},
 			},
-			len: 3,
+			len: 5,
+		},
+		{
+			name: "internal middleware",
+			r:    &mockedServer{},
+			conf: RpcServerConf{
+				CpuThreshold: 900,
+				Middlewares: ServerMiddlewaresConf{
+					Trace:             true,
+					Recover:           true,
+					Stat:              true,
+					Prometheus:        true,
+					Breaker:           true,
+					StreamShedding:    true,
+					MaxStreamLifetime: time.Hour,
+				},
+			},
+			len: 7,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			setupStreamInterceptors(test.r, test.conf)
+			metrics := stat.NewMetrics("abc")
+			setupStreamInterceptors(test.r, test.conf, metrics, newShedder(test.conf))
 			assert.Equal(t, test.len, len(test.r.streamInterceptors))
 		})
 	}
@@ -274,7 +349,7 @@ func Test_setupStreamInterceptors(t *testing.T) {
 func Test_setupAuthInterceptors(t *testing.T) {
 	t.Run("no need set auth", func(t *testing.T) {
 		s := &mockedServer{}
-		err := setupAuthInterceptors(s, RpcServerConf{
+		_, err := setupAuthInterceptors(s, RpcServerConf{
 			Auth:  false,
 			Redis: redis.RedisKeyConf{},
 		})
@@ -283,7 +358,7 @@ func Test_setupAuthInterceptors(t *testing.T) {
 
 	t.Run("redis error", func(t *testing.T) {
 		s := &mockedServer{}
-		err := setupAuthInterceptors(s, RpcServerConf{
+		_, err := setupAuthInterceptors(s, RpcServerConf{
 			Auth:  true,
 			Redis: redis.RedisKeyConf{},
 		})
@@ -293,7 +368,7 @@ func Test_setupAuthInterceptors(t *testing.T) {
 	t.Run("works", func(t *testing.T) {
 		rds := miniredis.RunT(t)
 		s := &mockedServer{}
-		err := setupAuthInterceptors(s, RpcServerConf{
+		_, err := setupAuthInterceptors(s, RpcServerConf{
 			Auth: true,
 			Redis: redis.RedisKeyConf{
 				RedisConf: redis.RedisConf{
@@ -307,4 +382,39 @@ func Test_setupAuthInterceptors(t *testing.T) {
 		assert.Equal(t, 1, len(s.unaryInterceptors))
 		assert.Equal(t, 1, len(s.streamInterceptors))
 	})
+
+	t.Run("policy", func(t *testing.T) {
+		rds := miniredis.RunT(t)
+		s := &mockedServer{}
+		policy, err := setupAuthInterceptors(s, RpcServerConf{
+			Redis: redis.RedisKeyConf{
+				RedisConf: redis.RedisConf{
+					Host: rds.Addr(),
+					Type: redis.NodeType,
+				},
+				Key: "foo",
+			},
+			AuthPolicy: AuthPolicyConf{
+				Jwt: JwtAuthConf{
+					Secret: "secret",
+				},
+				ApiKeys: []string{"key"},
+				Rules: []AuthRuleConf{
+					{
+						Method:         "/foo.Admin/*",
+						Authenticators: []string{"mtls"},
+					},
+				},
+			},
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, policy)
+		assert.Equal(t, 1, len(s.unaryInterceptors))
+		assert.Equal(t, 1, len(s.streamInterceptors))
+
+		rs := &RpcServer{policy: policy}
+		rs.AddAuthenticator("custom", auth.NewPeerAuthenticator())
+		rs = &RpcServer{}
+		rs.AddAuthenticator("custom", auth.NewPeerAuthenticator())
+	})
 }